@@ -0,0 +1,395 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"apocli/pkg/apocli"
+	"apocli/pkg/longcook"
+	"apocli/pkg/scheduler"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+)
+
+// scheduleFile is embedded in every command that reads or writes scheduled
+// cooks.
+type scheduleFile struct {
+	ScheduleFile string `type:"path" help:"Path of the file holding scheduled cooks. Defaults to schedule.json in the config folder."`
+}
+
+func (flag scheduleFile) open() (*scheduler.Store, error) {
+	path := flag.ScheduleFile
+	if path == "" {
+		var err error
+		path, err = apocli.ScheduleFilePath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return scheduler.NewStore(path), nil
+}
+
+// longCooksFile is embedded in every command that starts or follows long
+// cooks.
+type longCooksFile struct {
+	LongCooksFile string `type:"path" help:"Path of the file recording the stage chains of long cooks. Defaults to longcooks.json in the config folder."`
+}
+
+func (flag longCooksFile) open() (*longcook.Store, error) {
+	path := flag.LongCooksFile
+	if path == "" {
+		var err error
+		path, err = apocli.LongCooksFilePath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return longcook.NewStore(path), nil
+}
+
+// record saves the stage chain of a long cook that was just started, so that
+// its progress can be followed as a single cook. Other cooks aren't recorded.
+func (flag longCooksFile) record(recipe anova.Recipe, cook *anova.Cook, oven *anova.Oven) error {
+	if !recipe.Long {
+		return nil
+	}
+	store, err := flag.open()
+	if err != nil {
+		return err
+	}
+	return store.Save(longcook.NewRecord(cook, oven, time.Now()))
+}
+
+// recordStarted records the cook if it's a long cook, for use as an observer of
+// the cooks a command starts.
+func (flag longCooksFile) recordStarted(recipe anova.Recipe, cook *anova.Cook, oven *anova.Oven) {
+	if err := flag.record(recipe, cook, oven); err != nil {
+		slog.Error("failed to record long cook", slog.String("cookID", cook.ID()), slog.Any("err", err))
+	}
+}
+
+type CookStartCmd struct {
+	Recipe  string `arg:"" help:"JSON recipe file, as accepted by \"apocli serve\", or - to read it from standard input"`
+	At      string `help:"Start the cook at this time instead of now, e.g. 17:30 or \"2024-03-01 17:30\""`
+	ReadyBy string `help:"Start the cook early enough to be ready at this time, from its timers and an estimated preheat time"`
+
+	scheduleFile  `embed:""`
+	longCooksFile `embed:""`
+}
+
+func (cmd *CookStartCmd) Run(ctx *Context) error {
+	if cmd.At != "" && cmd.ReadyBy != "" {
+		return errors.New("only one of --at and --ready-by can be given")
+	}
+
+	recipe, err := readRecipe(cmd.Recipe)
+	if err != nil {
+		return err
+	}
+	cook, err := recipe.NewCook()
+	if err != nil {
+		return err
+	}
+
+	job := scheduler.Job{Recipe: recipe}
+	now := time.Now()
+	switch {
+	case cmd.At != "":
+		job.StartAt, err = scheduler.ParseTime(cmd.At, now)
+		if err != nil {
+			return err
+		}
+		if !job.StartAt.After(now) {
+			return fmt.Errorf("cannot start the cook at %s, which has already passed", job.StartAt.Format(time.DateTime))
+		}
+	case cmd.ReadyBy != "":
+		readyBy, err := scheduler.ParseTime(cmd.ReadyBy, now)
+		if err != nil {
+			return err
+		}
+		job.ReadyBy = &readyBy
+		job.StartAt, err = scheduler.StartTimeForReadyBy(cook, readyBy)
+		if err != nil {
+			return err
+		}
+		if job.StartAt.Before(now) {
+			return fmt.Errorf("too late to be ready by %s; the cook would have had to start at %s",
+				readyBy.Format(time.DateTime), job.StartAt.Format(time.DateTime))
+		}
+	}
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	if job.StartAt.IsZero() {
+		err = cook.Start(oven)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Started cook on %s.\n", oven.Name)
+		return cmd.record(recipe, cook, oven)
+	}
+
+	store, err := cmd.scheduleFile.open()
+	if err != nil {
+		return err
+	}
+	job.CookerID = oven.CookerID
+	job.OvenName = oven.Name
+	job, err = scheduler.New(store).Schedule(job)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Scheduled cook %s on %s to start at %s.\n", job.ID, oven.Name, job.StartAt.Format(time.DateTime))
+	if !daemonRunning() {
+		fmt.Println("Scheduled cooks are only started while \"apocli daemon\" is running.")
+	}
+	return nil
+}
+
+type CookValidateCmd struct {
+	Recipe string `arg:"" help:"JSON recipe file, as accepted by \"apocli serve\", or - to read it from standard input"`
+}
+
+func (cmd *CookValidateCmd) Run(ctx *Context) error {
+	recipe, err := readRecipe(cmd.Recipe)
+	if err != nil {
+		return err
+	}
+	_, err = recipe.NewCook()
+	var validationErr anova.ErrValidation
+	if errors.As(err, &validationErr) {
+		// One line per violation reads better than the error's own message
+		for _, violation := range validationErr.Violations {
+			fmt.Println(violation.Error())
+		}
+		return fmt.Errorf("recipe breaks %d rules", len(validationErr.Violations))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Recipe is valid.")
+	return nil
+}
+
+type CookStatusCmd struct {
+	Follow bool `short:"f" help:"Keep printing progress until the cook ends. Preheat times are estimated once the oven has been seen heating up for a little while."`
+
+	longCooksFile `embed:""`
+}
+
+func (cmd *CookStatusCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+	progress := oven.Progress()
+	if progress == nil {
+		fmt.Printf("%s is not cooking.\n", oven.Name)
+		return nil
+	}
+
+	store, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	record, err := store.Get(oven.CookerID)
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		if longProgress, ok := record.Progress(oven.State); ok {
+			fmt.Printf("Stage %d of %d of a long cook started at %s",
+				longProgress.Stage+1, longProgress.Stages, record.StartedAt.Local().Format(time.DateTime))
+			if longProgress.TimerDuration > 0 && !longProgress.Preheating {
+				fmt.Printf(", timer at %s of %s", longProgress.TimerElapsed, longProgress.TimerDuration)
+			}
+			fmt.Println(".")
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STAGE\tTYPE\tSTATUS\tTIMER\tREMAINING")
+	for i, stage := range progress.Stages {
+		timer := "-"
+		if stage.Timer > 0 {
+			timer = stage.Timer.String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, stage.Type, stage.Status, timer, formatRemaining(stage.Remaining))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	unit := ctx.TemperatureUnit(oven.State.State.TemperatureUnit)
+	fmt.Println(progressSummary(progress, unit))
+	if !cmd.Follow {
+		return nil
+	}
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for {
+		select {
+		case event := <-session.Events:
+			switch event := event.(type) {
+			case anova.OvenUpdated:
+				if event.Oven.CookerID != oven.CookerID {
+					continue
+				}
+				progress := oven.Progress()
+				if progress == nil {
+					fmt.Println("The cook has ended.")
+					return nil
+				}
+				fmt.Printf("[%s] %s\n", time.Now().Format(time.TimeOnly), progressSummary(progress, unit))
+
+			case anova.ServiceStopped:
+				return errors.New("connection closed while following the cook")
+			}
+		case <-interrupted.Done():
+			return nil
+		}
+	}
+}
+
+// progressSummary describes the active stage and what is left of the cook.
+func progressSummary(progress *anova.CookProgress, unit dto.TemperatureUnit) string {
+	summary := fmt.Sprintf("Stage %d of %d", progress.ActiveStageIndex+1, len(progress.Stages))
+	if index := progress.ActiveStageIndex; index >= 0 && index < len(progress.Stages) {
+		summary += fmt.Sprintf(" is %s", progress.Stages[index].Status)
+	}
+	if progress.StageRemaining != nil {
+		summary += fmt.Sprintf(", %s left", formatRemaining(progress.StageRemaining))
+	}
+	if progress.PreheatETA != nil {
+		// A rate converts like a difference, without the offset between units
+		rate := progress.HeatingRate
+		if unit == dto.TemperatureUnitFahrenheit {
+			rate *= 9.0 / 5
+		}
+		summary += fmt.Sprintf(" (preheated by %s, heating at %.1f °%s/min)",
+			progress.PreheatETA.Local().Format(time.TimeOnly), rate, unit)
+	}
+	return summary + fmt.Sprintf(". Cook: %s elapsed, %s left.", progress.Elapsed, formatRemaining(progress.Remaining))
+}
+
+func formatRemaining(remaining *time.Duration) string {
+	if remaining == nil {
+		return "unknown"
+	}
+	return remaining.Round(time.Second).String()
+}
+
+// readRecipe reads a JSON recipe from a file, or from standard input if path
+// is "-".
+func readRecipe(path string) (anova.Recipe, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		fh, err := os.Open(path)
+		if err != nil {
+			return anova.Recipe{}, fmt.Errorf("failed to open recipe: %+v", err)
+		}
+		defer fh.Close()
+		reader = fh
+	}
+
+	var recipe anova.Recipe
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&recipe)
+	if err != nil {
+		return anova.Recipe{}, fmt.Errorf("invalid recipe: %+v", err)
+	}
+	return recipe, nil
+}
+
+// daemonRunning checks whether a daemon is listening on the default socket.
+func daemonRunning() bool {
+	socketPath, err := apocli.DaemonSocketPath()
+	if err != nil {
+		return false
+	}
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+type ScheduleListCmd struct {
+	scheduleFile `embed:""`
+}
+
+func (cmd *ScheduleListCmd) Run(_ *Context) error {
+	store, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	jobs, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No cooks scheduled. Run \"apocli cook start --at\" or \"--ready-by\" to schedule one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tOVEN\tSTART AT\tREADY BY\tSTAGES")
+	for _, job := range jobs {
+		readyBy := "-"
+		if job.ReadyBy != nil {
+			readyBy = job.ReadyBy.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n",
+			job.ID,
+			stringOrDash(&job.OvenName),
+			job.StartAt.Local().Format(time.DateTime),
+			readyBy,
+			len(job.Recipe.Stages))
+	}
+	return w.Flush()
+}
+
+type ScheduleCancelCmd struct {
+	scheduleFile `embed:""`
+
+	ID string `arg:"" name:"id" help:"ID of the scheduled cook, as listed by \"schedule list\""`
+}
+
+func (cmd *ScheduleCancelCmd) Run(_ *Context) error {
+	store, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	job, err := store.Remove(cmd.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Cancelled cook %s, which was to start at %s.\n", job.ID, job.StartAt.Local().Format(time.DateTime))
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/apocli"
+	"apocli/pkg/history"
+	"apocli/pkg/notifier"
+	"apocli/pkg/scheduler"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+type DaemonCmd struct {
+	Socket   string `type:"path" help:"Path of the Unix socket to listen on. Defaults to daemon.sock in the config folder."`
+	History  bool   `help:"Record oven states and cooks to the history database"`
+	Notify   bool   `help:"Send notifications through the channels in the notifications config"`
+	Watchdog bool   `help:"Stop cooks that break the --watchdog-* rules, e.g. because the oven is overheating"`
+
+	historyDatabase     `embed:""`
+	scheduleFile        `embed:""`
+	longCooksFile       `embed:""`
+	notificationsConfig `embed:""`
+	watchdogRules       `embed:"" prefix:"watchdog-"`
+	timeZoneSyncFlag    `embed:""`
+}
+
+func (cmd *DaemonCmd) Run(ctx *Context) error {
+	socketPath := cmd.Socket
+	if socketPath == "" {
+		var err error
+		socketPath, err = apocli.DaemonSocketPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	listener, err := listenDaemonSocket(socketPath)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	// The daemon itself must connect to the backend
+	ctx.NoDaemon = true
+	daemon := anova.NewDaemon()
+	session, err := ctx.Connect(anova.OptionDaemon(daemon))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	serveErrors := make(chan error, 1)
+	go func() {
+		serveErrors <- daemon.Serve(listener)
+	}()
+	defer daemon.Close()
+
+	fmt.Printf("Daemon listening on %s. Press Ctrl-C to stop.\n", socketPath)
+
+	// Scheduled cooks are always started while the daemon runs
+	scheduleStore, err := cmd.scheduleFile.open()
+	if err != nil {
+		return err
+	}
+	cookScheduler := scheduler.New(scheduleStore, func(job scheduler.Job, cook *anova.Cook, oven *anova.Oven) {
+		if err := cmd.longCooksFile.record(job.Recipe, cook, oven); err != nil {
+			slog.Error("failed to record long cook", slog.String("job", job.ID), slog.Any("err", err))
+		}
+	})
+	go cookScheduler.Run(interrupted.Done())
+
+	handlers := append([]anova.EventHandler{cookScheduler}, cmd.timeZoneSyncFlag.handlers(interrupted.Done())...)
+	if cmd.History {
+		store, err := cmd.historyDatabase.open()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		handlers = append(handlers, history.NewRecorder(store))
+	}
+	var notifications *notifier.Notifier
+	if cmd.Notify {
+		notifications, err = cmd.newNotifier()
+		if err != nil {
+			return err
+		}
+		defer notifications.Close()
+		handlers = append(handlers, notifications)
+	}
+	if cmd.Watchdog {
+		guard, err := newWatchdog(cmd.watchdogRules.rules(), notifications)
+		if err != nil {
+			return err
+		}
+		// Let stops in progress finish and be notified
+		defer guard.Wait()
+		handlers = append(handlers, guard)
+	}
+
+	dispatchErrors := make(chan error, 1)
+	go func() {
+		dispatchErrors <- session.Dispatch(interrupted.Done(), handlers...)
+	}()
+
+	select {
+	case err := <-serveErrors:
+		if err != nil {
+			return fmt.Errorf("daemon failed: %+v", err)
+		}
+		return nil
+	case err := <-dispatchErrors:
+		if err != nil {
+			slog.Error("stopped receiving events", slog.Any("err", err))
+		}
+		return err
+	}
+}
+
+// listenDaemonSocket listens on the socket, replacing it if it was left behind
+// by a daemon that is no longer running.
+func listenDaemonSocket(socketPath string) (net.Listener, error) {
+	if _, err := os.Stat(socketPath); err == nil {
+		conn, err := net.Dial("unix", socketPath)
+		if err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("a daemon is already listening on \"%s\"", socketPath)
+		}
+		err = os.Remove(socketPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket \"%s\": %+v", socketPath, err)
+		}
+	}
+
+	// Anyone who can connect can control the ovens, so the socket is created
+	// accessible only to the user rather than restricted after the fact
+	oldUmask := syscall.Umask(0077)
+	listener, err := net.Listen("unix", socketPath)
+	syscall.Umask(oldUmask)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on \"%s\": %+v", socketPath, err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// descaleStartTimeout is how long to wait for the oven to report that it has
+// entered descale mode after the command is acknowledged
+const descaleStartTimeout = 30 * time.Second
+
+type DescaleCmd struct {
+	Abort bool `help:"Abort a descale that is already running"`
+	Yes   bool `short:"y" help:"Skip the confirmation prompt"`
+}
+
+func (cmd *DescaleCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Abort {
+		if oven.State.State.Mode != dto.StateModeDescale {
+			return fmt.Errorf("nothing to abort; oven \"%s\" (%s) is not descaling", oven.Name, oven.CookerID)
+		}
+		err = oven.AbortDescale()
+		if err != nil {
+			return fmt.Errorf("failed to abort descale: %+v", err)
+		}
+		fmt.Printf("Aborted descale on oven \"%s\" (%s)\n", oven.Name, oven.CookerID)
+		return nil
+	}
+
+	err = checkDescalePreconditions(oven)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Yes {
+		if !oven.State.Nodes.SteamGenerators.Boiler.DescaleRequired {
+			fmt.Println("The oven does not currently report that descaling is required.")
+		}
+		fmt.Println("Make sure the water tank is filled with descaling solution before starting.")
+		if !confirm(fmt.Sprintf("Start descale on oven \"%s\" (%s)?", oven.Name, oven.CookerID)) {
+			return nil
+		}
+	}
+
+	err = oven.StartDescale()
+	if err != nil {
+		return fmt.Errorf("failed to start descale: %+v", err)
+	}
+
+	fmt.Println("Descale started. Press Ctrl-C to stop following; the oven will keep descaling.")
+	return followDescale(ctx, session, oven)
+}
+
+func checkDescalePreconditions(oven *anova.Oven) error {
+	state := oven.State
+	switch state.State.Mode {
+	case dto.StateModeCook:
+		return fmt.Errorf("oven \"%s\" (%s) is cooking; stop the cook before descaling", oven.Name, oven.CookerID)
+	case dto.StateModeDescale:
+		return fmt.Errorf("oven \"%s\" (%s) is already descaling", oven.Name, oven.CookerID)
+	}
+	if !state.Nodes.Door.Closed {
+		return errors.New("the oven door must be closed to descale")
+	}
+	if state.Nodes.WaterTank.Empty {
+		return errors.New("the water tank must be filled with descaling solution")
+	}
+	return nil
+}
+
+// followDescale prints the oven's progress until it leaves descale mode.
+func followDescale(ctx *Context, session *Session, oven *anova.Oven) error {
+	started := false
+	startedAt := time.Now()
+	startTimeout := time.After(descaleStartTimeout)
+	for {
+		select {
+		case event := <-session.Events:
+			switch event := event.(type) {
+			case anova.OvenUpdated:
+				if event.Oven.CookerID != oven.CookerID {
+					continue
+				}
+
+				state := event.Oven.State
+				if state.State.Mode != dto.StateModeDescale {
+					if started {
+						fmt.Printf("Descale finished after %s\n", time.Since(startedAt).Round(time.Second))
+						return nil
+					}
+					continue
+				}
+				if !started {
+					started = true
+					startedAt = time.Now()
+				}
+
+				waterTank := "ok"
+				if state.Nodes.WaterTank.Empty {
+					waterTank = "EMPTY"
+				}
+				door := "closed"
+				if !state.Nodes.Door.Closed {
+					door = "OPEN"
+				}
+				boiler := anova.NewTemperatureCelsius(state.Nodes.SteamGenerators.Boiler.Celsius)
+				fmt.Printf("[%s] boiler %s, water tank %s, door %s\n",
+					time.Since(startedAt).Round(time.Second),
+					boiler.Format(ctx.TemperatureUnit(state.State.TemperatureUnit)), waterTank, door)
+
+			case anova.ServiceStopped:
+				return errors.New("connection closed while descaling")
+			}
+
+		case <-startTimeout:
+			if !started {
+				return fmt.Errorf("oven did not enter descale mode within %s", descaleStartTimeout)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+type DiagHealthCmd struct {
+}
+
+func (cmd *DiagHealthCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = oven.HealthCheck()
+	if err != nil {
+		return fmt.Errorf("health check failed: %+v", err)
+	}
+
+	fmt.Printf("Oven \"%s\" (%s) is healthy (responded in %s)\n", oven.Name, oven.CookerID, time.Since(start).Round(time.Millisecond))
+
+	return nil
+}
+
+type DiagRequestCmd struct {
+	Command string `arg:"" help:"Diagnostic command to send to the oven"`
+}
+
+func (cmd *DiagRequestCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	data, err := oven.RequestDiagnostics(cmd.Command)
+	if err != nil {
+		return fmt.Errorf("diagnostics request failed: %+v", err)
+	}
+
+	return printJSON(data)
+}
+
+type DiagConfigCmd struct {
+}
+
+func (cmd *DiagConfigCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	data, err := oven.GetConfiguration()
+	if err != nil {
+		return fmt.Errorf("failed to get configuration: %+v", err)
+	}
+
+	return printJSON(data)
+}
+
+type DiagReportRateCmd struct {
+	Cooking time.Duration `default:"2s" help:"Interval between state reports while cooking, in whole seconds"`
+	Idle    time.Duration `default:"30s" help:"Interval between state reports while idle, in whole seconds"`
+	For     time.Duration `help:"Restore the default rate after this long (or on Ctrl-C), instead of leaving the new rate in place"`
+	Default bool          `help:"Restore the default rate and exit"`
+}
+
+func (cmd *DiagReportRateCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Default {
+		err = oven.SetReportStateRateDefault()
+		if err != nil {
+			return fmt.Errorf("failed to restore default report rate: %+v", err)
+		}
+		fmt.Printf("Restored default report rate on oven \"%s\" (%s)\n", oven.Name, oven.CookerID)
+		return nil
+	}
+
+	if cmd.For == 0 {
+		err = oven.SetReportStateRate(cmd.Cooking, cmd.Idle)
+		if err != nil {
+			return fmt.Errorf("failed to set report rate: %+v", err)
+		}
+		fmt.Printf("Oven \"%s\" (%s) now reports every %s while cooking and every %s while idle\n",
+			oven.Name, oven.CookerID, cmd.Cooking, cmd.Idle)
+		return nil
+	}
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return oven.WithReportStateRate(cmd.Cooking, cmd.Idle, func() error {
+		fmt.Printf("Oven \"%s\" (%s) reports every %s while cooking and every %s while idle until %s. Press Ctrl-C to restore early.\n",
+			oven.Name, oven.CookerID, cmd.Cooking, cmd.Idle, time.Now().Add(cmd.For).Format(time.DateTime))
+
+		select {
+		case <-time.After(cmd.For):
+		case <-interrupted.Done():
+		}
+
+		fmt.Println("Restoring default report rate")
+		return nil
+	})
+}
+
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/exporter"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+type ExporterCmd struct {
+	Listen string `default:":9123" help:"Address to serve metrics on"`
+
+	timeZoneSyncFlag `embed:""`
+}
+
+func (cmd *ExporterCmd) Run(ctx *Context) error {
+	metrics := exporter.NewExporter()
+
+	session, err := ctx.Connect(
+		anova.OptionCommandObserver(metrics.ObserveCommand),
+		anova.OptionUnknownFieldsObserver(metrics.ObserveUnknownFields))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{
+		Addr:              cmd.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- server.ListenAndServe()
+	}()
+	defer server.Shutdown(context.Background())
+
+	fmt.Printf("Serving metrics on http://%s/metrics. Press Ctrl-C to stop.\n", cmd.Listen)
+
+	dispatchErrors := make(chan error, 1)
+	go func() {
+		handlers := append([]anova.EventHandler{metrics}, cmd.timeZoneSyncFlag.handlers(interrupted.Done())...)
+		dispatchErrors <- session.Dispatch(interrupted.Done(), handlers...)
+	}()
+
+	select {
+	case err := <-serverErrors:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("metrics server failed: %+v", err)
+		}
+		return nil
+	case err := <-dispatchErrors:
+		if err != nil {
+			slog.Error("stopped receiving events", slog.Any("err", err))
+		}
+		return err
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// firmwareUpdateStartTimeout is how long to wait for the oven to report any
+// update progress after the command is acknowledged
+const firmwareUpdateStartTimeout = time.Minute
+
+type FirmwareStatusCmd struct {
+}
+
+func (cmd *FirmwareStatusCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	// Show the whole fleet unless a single oven was asked for
+	ovens := session.Ovens
+	if ctx.OvenSelector != "" {
+		oven, err := ctx.SelectOven(session)
+		if err != nil {
+			return err
+		}
+		ovens = []*anova.Oven{oven}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COOKER ID\tNAME\tFIRMWARE\tHARDWARE\tUI FIRMWARE\tUI HARDWARE\tLAST UPDATED\tOTA")
+	for _, oven := range ovens {
+		systemInfo := oven.State.SystemInfo
+
+		ota := "-"
+		if systemInfo.OtaUpdate != nil {
+			ota = formatFirmwareUpdate(systemInfo.OtaUpdate.Mode, systemInfo.OtaUpdate.Progress)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			oven.CookerID, oven.Name,
+			systemInfo.FirmwareVersion, systemInfo.HardwareVersion,
+			stringOrDash(systemInfo.UIFirmwareVersion), stringOrDash(systemInfo.UIHardwareVersion),
+			systemInfo.FirmwareUpdatedTimestamp.Local().Format(time.DateTime), ota)
+	}
+
+	return w.Flush()
+}
+
+type FirmwareUpdateCmd struct {
+	DownloadLink string `required:"" name:"download-link" placeholder:"URL" help:"HTTPS link to the firmware image to install"`
+	Yes          bool   `short:"y" help:"Skip the confirmation prompt"`
+}
+
+func (cmd *FirmwareUpdateCmd) Run(ctx *Context) error {
+	downloadLink, err := url.Parse(cmd.DownloadLink)
+	if err != nil || downloadLink.Scheme != "https" || downloadLink.Host == "" {
+		return fmt.Errorf("invalid download link \"%s\"; expected an https:// URL", cmd.DownloadLink)
+	}
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	// Never interrupt a cook or descale, or stack updates on top of each other
+	state := oven.State
+	if state.State.Mode != dto.StateModeIdle {
+		return fmt.Errorf("oven \"%s\" (%s) must be idle to update, but is in mode \"%s\"", oven.Name, oven.CookerID, state.State.Mode)
+	}
+	if !state.SystemInfo.Online {
+		return fmt.Errorf("oven \"%s\" (%s) is offline", oven.Name, oven.CookerID)
+	}
+	if ota := state.SystemInfo.OtaUpdate; ota != nil && ota.Mode != dto.OtaUpdateModeDefault && ota.Mode != dto.OtaUpdateModeError {
+		return fmt.Errorf("oven \"%s\" (%s) already has an update in progress (%s)", oven.Name, oven.CookerID, ota.Mode)
+	}
+
+	if !cmd.Yes {
+		fmt.Printf("Current firmware: %s (hardware %s)\n", state.SystemInfo.FirmwareVersion, state.SystemInfo.HardwareVersion)
+		fmt.Printf("New firmware:     %s\n", downloadLink)
+		fmt.Println("Installing the wrong image can leave the oven unusable, and this is not supported by Anova.")
+		if !confirm(fmt.Sprintf("Update firmware on oven \"%s\" (%s)?", oven.Name, oven.CookerID)) {
+			return nil
+		}
+	}
+
+	err = oven.StartFirmwareUpdate(downloadLink.String())
+	if err != nil {
+		return fmt.Errorf("failed to start firmware update: %+v", err)
+	}
+
+	fmt.Println("Firmware update started. Press Ctrl-C to stop following; the oven will keep updating.")
+	return followFirmwareUpdate(session, oven)
+}
+
+// followFirmwareUpdate prints update progress until the oven reports a new
+// firmware version or the update fails.
+func followFirmwareUpdate(session *Session, oven *anova.Oven) error {
+	previousVersion := oven.State.SystemInfo.FirmwareVersion
+	started := false
+	startTimeout := time.After(firmwareUpdateStartTimeout)
+	for {
+		select {
+		case event := <-session.Events:
+			switch event := event.(type) {
+			case anova.FirmwareUpdateProgress:
+				if event.Oven.CookerID != oven.CookerID {
+					continue
+				}
+				started = true
+				fmt.Println(formatFirmwareUpdate(event.Mode, event.Progress))
+
+				switch event.Mode {
+				case dto.OtaUpdateModeError:
+					return errors.New("firmware update failed")
+				case dto.OtaUpdateModeRollback:
+					return errors.New("firmware update was rolled back")
+				}
+
+			case anova.OvenUpdated:
+				if event.Oven.CookerID != oven.CookerID {
+					continue
+				}
+				version := event.Oven.State.SystemInfo.FirmwareVersion
+				if version != previousVersion {
+					fmt.Printf("Firmware updated from %s to %s\n", previousVersion, version)
+					return nil
+				}
+
+			case anova.ServiceStopped:
+				return errors.New("connection closed while updating firmware")
+			}
+
+		case <-startTimeout:
+			if !started {
+				return fmt.Errorf("oven did not report any update progress within %s", firmwareUpdateStartTimeout)
+			}
+		}
+	}
+}
+
+func formatFirmwareUpdate(mode dto.OtaUpdateMode, progress *float64) string {
+	if progress == nil {
+		return string(mode)
+	}
+	return fmt.Sprintf("%s (%.0f%%)", mode, *progress)
+}
+
+func stringOrDash(value *string) string {
+	if value == nil || *value == "" {
+		return "-"
+	}
+	return *value
+}
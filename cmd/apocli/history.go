@@ -0,0 +1,299 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"apocli/pkg/apocli"
+	"apocli/pkg/history"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// historyDatabase is embedded in every history command.
+type historyDatabase struct {
+	Database string `type:"path" help:"Path of the history database. Defaults to history.db in the config folder."`
+}
+
+func (flag historyDatabase) open() (*history.Store, error) {
+	path := flag.Database
+	if path == "" {
+		var err error
+		path, err = apocli.HistoryDatabasePath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return history.Open(path)
+}
+
+type HistoryRecordCmd struct {
+	historyDatabase `embed:""`
+}
+
+func (cmd *HistoryRecordCmd) Run(ctx *Context) error {
+	store, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("Recording oven states. Press Ctrl-C to stop.")
+	return session.Dispatch(interrupted.Done(), history.NewRecorder(store))
+}
+
+type HistoryListCmd struct {
+	historyDatabase `embed:""`
+
+	Limit int `short:"n" default:"20" help:"Maximum number of cooks to list"`
+}
+
+func (cmd *HistoryListCmd) Run(ctx *Context) error {
+	store, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	// Only filtered by --oven, as the default oven shouldn't hide other ovens' cooks
+	cooks, err := store.ListCooks(ctx.OvenSelector, cmd.Limit)
+	if err != nil {
+		return err
+	}
+	if len(cooks) == 0 {
+		fmt.Println("No cooks recorded. Run \"apocli history record\" or \"apocli daemon --history\" to record cooks.")
+		return nil
+	}
+
+	unit := ctx.TemperatureUnit("")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COOK ID\tOVEN\tSTARTED\tDURATION\tSTAGES\tPEAK DRY\tOUTCOME")
+	for _, cook := range cooks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d/%d\t%s\t%s\n",
+			cook.CookID,
+			cook.OvenName,
+			cook.StartedAt.Local().Format(time.DateTime),
+			formatCookDuration(cook),
+			cook.LastStageIndex+1, len(cook.Stages),
+			formatTemperature(cook.PeakDryCelsius, unit),
+			formatOutcome(cook))
+	}
+	return w.Flush()
+}
+
+type HistoryShowCmd struct {
+	historyDatabase `embed:""`
+
+	CookID string `arg:"" name:"cook-id" help:"ID of the cook, as listed by \"history list\""`
+}
+
+func (cmd *HistoryShowCmd) Run(ctx *Context) error {
+	store, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	cook, err := store.GetCook(cmd.CookID)
+	if err != nil {
+		return err
+	}
+	states, err := store.ListStates(cook.CookID)
+	if err != nil {
+		return err
+	}
+
+	unit := ctx.TemperatureUnit("")
+	fmt.Printf("Cook %s on \"%s\" (%s)\n", cook.CookID, cook.OvenName, cook.CookerID)
+	fmt.Printf("Started:  %s\n", cook.StartedAt.Local().Format(time.DateTime))
+	fmt.Printf("Duration: %s\n", formatCookDuration(*cook))
+	fmt.Printf("Outcome:  %s\n", formatOutcome(*cook))
+	fmt.Printf("Peak:     %s dry, %s wet\n", formatTemperature(cook.PeakDryCelsius, unit), formatTemperature(cook.PeakWetCelsius, unit))
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STAGE\tTYPE\tMODE\tSETPOINT\tTIMER")
+	for i, stage := range cook.Stages {
+		mode, setpoint := "-", "-"
+		if bulbs := stage.TemperatureBulbs; bulbs != nil {
+			mode = string(bulbs.Mode)
+			if bulbs.Dry != nil {
+				setpoint = formatTemperature(&bulbs.Dry.Setpoint.Celsius, unit)
+			} else if bulbs.Wet != nil {
+				setpoint = formatTemperature(&bulbs.Wet.Setpoint.Celsius, unit)
+			}
+		}
+		timer := "-"
+		if stage.Timer != nil {
+			timer = (time.Duration(stage.Timer.Initial) * time.Second).String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, stage.Type, mode, setpoint, timer)
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ELAPSED\tSTAGE\tDRY\tDRY SETPOINT\tWET\tWET SETPOINT\tHUMIDITY\tTIMER")
+	for _, state := range states {
+		stage := "-"
+		if state.StageIndex != nil {
+			stage = strconv.Itoa(*state.StageIndex + 1)
+		}
+		humidity := "-"
+		if state.RelativeHumidity != nil {
+			humidity = fmt.Sprintf("%d%%", *state.RelativeHumidity)
+		}
+		timer := "-"
+		if state.TimerMode != string(dto.TimerModeIdle) {
+			timer = fmt.Sprintf("%s/%s", time.Duration(state.TimerCurrent)*time.Second, time.Duration(state.TimerInitial)*time.Second)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			state.RecordedAt.Sub(cook.StartedAt).Truncate(time.Second),
+			stage,
+			formatTemperature(&state.DryCelsius, unit),
+			formatTemperature(state.DrySetpointCelsius, unit),
+			formatTemperature(&state.WetCelsius, unit),
+			formatTemperature(state.WetSetpointCelsius, unit),
+			humidity,
+			timer)
+	}
+	return w.Flush()
+}
+
+type HistoryExportCmd struct {
+	historyDatabase `embed:""`
+
+	CSV    bool   `name:"csv" help:"Export as CSV. This is currently the only format."`
+	Cook   string `help:"Only export the states recorded during this cook"`
+	Output string `short:"O" type:"path" help:"File to write to. Defaults to stdout."`
+}
+
+func (cmd *HistoryExportCmd) Run(_ *Context) error {
+	if !cmd.CSV {
+		return errors.New("an export format is required; use --csv")
+	}
+
+	store, err := cmd.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if cmd.Cook != "" {
+		// Distinguish a mistyped cook ID from a cook without states
+		_, err = store.GetCook(cmd.Cook)
+		if err != nil {
+			return err
+		}
+	}
+	states, err := store.ListStates(cmd.Cook)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = os.Stdout
+	if cmd.Output != "" {
+		fh, err := os.Create(cmd.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create \"%s\": %+v", cmd.Output, err)
+		}
+		defer fh.Close()
+		output = fh
+	}
+
+	return writeHistoryCSV(output, states)
+}
+
+func writeHistoryCSV(output io.Writer, states []history.State) error {
+	w := csv.NewWriter(output)
+	err := w.Write([]string{
+		"recorded_at", "cooker_id", "cook_id", "mode", "stage_index",
+		"dry_celsius", "dry_setpoint_celsius", "wet_celsius", "wet_setpoint_celsius", "relative_humidity",
+		"fan_speed", "door_closed", "vent_open", "probe_connected",
+		"timer_mode", "timer_initial_seconds", "timer_current_seconds",
+	})
+	if err != nil {
+		return err
+	}
+
+	formatFloat := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+	formatInt := func(value *int) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	}
+
+	for _, state := range states {
+		err = w.Write([]string{
+			state.RecordedAt.UTC().Format(time.RFC3339Nano),
+			string(state.CookerID),
+			state.CookID,
+			string(state.Mode),
+			formatInt(state.StageIndex),
+			formatFloat(&state.DryCelsius),
+			formatFloat(state.DrySetpointCelsius),
+			formatFloat(&state.WetCelsius),
+			formatFloat(state.WetSetpointCelsius),
+			formatInt(state.RelativeHumidity),
+			strconv.Itoa(state.FanSpeed),
+			strconv.FormatBool(state.DoorClosed),
+			strconv.FormatBool(state.VentOpen),
+			strconv.FormatBool(state.ProbeConnected),
+			state.TimerMode,
+			strconv.Itoa(state.TimerInitial),
+			strconv.Itoa(state.TimerCurrent),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func formatCookDuration(cook history.Cook) string {
+	if cook.EndedAt == nil {
+		return "-"
+	}
+	return cook.EndedAt.Sub(cook.StartedAt).Truncate(time.Second).String()
+}
+
+func formatOutcome(cook history.Cook) string {
+	if cook.EndedAt == nil {
+		return "in progress"
+	}
+	return string(cook.Outcome)
+}
+
+func formatTemperature(celsius *float64, unit dto.TemperatureUnit) string {
+	if celsius == nil {
+		return "-"
+	}
+	return anova.NewTemperatureCelsius(*celsius).Format(unit)
+}
@@ -12,9 +12,32 @@ import (
 
 type Context struct {
 	Config *apocli.Config
+
+	// Oven selector passed with --oven, if any
+	OvenSelector string
+	// Whether raw WebSocket messages should be logged
+	Trace bool
+	// Whether to connect to the backend even if a daemon is running
+	NoDaemon bool
+	// Whether to drop messages with fields apocli doesn't know about
+	StrictDecoding bool
+	// Capture file to record the session to, if any
+	Record string
+	// Capture file to replay instead of connecting, if any
+	Replay      string
+	ReplaySpeed float64
 }
 
 var cli struct {
+	Verbose        bool    `short:"v" help:"Enable debug logging"`
+	Trace          bool    `help:"Log all raw messages sent to and received from the backend"`
+	OvenSelector   string  `name:"oven" short:"o" help:"Cooker ID or name of the oven to use. Defaults to DefaultCookerID, then the first oven on the account."`
+	NoDaemon       bool    `help:"Connect directly to the backend, even if apocli daemon is running"`
+	StrictDecoding bool    `help:"Drop messages with unknown fields or fields of the wrong type, instead of decoding what can be decoded"`
+	Record         string  `type:"path" help:"Append all messages sent and received to a JSONL capture file, with tokens redacted"`
+	Replay         string  `type:"path" help:"Replay a capture file instead of connecting to the backend. Commands have no effect."`
+	ReplaySpeed    float64 `default:"1" help:"Speed to replay the capture at, e.g. 10 for ten times faster. 0 replays without delays."`
+
 	Config struct {
 		Path     ConfigPathCmd     `cmd:"" help:"Print path to config file"`
 		List     ConfigListCmd     `cmd:"" help:"List all config items"`
@@ -23,12 +46,79 @@ var cli struct {
 		Clear    ConfigClearCmd    `cmd:"" help:"Reset a single config item to its default"`
 		ClearAll ConfigClearAllCmd `cmd:"" help:"Reset all config values to the defaults"`
 	} `cmd:"" help:"Manage configuration"`
+
+	Ovens struct {
+		List OvensListCmd `cmd:"" help:"List all ovens paired with the account"`
+	} `cmd:"" help:"Inspect the ovens paired with the account"`
+
+	Oven struct {
+		Rename     OvenRenameCmd     `cmd:"" help:"Change the display name of the oven"`
+		Share      OvenShareCmd      `cmd:"" help:"Generate a pairing code to share the oven with another account"`
+		Join       OvenJoinCmd       `cmd:"" help:"Add an oven to this account using a pairing code"`
+		Users      OvenUsersCmd      `cmd:"" help:"List the accounts that have access to the oven"`
+		Remove     OvenRemoveCmd     `cmd:"" help:"Remove the oven from this account"`
+		SetDefault OvenSetDefaultCmd `cmd:"" help:"Save the oven as the default oven in the config"`
+		SyncTime   OvenSyncTimeCmd   `cmd:"" help:"Set the oven's time zone to match this computer"`
+	} `cmd:"" help:"Manage a single oven"`
+
+	Cook struct {
+		Start    CookStartCmd    `cmd:"" help:"Start a cook from a JSON recipe, now or at a later time"`
+		Validate CookValidateCmd `cmd:"" help:"Check a JSON recipe without starting it, listing every rule it breaks"`
+		Status   CookStatusCmd   `cmd:"" help:"Show the progress of the running cook, following long cooks across their chained stages"`
+		Program  struct {
+			DeltaT      ProgramDeltaTCmd      `cmd:"" name:"delta-t" help:"Keep the bulb a fixed offset above the probe temperature"`
+			Ramp        ProgramRampCmd        `cmd:"" help:"Change the bulb setpoint linearly over time"`
+			SteamRamp   ProgramSteamRampCmd   `cmd:"" help:"Change the steam linearly over time"`
+			ReverseSear ProgramReverseSearCmd `cmd:"" help:"Switch to a searing setpoint once the probe reaches a temperature"`
+		} `cmd:"" help:"Adjust the running cook with a closed-loop program, until it finishes or the cook ends"`
+	} `cmd:"" help:"Start cooks and control running cooks"`
+
+	Schedule struct {
+		List   ScheduleListCmd   `cmd:"" help:"List scheduled cooks"`
+		Cancel ScheduleCancelCmd `cmd:"" help:"Cancel a scheduled cook"`
+	} `cmd:"" help:"Manage cooks scheduled with \"cook start --at\" or \"--ready-by\""`
+
+	Descale DescaleCmd `cmd:"" help:"Descale the oven's steam generator and follow its progress"`
+
+	Firmware struct {
+		Status FirmwareStatusCmd `cmd:"" help:"Show firmware and hardware versions of every oven, or the oven given with --oven"`
+		Update FirmwareUpdateCmd `cmd:"" help:"Install a firmware image on an idle oven"`
+	} `cmd:"" help:"Monitor and update oven firmware"`
+
+	Diag struct {
+		Health     DiagHealthCmd     `cmd:"" help:"Check that the oven responds to commands"`
+		Request    DiagRequestCmd    `cmd:"" help:"Send a raw diagnostic command and print the response"`
+		Config     DiagConfigCmd     `cmd:"" help:"Print the oven's configuration"`
+		ReportRate DiagReportRateCmd `cmd:"" help:"Change how often the oven reports its state"`
+	} `cmd:"" help:"Diagnose and tune an oven"`
+
+	Exporter ExporterCmd `cmd:"" help:"Serve oven telemetry as Prometheus metrics"`
+	Mqtt     MqttCmd     `cmd:"" name:"mqtt" help:"Bridge ovens to an MQTT broker, with Home Assistant discovery"`
+	Serve    ServeCmd    `cmd:"" help:"Serve a local REST API for the ovens"`
+	Daemon   DaemonCmd   `cmd:"" help:"Share one backend connection with other apocli commands and the URL handler"`
+
+	Telemetry TelemetryCmd `cmd:"" help:"Export oven states to InfluxDB, CSV or NDJSON until interrupted"`
+	Notify    NotifyCmd    `cmd:"" help:"Send notifications about oven events until interrupted"`
+
+	History struct {
+		Record HistoryRecordCmd `cmd:"" help:"Record oven states and cooks until interrupted"`
+		List   HistoryListCmd   `cmd:"" help:"List recorded cooks"`
+		Show   HistoryShowCmd   `cmd:"" help:"Show the stages and temperature profile of a recorded cook"`
+		Export HistoryExportCmd `cmd:"" help:"Export recorded oven states"`
+	} `cmd:"" help:"Review recorded cooks"`
 }
 
 func run() error {
+	ctx := kong.Parse(&cli)
+
+	level := slog.LevelInfo
+	if cli.Verbose || cli.Trace {
+		level = slog.LevelDebug
+	}
+
 	w := os.Stdout
 	opts := &tint.Options{
-		Level:      slog.LevelDebug,
+		Level:      level,
 		TimeFormat: time.DateTime,
 		NoColor:    !isatty.IsTerminal(w.Fd()),
 	}
@@ -40,8 +130,16 @@ func run() error {
 		return err
 	}
 
-	ctx := kong.Parse(&cli)
-	return ctx.Run(&Context{Config: config})
+	return ctx.Run(&Context{
+		Config:         config,
+		OvenSelector:   cli.OvenSelector,
+		Trace:          cli.Trace,
+		NoDaemon:       cli.NoDaemon,
+		StrictDecoding: cli.StrictDecoding,
+		Record:         cli.Record,
+		Replay:         cli.Replay,
+		ReplaySpeed:    cli.ReplaySpeed,
+	})
 }
 
 func main() {
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/mqttbridge"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+type MqttCmd struct {
+	Broker          string `default:"tcp://localhost:1883" help:"MQTT broker URL"`
+	Username        string `help:"MQTT username"`
+	Password        string `help:"MQTT password" env:"APOCLI_MQTT_PASSWORD"`
+	ClientID        string `name:"client-id" default:"apocli" help:"MQTT client ID"`
+	Prefix          string `default:"apocli" help:"Prefix of state and command topics"`
+	DiscoveryPrefix string `default:"homeassistant" help:"Prefix of Home Assistant discovery topics. Empty to disable discovery."`
+
+	longCooksFile    `embed:""`
+	timeZoneSyncFlag `embed:""`
+}
+
+func (cmd *MqttCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	options := mqttbridge.Options{
+		TopicPrefix:     cmd.Prefix,
+		DiscoveryPrefix: cmd.DiscoveryPrefix,
+	}
+	conn, err := mqttbridge.Dial(mqttbridge.DialOptions{
+		Broker:   cmd.Broker,
+		ClientID: cmd.ClientID,
+		Username: cmd.Username,
+		Password: cmd.Password,
+
+		WillTopic:   options.StatusTopic(),
+		WillPayload: "offline",
+	})
+	if err != nil {
+		return err
+	}
+	defer mqttbridge.Close(conn)
+	bridge := mqttbridge.NewBridge(conn, options, cmd.longCooksFile.recordStarted)
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("Bridging ovens to %s. Press Ctrl-C to stop.\n", cmd.Broker)
+	handlers := append([]anova.EventHandler{bridge}, cmd.timeZoneSyncFlag.handlers(interrupted.Done())...)
+	err = session.Dispatch(interrupted.Done(), handlers...)
+	// Dispatch doesn't deliver ServiceStopped when interrupted, and a clean
+	// disconnect doesn't trigger the will
+	bridge.HandleEvent(anova.ServiceStopped{})
+	return err
+}
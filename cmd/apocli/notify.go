@@ -0,0 +1,73 @@
+package main
+
+import (
+	"apocli/pkg/apocli"
+	"apocli/pkg/notifier"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// notificationsConfig is embedded in every command that sends notifications.
+type notificationsConfig struct {
+	NotificationsConfig string `type:"path" help:"Path of the TOML file configuring notification channels. Defaults to notifications.toml in the config folder."`
+}
+
+func (flag notificationsConfig) newNotifier() (*notifier.Notifier, error) {
+	path := flag.NotificationsConfig
+	if path == "" {
+		var err error
+		path, err = apocli.NotificationsConfigPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config, err := notifier.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := config.Routes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid notifications config \"%s\": %+v", path, err)
+	}
+	return notifier.New(routes, config.DoorOpenAfter), nil
+}
+
+type NotifyCmd struct {
+	Test bool `help:"Send a test notification through every channel and exit"`
+
+	notificationsConfig `embed:""`
+}
+
+func (cmd *NotifyCmd) Run(ctx *Context) error {
+	notifications, err := cmd.newNotifier()
+	if err != nil {
+		return err
+	}
+	defer notifications.Close()
+
+	if cmd.Test {
+		notifications.Notify(notifier.Notification{
+			Trigger: notifier.TriggerTest,
+			Title:   "apocli",
+			Message: "This is a test notification.",
+		})
+		// Close waits for it to be sent; failures are logged
+		fmt.Println("Sending a test notification through every channel.")
+		return nil
+	}
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("Sending notifications. Press Ctrl-C to stop.")
+	return session.Dispatch(interrupted.Done(), notifications)
+}
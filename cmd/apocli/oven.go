@@ -0,0 +1,331 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+)
+
+type OvensListCmd struct {
+}
+
+func (cmd *OvensListCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COOKER ID\tNAME\tTYPE\tMODE\tDEFAULT")
+	for _, oven := range session.Ovens {
+		isDefault := ""
+		if oven.CookerID == ctx.Config.DefaultCookerID {
+			isDefault = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			oven.CookerID, oven.Name, oven.Type, oven.State.State.Mode, isDefault)
+	}
+
+	return w.Flush()
+}
+
+type OvenRenameCmd struct {
+	Name string `arg:"" help:"New display name"`
+}
+
+func (cmd *OvenRenameCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	err = oven.SetName(cmd.Name)
+	if err != nil {
+		return fmt.Errorf("failed to rename oven: %+v", err)
+	}
+
+	fmt.Printf("Renamed oven \"%s\" (%s) to \"%s\"\n", oven.Name, oven.CookerID, cmd.Name)
+
+	return nil
+}
+
+type OvenShareCmd struct {
+	QR  bool   `help:"Also render the pairing code as a QR code in the terminal"`
+	PNG string `name:"png" type:"path" placeholder:"FILE" help:"Write the pairing code as a QR code image to a PNG file"`
+}
+
+func (cmd *OvenShareCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	token, err := oven.GeneratePairingCode()
+	if err != nil {
+		return fmt.Errorf("failed to generate pairing code: %+v", err)
+	}
+
+	code, err := anova.ParsePairingCode(token)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pairing code for oven \"%s\" (%s):\n\n%s\n\n", oven.Name, oven.CookerID, code.Token)
+	printPairingCodeClaims(code)
+
+	if cmd.QR {
+		qrCode, err := renderQRCode(code.Token)
+		if err != nil {
+			return fmt.Errorf("failed to render QR code: %+v", err)
+		}
+		fmt.Printf("\n%s", qrCode)
+	}
+	if cmd.PNG != "" {
+		err = writeQRCodePNG(code.Token, cmd.PNG)
+		if err != nil {
+			return fmt.Errorf("failed to write QR code to \"%s\": %+v", cmd.PNG, err)
+		}
+		fmt.Printf("\nWrote QR code to %s\n", cmd.PNG)
+	}
+
+	fmt.Println("\nOn the other account, run:")
+	fmt.Printf("  apocli oven join \"%s\"\n", code.Token)
+
+	return nil
+}
+
+type OvenJoinCmd struct {
+	PairingCode string `arg:"" help:"Pairing code generated by \"apocli oven share\" on another account"`
+}
+
+func (cmd *OvenJoinCmd) Run(ctx *Context) error {
+	// Catch typos and expired codes before bothering the backend
+	code, err := anova.ParsePairingCode(cmd.PairingCode)
+	if err != nil {
+		return err
+	}
+	printPairingCodeClaims(code)
+	err = code.Validate()
+	if err != nil {
+		return err
+	}
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	err = session.Client.AddUserWithPairingCode(code.Token)
+	if err != nil {
+		return fmt.Errorf("failed to join oven: %+v", err)
+	}
+
+	// The backend follows up with the state of the newly paired oven
+	timeout := time.After(ovenStateTimeout)
+	for {
+		select {
+		case event := <-session.Events:
+			if event, ok := event.(anova.OvenAdded); ok {
+				fmt.Printf("Added oven \"%s\" (%s) to the account\n", event.Oven.Name, event.Oven.CookerID)
+				return nil
+			}
+
+		case <-timeout:
+			fmt.Println("Pairing code accepted, but the oven has not reported its state yet")
+			return nil
+		}
+	}
+}
+
+func printPairingCodeClaims(code *anova.PairingCode) {
+	if code.CookerID != "" {
+		fmt.Printf("Cooker ID: %s\n", code.CookerID)
+	}
+	if code.Issuer != "" {
+		fmt.Printf("Issuer:    %s\n", code.Issuer)
+	}
+	if !code.IssuedAt.IsZero() {
+		fmt.Printf("Issued:    %s\n", code.IssuedAt.Local().Format(time.DateTime))
+	}
+	if !code.ExpiresAt.IsZero() {
+		fmt.Printf("Expires:   %s (in %s)\n", code.ExpiresAt.Local().Format(time.DateTime),
+			time.Until(code.ExpiresAt).Round(time.Minute))
+	}
+}
+
+type OvenUsersCmd struct {
+}
+
+func (cmd *OvenUsersCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	userIDs, err := oven.ListAccounts()
+	if err != nil {
+		return fmt.Errorf("failed to list users: %+v", err)
+	}
+
+	for _, userID := range userIDs {
+		fmt.Println(userID)
+	}
+
+	return nil
+}
+
+type OvenRemoveCmd struct {
+	Yes bool `short:"y" help:"Skip the confirmation prompt"`
+}
+
+func (cmd *OvenRemoveCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Yes {
+		fmt.Println("If no other account is paired with the oven, it will need to go through Wi-Fi setup again.")
+		if !confirm(fmt.Sprintf("Remove oven \"%s\" (%s) from this account?", oven.Name, oven.CookerID)) {
+			return nil
+		}
+	}
+
+	err = oven.DisconnectFromAccount()
+	if err != nil {
+		return fmt.Errorf("failed to remove oven: %+v", err)
+	}
+
+	fmt.Printf("Removed oven \"%s\" (%s)\n", oven.Name, oven.CookerID)
+
+	if oven.CookerID == ctx.Config.DefaultCookerID {
+		ctx.Config.DefaultCookerID = ""
+		err = ctx.Config.Save()
+		if err != nil {
+			return fmt.Errorf("failed to save config after updating: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+type OvenSetDefaultCmd struct {
+}
+
+func (cmd *OvenSetDefaultCmd) Run(ctx *Context) error {
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+
+	ctx.Config.DefaultCookerID = oven.CookerID
+	err = ctx.Config.Save()
+	if err != nil {
+		return fmt.Errorf("failed to save config after updating: %+v", err)
+	}
+
+	fmt.Printf("DefaultCookerID = %s (\"%s\")\n", oven.CookerID, oven.Name)
+
+	return nil
+}
+
+type OvenSyncTimeCmd struct {
+	Zone  string `help:"IANA name of the time zone to set, e.g. \"America/New_York\". Defaults to the local time zone."`
+	Watch bool   `help:"Keep running and update every oven again after each daylight saving time change"`
+}
+
+func (cmd *OvenSyncTimeCmd) Run(ctx *Context) error {
+	location := time.Local
+	if cmd.Zone != "" {
+		var err error
+		location, err = time.LoadLocation(cmd.Zone)
+		if err != nil {
+			return fmt.Errorf("unknown time zone \"%s\": %+v", cmd.Zone, err)
+		}
+	}
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if !cmd.Watch {
+		oven, err := ctx.SelectOven(session)
+		if err != nil {
+			return err
+		}
+
+		err = oven.SetTimeZone(location)
+		if err != nil {
+			return fmt.Errorf("failed to set time zone: %+v", err)
+		}
+
+		zone, _ := time.Now().In(location).Zone()
+		fmt.Printf("Set time zone of oven \"%s\" (%s) to %s (%s)\n", oven.Name, oven.CookerID, location, zone)
+		return nil
+	}
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	timeZoneSync := anova.NewTimeZoneSync(location)
+	go timeZoneSync.Run(interrupted.Done())
+
+	fmt.Printf("Keeping all ovens in sync with %s. Press Ctrl-C to stop.\n", location)
+	return session.Dispatch(interrupted.Done(), timeZoneSync)
+}
+
+// timeZoneSyncFlag keeps the ovens' time zones in sync with this computer's
+// while a long-running command is running, if asked to.
+type timeZoneSyncFlag struct {
+	SyncTimeZone bool `help:"Keep every oven's time zone in sync with this computer's, e.g. across daylight saving time changes"`
+}
+
+// handlers starts keeping the ovens in sync until done is closed, returning the
+// handler that must receive their events. It returns nothing if syncing is
+// disabled.
+func (flag timeZoneSyncFlag) handlers(done <-chan struct{}) []anova.EventHandler {
+	if !flag.SyncTimeZone {
+		return nil
+	}
+	timeZoneSync := anova.NewTimeZoneSync(time.Local)
+	go timeZoneSync.Run(done)
+	return []anova.EventHandler{timeZoneSync}
+}
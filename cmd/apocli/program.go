@@ -0,0 +1,116 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"apocli/pkg/program"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// programMode is embedded in every program command that sets the bulb
+// temperature.
+type programMode struct {
+	Mode string `enum:"dry,wet" default:"dry" help:"Bulb to control: dry, or wet for sous vide (${enum})"`
+}
+
+func (flag programMode) mode() anova.TemperatureMode {
+	return anova.TemperatureMode(flag.Mode)
+}
+
+type ProgramDeltaTCmd struct {
+	Offset float64 `arg:"" help:"Degrees Celsius to keep the bulb above the probe temperature"`
+
+	programMode `embed:""`
+}
+
+func (cmd *ProgramDeltaTCmd) Run(ctx *Context) error {
+	return runProgram(ctx, program.DeltaT{Mode: cmd.mode(), OffsetCelsius: cmd.Offset})
+}
+
+type ProgramRampCmd struct {
+	From anova.Temperature `arg:"" help:"Setpoint to start from, e.g. 60C or 140F; bare numbers are degrees Celsius"`
+	To   anova.Temperature `arg:"" help:"Setpoint to end at"`
+	Over time.Duration     `arg:"" help:"Time to ramp over, e.g. 2h"`
+
+	programMode `embed:""`
+}
+
+func (cmd *ProgramRampCmd) Run(ctx *Context) error {
+	return runProgram(ctx, program.TemperatureRamp{Mode: cmd.mode(), FromCelsius: cmd.From.Celsius(), ToCelsius: cmd.To.Celsius(), Over: cmd.Over})
+}
+
+type ProgramSteamRampCmd struct {
+	From float64       `arg:"" help:"Relative humidity (wet mode) or steam percentage (dry mode) to start from"`
+	To   float64       `arg:"" help:"Relative humidity or steam percentage to end at"`
+	Over time.Duration `arg:"" help:"Time to ramp over, e.g. 30m"`
+}
+
+func (cmd *ProgramSteamRampCmd) Run(ctx *Context) error {
+	return runProgram(ctx, program.SteamRamp{FromPercentage: cmd.From, ToPercentage: cmd.To, Over: cmd.Over})
+}
+
+type ProgramReverseSearCmd struct {
+	Probe anova.Temperature `arg:"" help:"Probe temperature to start searing at, e.g. 50C or 122F; bare numbers are degrees Celsius"`
+	Sear  anova.Temperature `arg:"" help:"Dry bulb setpoint to sear at"`
+}
+
+func (cmd *ProgramReverseSearCmd) Run(ctx *Context) error {
+	return runProgram(ctx, program.ReverseSear{ProbeCelsius: cmd.Probe.Celsius(), Sear: anova.NewSetpointTemperature(cmd.Sear, anova.TemperatureModeDry)})
+}
+
+// runProgram runs the program against the cook running on the selected oven,
+// until the program finishes, the cook ends or the user interrupts it.
+func runProgram(ctx *Context, cookProgram program.Program) error {
+	if err := cookProgram.Validate(); err != nil {
+		return err
+	}
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	oven, err := ctx.SelectOven(session)
+	if err != nil {
+		return err
+	}
+	if oven.State.State.Mode != dto.StateModeCook {
+		return program.ErrNotCooking{}
+	}
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	runner := program.NewRunner(cookProgram, oven.CookerID)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-runner.Done():
+		case <-interrupted.Done():
+		}
+		close(done)
+	}()
+
+	fmt.Printf("Running %s on oven \"%s\" (%s). Press Ctrl-C to stop.\n", cookProgram, oven.Name, oven.CookerID)
+	err = session.Dispatch(done, runner)
+	runner.Wait()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-runner.Done():
+		if err := runner.Err(); err != nil {
+			return err
+		}
+		fmt.Println("Program finished.")
+	default:
+		fmt.Println("Program stopped; the cook keeps running at its current setpoints.")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/skip2/go-qrcode"
+	"strings"
+)
+
+// qrCodePNGSize is the width and height of QR code images, in pixels
+const qrCodePNGSize = 512
+
+// renderQRCode draws a QR code with ANSI colors. Each character covers two
+// rows of modules, and the colors are set explicitly so that the code scans on
+// both light and dark terminal themes.
+func renderQRCode(content string) (string, error) {
+	code, err := qrcode.New(content, qrcode.Low)
+	if err != nil {
+		return "", err
+	}
+
+	// Includes the quiet zone around the code
+	bitmap := code.Bitmap()
+
+	var sb strings.Builder
+	for y := 0; y < len(bitmap); y += 2 {
+		for x := range bitmap[y] {
+			top := bitmap[y][x]
+			bottom := y+1 < len(bitmap) && bitmap[y+1][x]
+
+			// Foreground draws the upper half block, background the lower half
+			if top {
+				sb.WriteString("\x1b[30m")
+			} else {
+				sb.WriteString("\x1b[97m")
+			}
+			if bottom {
+				sb.WriteString("\x1b[40m")
+			} else {
+				sb.WriteString("\x1b[107m")
+			}
+			sb.WriteString("▀")
+		}
+		sb.WriteString("\x1b[0m\n")
+	}
+
+	return sb.String(), nil
+}
+
+func writeQRCodePNG(content string, path string) error {
+	return qrcode.WriteFile(content, qrcode.Medium, qrCodePNGSize, path)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/apiserver"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+type ServeCmd struct {
+	Listen string `default:":8080" help:"Address to serve the API on"`
+	Token  string `env:"APOCLI_API_TOKEN" help:"Bearer token required by the API. Defaults to the APIToken in the config, which is generated if unset."`
+
+	longCooksFile    `embed:""`
+	timeZoneSyncFlag `embed:""`
+}
+
+func (cmd *ServeCmd) Run(ctx *Context) error {
+	token, err := cmd.resolveToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	api := apiserver.NewServer(token, cmd.longCooksFile.recordStarted)
+	server := &http.Server{
+		Addr:              cmd.Listen,
+		Handler:           api.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- server.ListenAndServe()
+	}()
+	defer server.Shutdown(context.Background())
+
+	fmt.Printf("Serving API on http://%s (OpenAPI document at /openapi.json). Press Ctrl-C to stop.\n", cmd.Listen)
+
+	dispatchErrors := make(chan error, 1)
+	go func() {
+		handlers := append([]anova.EventHandler{api}, cmd.timeZoneSyncFlag.handlers(interrupted.Done())...)
+		dispatchErrors <- session.Dispatch(interrupted.Done(), handlers...)
+	}()
+
+	select {
+	case err := <-serverErrors:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("API server failed: %+v", err)
+		}
+		return nil
+	case err := <-dispatchErrors:
+		if err != nil {
+			slog.Error("stopped receiving events", slog.Any("err", err))
+		}
+		return err
+	}
+}
+
+func (cmd *ServeCmd) resolveToken(ctx *Context) (string, error) {
+	if cmd.Token != "" {
+		return cmd.Token, nil
+	}
+	if ctx.Config.APIToken != "" {
+		return ctx.Config.APIToken, nil
+	}
+
+	token, err := apiserver.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	ctx.Config.APIToken = token
+	err = ctx.Config.Save()
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Generated API token %s and saved it to the config\n", token)
+	return token, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// ovenStateTimeout is how long to wait for the backend to send the state of
+// every oven on the account after connecting
+const ovenStateTimeout = 5 * time.Second
+
+// Session is a connection to the backend with the account's ovens loaded.
+type Session struct {
+	Client  *anova.Client
+	Service *anova.Service
+
+	// Ovens paired with the account, in the order listed by the backend
+	Ovens []*anova.Oven
+	// Events that announced the ovens, replayed to handlers by Dispatch
+	ovenAddedEvents []anova.OvenAdded
+
+	// Service events received after the ovens were loaded
+	Events chan anova.Event
+}
+
+// Connect opens a new client, through the daemon if one is running or from a
+// capture with --replay, and blocks until the state of every oven on the
+// account has been received.
+func (ctx *Context) Connect(options ...func(*anova.Client) error) (*Session, error) {
+	if ctx.Trace {
+		options = append(options, anova.OptionPrintMessageTraces)
+	}
+	if ctx.StrictDecoding {
+		options = append(options, anova.OptionStrictDecoding)
+	}
+	if ctx.Record != "" {
+		options = append(options, anova.OptionRecordSession(ctx.Record))
+	}
+
+	var client *anova.Client
+	var err error
+	if ctx.Replay != "" {
+		client, err = anova.NewClient("", append(options, anova.OptionReplaySession(ctx.Replay, ctx.ReplaySpeed))...)
+	} else if ctx.NoDaemon {
+		client, err = anova.NewClient(ctx.Config.FirebaseRefreshToken, options...)
+	} else {
+		client, err = ctx.Config.NewClient(options...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %+v", err)
+	}
+
+	session := &Session{
+		Client:  client,
+		Service: anova.NewService(client),
+
+		Events: make(chan anova.Event, 100),
+	}
+	go session.forwardEvents()
+
+	err = session.waitForOvens()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (session *Session) Close() {
+	session.Client.Close()
+}
+
+func (session *Session) forwardEvents() {
+	for {
+		event := session.Service.ReadEvent()
+		session.Events <- event
+
+		if _, stopped := event.(anova.ServiceStopped); stopped {
+			return
+		}
+	}
+}
+
+func (session *Session) waitForOvens() error {
+	var listedCookerIDs []anova.CookerID
+	listed := false
+	addedOvens := make(map[anova.CookerID]anova.OvenAdded)
+
+	timeout := time.After(ovenStateTimeout)
+	timedOut := false
+	for !timedOut {
+		select {
+		case event := <-session.Events:
+			switch event := event.(type) {
+			case anova.OvenListUpdated:
+				listedCookerIDs = event.CookerIDs
+				listed = true
+			case anova.OvenAdded:
+				addedOvens[event.Oven.CookerID] = event
+			case anova.ServiceStopped:
+				return errors.New("connection closed before ovens were loaded")
+			}
+
+		case <-timeout:
+			if !listed {
+				return errors.New("timed out waiting for list of ovens")
+			}
+			timedOut = true
+		}
+
+		if !listed {
+			continue
+		}
+
+		complete := true
+		session.Ovens = nil
+		session.ovenAddedEvents = nil
+		for _, cookerID := range listedCookerIDs {
+			ovenAdded, exists := addedOvens[cookerID]
+			if !exists {
+				complete = false
+				// Offline ovens might never report their state, so carry on
+				// with whichever ovens did
+				if timedOut {
+					slog.Warn("timed out waiting for oven state",
+						slog.String("cookerID", string(cookerID)))
+				}
+				continue
+			}
+			session.Ovens = append(session.Ovens, ovenAdded.Oven)
+			session.ovenAddedEvents = append(session.ovenAddedEvents, ovenAdded)
+		}
+		if complete {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Dispatch passes every event to the handlers until the service stops or done
+// is closed. The handlers first receive an OvenAdded event for each oven that
+// was loaded while connecting.
+func (session *Session) Dispatch(done <-chan struct{}, handlers ...anova.EventHandler) error {
+	for _, event := range session.ovenAddedEvents {
+		for _, handler := range handlers {
+			handler.HandleEvent(event)
+		}
+	}
+
+	for {
+		select {
+		case event := <-session.Events:
+			for _, handler := range handlers {
+				handler.HandleEvent(event)
+			}
+			if _, stopped := event.(anova.ServiceStopped); stopped {
+				return errors.New("connection to the backend was closed")
+			}
+
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// FindOven looks up an oven by its cooker ID, or else by its case-insensitive
+// name.
+func (session *Session) FindOven(selector string) (*anova.Oven, error) {
+	for _, oven := range session.Ovens {
+		if string(oven.CookerID) == selector {
+			return oven, nil
+		}
+	}
+	for _, oven := range session.Ovens {
+		if strings.EqualFold(oven.Name, selector) {
+			return oven, nil
+		}
+	}
+	return nil, fmt.Errorf("no oven matching \"%s\"", selector)
+}
+
+// SelectOven returns the oven chosen with --oven, falling back to the default
+// cooker ID from the config and then to the first oven on the account.
+func (ctx *Context) SelectOven(session *Session) (*anova.Oven, error) {
+	if ctx.OvenSelector != "" {
+		return session.FindOven(ctx.OvenSelector)
+	}
+	if ctx.Config.DefaultCookerID != "" {
+		return session.FindOven(string(ctx.Config.DefaultCookerID))
+	}
+	if len(session.Ovens) == 0 {
+		return nil, errors.New("no ovens are paired with the account")
+	}
+	return session.Ovens[0], nil
+}
+
+// TemperatureUnit returns the unit to show temperatures in: the one from the
+// config, falling back to the oven's unit, if known, and then to Celsius.
+func (ctx *Context) TemperatureUnit(ovenUnit dto.TemperatureUnit) dto.TemperatureUnit {
+	switch {
+	case ctx.Config.TemperatureUnit != "":
+		return dto.TemperatureUnit(strings.ToUpper(string(ctx.Config.TemperatureUnit)))
+	case ovenUnit != "":
+		return ovenUnit
+	}
+	return dto.TemperatureUnitCelsius
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no.
+func confirm(message string) bool {
+	fmt.Printf("%s [y/N] ", message)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"apocli/pkg/telemetry"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+type TelemetryCmd struct {
+	InfluxURL   string        `name:"influx-url" help:"InfluxDB write endpoint, e.g. \"http://localhost:8086/api/v2/write?org=home&bucket=ovens\""`
+	InfluxToken string        `name:"influx-token" env:"APOCLI_INFLUX_TOKEN" help:"InfluxDB API token"`
+	InfluxFile  string        `name:"influx-file" type:"path" help:"File to append InfluxDB line protocol to"`
+	CSV         string        `name:"csv" type:"path" help:"CSV file to append samples to"`
+	NDJSON      string        `name:"ndjson" type:"path" help:"NDJSON file to append samples to"`
+	MaxSizeMB   int64         `name:"max-size-mb" default:"0" help:"Rotate files once they reach this size in MB. 0 to disable."`
+	RotateEvery time.Duration `default:"0" help:"Rotate files after this long, e.g. 24h. 0 to disable."`
+}
+
+func (cmd *TelemetryCmd) Run(ctx *Context) error {
+	options := telemetry.RotationOptions{
+		MaxBytes: cmd.MaxSizeMB * 1024 * 1024,
+		MaxAge:   cmd.RotateEvery,
+	}
+
+	var sinks []telemetry.Sink
+	addFileSink := func(path string, header []byte, newSink func(file *telemetry.RotatingFile) telemetry.Sink) error {
+		if path == "" {
+			return nil
+		}
+		file, err := telemetry.NewRotatingFile(path, options, header)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, newSink(file))
+		return nil
+	}
+
+	if cmd.InfluxURL != "" {
+		sinks = append(sinks, telemetry.NewInfluxHTTPSink(cmd.InfluxURL, cmd.InfluxToken))
+	}
+	err := errors.Join(
+		addFileSink(cmd.InfluxFile, nil, func(file *telemetry.RotatingFile) telemetry.Sink {
+			return telemetry.NewInfluxWriterSink(file)
+		}),
+		addFileSink(cmd.CSV, telemetry.CSVHeader(), func(file *telemetry.RotatingFile) telemetry.Sink {
+			return telemetry.NewCSVSink(file)
+		}),
+		addFileSink(cmd.NDJSON, nil, func(file *telemetry.RotatingFile) telemetry.Sink {
+			return telemetry.NewNDJSONSink(file)
+		}),
+	)
+	handler := telemetry.NewHandler(sinks...)
+	defer handler.Close()
+	if err != nil {
+		return err
+	}
+	if len(sinks) == 0 {
+		return errors.New("at least one sink is required; use --influx-url, --influx-file, --csv or --ndjson")
+	}
+
+	session, err := ctx.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("Exporting oven telemetry. Press Ctrl-C to stop.")
+	return session.Dispatch(interrupted.Done(), handler)
+}
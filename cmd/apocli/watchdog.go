@@ -0,0 +1,52 @@
+package main
+
+import (
+	"apocli/pkg/notifier"
+	"apocli/pkg/watchdog"
+	"time"
+)
+
+// watchdogRules is embedded in `apocli daemon` with a "watchdog-" prefix. The
+// defaults match watchdog.DefaultRules.
+type watchdogRules struct {
+	MaxCookTime        time.Duration `default:"0" help:"Stop cooks that run for longer than this, e.g. 12h. 0 to disable."`
+	MaxOvershoot       float64       `default:"25" help:"Stop cooks whose dry bulb is this many °C above its setpoint for longer than --watchdog-overshoot-for. 0 to disable."`
+	OvershootFor       time.Duration `default:"2m" help:"How long the dry bulb may overshoot its setpoint"`
+	StopOnFailure      bool          `default:"true" negatable:"" help:"Stop cooks when the oven reports a failed or overheated component"`
+	MaxDoorOpen        time.Duration `default:"10m" help:"Stop cooks whose door is open for longer than this while hotter than --watchdog-door-open-min-celsius. 0 to disable."`
+	DoorOpenMinCelsius float64       `default:"100" help:"Dry bulb temperature above which the door may not be left open"`
+	StopWhenOffline    bool          `default:"true" negatable:"" help:"Stop cooks that were running when the oven went offline, once it is back online"`
+}
+
+func (flags watchdogRules) rules() watchdog.Rules {
+	return watchdog.Rules{
+		MaxCookDuration:     flags.MaxCookTime,
+		MaxOvershootCelsius: flags.MaxOvershoot,
+		OvershootFor:        flags.OvershootFor,
+		StopOnFailure:       flags.StopOnFailure,
+		MaxDoorOpen:         flags.MaxDoorOpen,
+		DoorOpenMinCelsius:  flags.DoorOpenMinCelsius,
+		StopWhenOffline:     flags.StopWhenOffline,
+	}
+}
+
+// newWatchdog creates a watchdog that also sends a notification for every
+// intervention, if notifications are enabled.
+func newWatchdog(rules watchdog.Rules, notifications *notifier.Notifier) (*watchdog.Watchdog, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return watchdog.New(rules, func(intervention watchdog.Intervention) {
+		if notifications == nil {
+			return
+		}
+		notifications.Notify(notifier.Notification{
+			Trigger:  notifier.TriggerWatchdog,
+			CookerID: intervention.CookerID,
+			OvenName: intervention.OvenName,
+			Time:     intervention.Time,
+			Title:    intervention.OvenName + ": Watchdog",
+			Message:  "The watchdog " + intervention.String() + ".",
+		})
+	}), nil
+}
@@ -103,23 +103,11 @@ func parseStartCookParams(paramValues url.Values) (startCookParams, error) {
 		return params, fmt.Errorf("invalid Mode \"%s\": %+v", rawParams.Mode, params)
 	}
 
-	tempRegex := regexp.MustCompile(`^([0-9]{1,3}(\.[0-9]*)?)([cfCF])$`)
-	tempGroups := tempRegex.FindStringSubmatch(rawParams.Temperature)
-	if tempGroups == nil {
-		return params, fmt.Errorf("invalid temp \"%s\"", rawParams.Temperature)
-	}
-	tempValue, err := strconv.ParseFloat(tempGroups[1], 64)
+	temperature, err := anova.ParseTemperature(rawParams.Temperature)
 	if err != nil {
-		return params, fmt.Errorf("invalid temp value \"%s\"", tempGroups[1])
-	}
-	switch strings.ToLower(tempGroups[3]) {
-	case "c":
-		params.temp.TemperatureCelsius = tempValue
-	case "f":
-		params.temp.TemperatureCelsius = dto.FahrenheitToCelsius(tempValue)
-	default:
-		return params, fmt.Errorf("invalid temp unit \"%s\"", tempGroups[3])
+		return params, err
 	}
+	params.temp.TemperatureCelsius = temperature.Celsius()
 
 	for _, element := range strings.Split(rawParams.HeatingElements, ",") {
 		switch strings.ToLower(element) {
@@ -134,10 +122,10 @@ func parseStartCookParams(paramValues url.Values) (startCookParams, error) {
 		}
 	}
 
-	if rawParams.SteamPercentage == 0 {
-		params.steam = anova.NoSteam
-	} else {
+	if (params.temp.Mode == anova.TemperatureModeWet && paramValues.Has("steam")) || rawParams.SteamPercentage != 0 {
 		params.steam = anova.NewSteamPercentage(rawParams.SteamPercentage)
+	} else {
+		params.steam = anova.NoSteam
 	}
 
 	switch strings.ToLower(rawParams.FanSpeed) {
@@ -282,7 +270,9 @@ func run() error {
 		cookerID = anova.CookerID(rawCookerID)
 	}
 
-	client, err := anova.NewClient(config.FirebaseRefreshToken, anova.OptionPrintMessageTraces)
+	// Connect through the daemon if it's running, which skips authenticating and
+	// waiting for the backend to send the oven states
+	client, err := config.NewClient(anova.OptionPrintMessageTraces)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %+v", err)
 	}
@@ -20,8 +20,10 @@ func TestStartCookParams(t *testing.T) {
 
 		params, err := parseStartCookParams(rawParams)
 		assert.Equal(t, nil, err)
+		// 80 °F is converted exactly, to 26.67 °C rather than 27 °C, and only
+		// rounded when the cook is sent to the oven
 		assert.Equal(t, anova.TemperatureSetpoint{
-			TemperatureCelsius: 27,
+			TemperatureCelsius: (80 - 32) * 5 / 9.0,
 			Mode:               anova.TemperatureModeWet,
 		}, params.temp)
 		assert.Equal(t, anova.HeatingElements{
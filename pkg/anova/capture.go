@@ -0,0 +1,326 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedValue replaces tokens in session captures.
+const redactedValue = "REDACTED"
+
+// jwtPattern matches JSON Web Tokens, e.g. pairing codes, wherever they appear.
+var jwtPattern = regexp.MustCompile(`^eyJ[\w-]*\.[\w-]*\.[\w-]*$`)
+
+type CaptureDirection string
+
+const (
+	CaptureDirectionSend CaptureDirection = "send"
+	CaptureDirectionRecv CaptureDirection = "recv"
+)
+
+// CaptureEntry is one line of a session capture file: a message sent to or
+// received from the backend.
+type CaptureEntry struct {
+	Time      time.Time        `json:"time"`
+	Direction CaptureDirection `json:"direction"`
+	Message   dto.RawMessage   `json:"message"`
+}
+
+// OptionRecordSession appends every message sent and received by the client to
+// a JSONL capture file at path, with tokens redacted. Captures can be replayed
+// with OptionReplaySession.
+func OptionRecordSession(path string) func(*Client) error {
+	return func(client *Client) error {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open capture file \"%s\": %+v", path, err)
+		}
+		client.sessionRecorder = &sessionRecorder{writer: file}
+		return nil
+	}
+}
+
+// OptionReplaySession feeds a capture written by OptionRecordSession to the
+// client instead of connecting to the backend. Speed scales the delays between
+// received messages, e.g. 10 replays ten times faster than real time, and 0
+// replays them without any delay. Once every message has been replayed, the
+// client idles until closed.
+//
+// Commands sent during a replay have no effect. They are acknowledged with the
+// captured response to the same command, in order, or a bare "ok" response if
+// the capture has none left.
+func OptionReplaySession(path string, speed float64) func(*Client) error {
+	return func(client *Client) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open capture file \"%s\": %+v", path, err)
+		}
+		defer file.Close()
+
+		entries, err := ReadCapture(file)
+		if err != nil {
+			return fmt.Errorf("failed to read capture file \"%s\": %+v", path, err)
+		}
+		client.replay = newReplayTransport(entries, speed)
+		return nil
+	}
+}
+
+// ReadCapture parses a JSONL session capture.
+func ReadCapture(reader io.Reader) ([]CaptureEntry, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxSocketFrameSize)
+
+	var entries []CaptureEntry
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry CaptureEntry
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %+v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// RedactFrame replaces the values of any fields named like tokens, and any
+// strings that look like JWTs, in a JSON frame.
+func RedactFrame(frame []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(frame))
+	// Keep numbers exactly as sent
+	dec.UseNumber()
+	var value interface{}
+	err := dec.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactValue(value))
+}
+
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if _, isString := field.(string); isString && strings.Contains(strings.ToLower(key), "token") {
+				value[key] = redactedValue
+			} else {
+				value[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, element := range value {
+			value[i] = redactValue(element)
+		}
+	case string:
+		if jwtPattern.MatchString(value) {
+			return redactedValue
+		}
+	}
+	return value
+}
+
+// sessionRecorder writes capture entries for a recordingTransport.
+type sessionRecorder struct {
+	writer io.WriteCloser
+	mutex  sync.Mutex
+	closed bool
+}
+
+func (recorder *sessionRecorder) record(direction CaptureDirection, frame []byte) {
+	redacted, err := RedactFrame(frame)
+	if err == nil {
+		entry := CaptureEntry{Time: time.Now().UTC(), Direction: direction}
+		err = json.Unmarshal(redacted, &entry.Message)
+		if err == nil {
+			redacted, err = json.Marshal(entry)
+		}
+	}
+	if err != nil {
+		slog.Warn("failed to capture message", slog.Any("err", err))
+		return
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if recorder.closed {
+		return
+	}
+	_, err = recorder.writer.Write(append(redacted, '\n'))
+	if err != nil {
+		slog.Error("failed to write to capture file", slog.Any("err", err))
+	}
+}
+
+func (recorder *sessionRecorder) Close() error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.closed = true
+	return recorder.writer.Close()
+}
+
+// recordingTransport captures every frame passing through another transport.
+type recordingTransport struct {
+	transport
+	recorder *sessionRecorder
+}
+
+func (transport *recordingTransport) ReadMessage() ([]byte, error) {
+	frame, err := transport.transport.ReadMessage()
+	if err == nil {
+		transport.recorder.record(CaptureDirectionRecv, frame)
+	}
+	return frame, err
+}
+
+func (transport *recordingTransport) WriteMessage(frame []byte) error {
+	err := transport.transport.WriteMessage(frame)
+	if err == nil {
+		transport.recorder.record(CaptureDirectionSend, frame)
+	}
+	return err
+}
+
+func (transport *recordingTransport) Close() error {
+	err := transport.transport.Close()
+	recorderErr := transport.recorder.Close()
+	if err != nil {
+		return err
+	}
+	return recorderErr
+}
+
+// replayTransport plays back the received messages of a capture.
+type replayTransport struct {
+	// Received messages other than responses, which are only replayed when
+	// the client sends a matching command
+	frames []CaptureEntry
+	// When the capture started; frames are delayed relative to it
+	origin time.Time
+	speed  float64
+
+	// Captured response payloads for each command, in the order sent
+	responsesMutex sync.Mutex
+	responses      map[dto.MessageType][]json.RawMessage
+
+	start     time.Time
+	next      int
+	pending   chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newReplayTransport(entries []CaptureEntry, speed float64) *replayTransport {
+	transport := &replayTransport{
+		speed:     speed,
+		responses: make(map[dto.MessageType][]json.RawMessage),
+		pending:   make(chan []byte, 100),
+		closed:    make(chan struct{}),
+	}
+	if len(entries) > 0 {
+		transport.origin = entries[0].Time
+	}
+
+	sentCommands := make(map[dto.RequestID]dto.MessageType)
+	for _, entry := range entries {
+		message := entry.Message
+		switch {
+		case entry.Direction == CaptureDirectionSend && message.RequestID != nil:
+			sentCommands[*message.RequestID] = message.Command
+		case entry.Direction == CaptureDirectionRecv && message.Command == "RESPONSE":
+			if message.RequestID == nil {
+				continue
+			}
+			if command, exists := sentCommands[*message.RequestID]; exists {
+				transport.responses[command] = append(transport.responses[command], message.Payload)
+			}
+		case entry.Direction == CaptureDirectionRecv:
+			transport.frames = append(transport.frames, entry)
+		}
+	}
+	return transport
+}
+
+func (transport *replayTransport) ReadMessage() ([]byte, error) {
+	if transport.start.IsZero() {
+		transport.start = time.Now()
+	}
+
+	// Idle once the capture is exhausted, other than answering commands
+	var due <-chan time.Time
+	if transport.next < len(transport.frames) {
+		delay := time.Duration(0)
+		if transport.speed > 0 {
+			offset := transport.frames[transport.next].Time.Sub(transport.origin)
+			delay = time.Duration(float64(offset)/transport.speed) - time.Since(transport.start)
+		}
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		due = timer.C
+	}
+
+	select {
+	case frame := <-transport.pending:
+		return frame, nil
+	case <-due:
+		entry := transport.frames[transport.next]
+		transport.next++
+		return json.Marshal(entry.Message)
+	case <-transport.closed:
+		return nil, errTransportClosed
+	}
+}
+
+func (transport *replayTransport) WriteMessage(frame []byte) error {
+	var message dto.RawMessage
+	err := json.Unmarshal(frame, &message)
+	if err != nil {
+		return err
+	}
+	if message.RequestID == nil {
+		return nil
+	}
+
+	payload := json.RawMessage(`{"status":"ok"}`)
+	transport.responsesMutex.Lock()
+	if responses := transport.responses[message.Command]; len(responses) > 0 {
+		payload = responses[0]
+		transport.responses[message.Command] = responses[1:]
+	}
+	transport.responsesMutex.Unlock()
+
+	response, err := json.Marshal(dto.RawMessage{
+		RequestID: message.RequestID,
+		Command:   "RESPONSE",
+		Payload:   payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case transport.pending <- response:
+		return nil
+	case <-transport.closed:
+		return errTransportClosed
+	}
+}
+
+func (transport *replayTransport) Close() error {
+	transport.closeOnce.Do(func() {
+		close(transport.closed)
+	})
+	return nil
+}
@@ -2,7 +2,6 @@ package anova
 
 import (
 	"apocli/pkg/anova/dto"
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +10,7 @@ import (
 	"github.com/xeipuuv/gojsonschema"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -38,10 +38,26 @@ const (
 )
 
 type Client struct {
-	conn      *websocket.Conn
+	conn      transport
 	connMutex sync.Mutex
 
 	printMessageTraces bool
+	commandObservers   []CommandObserver
+	// Whether to drop messages that don't match the DTOs exactly
+	strictDecoding         bool
+	unknownFieldsObservers []UnknownFieldsObserver
+	// Unknown fields already logged, by command; only accessed from
+	// receiveMessages
+	loggedUnknownFields map[string]bool
+	// Path of a daemon's socket to connect through instead of the backend
+	daemonSocketPath string
+	// Called with every frame received before it is decoded. Frames for which
+	// it returns true are not processed any further.
+	frameInterceptor func(frame []byte) bool
+	// Captures every frame sent and received, if set
+	sessionRecorder *sessionRecorder
+	// Replays a capture instead of connecting anywhere, if set
+	replay *replayTransport
 
 	stop    chan bool
 	stopped bool
@@ -55,20 +71,31 @@ func OptionPrintMessageTraces(client *Client) error {
 	return nil
 }
 
-func NewClient(firebaseRefreshToken string, options ...func(*Client) error) (client *Client, err error) {
-	// Grab an ID token from Firebase Auth
-	//TODO we probably need to save the refresh token in order to re-auth
-	// if our WebSocket connection dies after more than an hour
-	firebaseIdToken, err := getFirebaseIdToken(firebaseRefreshToken)
-	if err != nil {
-		return nil, err
+// CommandObserver is called after each command sent by the client completes,
+// with the time taken to receive a response and the error, if any.
+type CommandObserver func(messageType dto.MessageType, duration time.Duration, err error)
+
+// OptionCommandObserver registers a function to be called after every command,
+// e.g. to collect latency metrics.
+func OptionCommandObserver(observer CommandObserver) func(*Client) error {
+	return func(client *Client) error {
+		client.commandObservers = append(client.commandObservers, observer)
+		return nil
 	}
+}
 
-	conn, err := connectWebsocket(firebaseIdToken)
+// OptionDaemonSocket connects through the daemon listening on the Unix socket
+// at path, instead of opening a new connection to the backend. The Firebase
+// refresh token passed to NewClient is not used.
+func OptionDaemonSocket(path string) func(*Client) error {
+	return func(client *Client) error {
+		client.daemonSocketPath = path
+		return nil
+	}
+}
 
+func NewClient(firebaseRefreshToken string, options ...func(*Client) error) (client *Client, err error) {
 	client = &Client{
-		conn: conn,
-
 		printMessageTraces: false,
 
 		stop:    make(chan bool),
@@ -86,6 +113,42 @@ func NewClient(firebaseRefreshToken string, options ...func(*Client) error) (cli
 		}
 	}
 
+	if client.sessionRecorder != nil {
+		defer func() {
+			if err != nil {
+				_ = client.sessionRecorder.Close()
+			}
+		}()
+	}
+
+	if client.replay != nil {
+		client.conn = client.replay
+	} else if client.daemonSocketPath != "" {
+		conn, err := net.Dial("unix", client.daemonSocketPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to daemon socket \"%s\": %+v", client.daemonSocketPath, err)
+		}
+		client.conn = newSocketTransport(conn)
+	} else {
+		// Grab an ID token from Firebase Auth
+		//TODO we probably need to save the refresh token in order to re-auth
+		// if our WebSocket connection dies after more than an hour
+		firebaseIdToken, err := getFirebaseIdToken(firebaseRefreshToken)
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := connectWebsocket(firebaseIdToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to backend: %+v", err)
+		}
+		client.conn = &websocketTransport{conn: conn}
+	}
+
+	if client.sessionRecorder != nil {
+		client.conn = &recordingTransport{transport: client.conn, recorder: client.sessionRecorder}
+	}
+
 	go client.receiveMessages()
 
 	return client, nil
@@ -99,16 +162,11 @@ func (client *Client) Close() {
 	client.stop <- true
 	client.stopped = true
 
-	err := client.conn.WriteMessage(websocket.CloseMessage,
-		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	err := client.conn.Close()
 	if err != nil {
-		slog.Error("failed to write close message", slog.Any("err", err))
+		slog.Error("failed to close connection", slog.Any("err", err))
 		return
 	}
-
-	select {
-	case <-time.After(time.Second):
-	}
 }
 
 // ErrRequestNotAcknowledged indicates that we did not receive a response for a
@@ -140,6 +198,13 @@ func (client *Client) SendCommand(cookerID CookerID, payload interface{}) (reque
 		return "", nil, errors.New(fmt.Sprintf("missing message type for payload %s", payloadType))
 	}
 
+	start := time.Now()
+	defer func() {
+		for _, observer := range client.commandObservers {
+			observer(messageType, time.Since(start), err)
+		}
+	}()
+
 	requestID = dto.RequestID(uuid.New().String())
 
 	command := dto.Command{
@@ -191,7 +256,7 @@ func (client *Client) SendCommand(cookerID CookerID, payload interface{}) (reque
 	{
 		defer client.connMutex.Unlock()
 
-		err = client.conn.WriteMessage(websocket.TextMessage, buf)
+		err = client.conn.WriteMessage(buf)
 		if err != nil {
 			slog.Error("failed to write message",
 				slog.Any("err", err),
@@ -226,13 +291,13 @@ func (client *Client) receiveMessages() {
 			return
 		}
 
-		_, message, err := client.conn.ReadMessage()
+		message, err := client.conn.ReadMessage()
 		if err != nil {
-			if errors.Is(err, websocket.ErrCloseSent) {
+			if errors.Is(err, errTransportClosed) {
 				return
 			}
 
-			slog.Error("failed to read from websocket",
+			slog.Error("failed to read from connection",
 				slog.Any("err", err))
 			client.Close()
 			return
@@ -243,11 +308,14 @@ func (client *Client) receiveMessages() {
 				slog.String("message", string(message)))
 		}
 
+		if client.frameInterceptor != nil && client.frameInterceptor(message) {
+			continue
+		}
+
 		// Decode the first layer of the message
-		dec := json.NewDecoder(bytes.NewReader(message))
-		dec.DisallowUnknownFields()
+		var unknownFields []string
 		var rawMessage dto.RawMessage
-		err = dec.Decode(&rawMessage)
+		err = client.decodeLayer(message, &rawMessage, "", &unknownFields)
 		if err != nil {
 			slog.Error("error parsing JSON",
 				slog.Any("err", err))
@@ -256,9 +324,11 @@ func (client *Client) receiveMessages() {
 
 		// Decode the payload of the message
 		if rawMessage.Command == "RESPONSE" {
+			client.reportUnknownFields(rawMessage.Command, unknownFields)
+
 			if rawMessage.RequestID == nil {
 				slog.Warn("received response with no request ID; ignoring")
-				break
+				continue
 			}
 
 			var payload map[string]interface{}
@@ -291,18 +361,15 @@ func (client *Client) receiveMessages() {
 			if !exists {
 				slog.Warn("received response for unknown request ID",
 					"requestID", requestID)
-				break
+				continue
 			}
 			ch <- payload
 
 			// Still send the response to any listeners.
 			client.inboundMessages <- decodedMessage
 		} else if messageType := dto.MessageTypeToResponseType[rawMessage.Command]; messageType != nil {
-			dec = json.NewDecoder(bytes.NewReader(rawMessage.Payload))
-			dec.DisallowUnknownFields()
-
 			decodedPayload := reflect.New(messageType).Interface()
-			err = dec.Decode(decodedPayload)
+			err = client.decodeLayer(rawMessage.Payload, decodedPayload, "payload", &unknownFields)
 			if err != nil {
 				slog.Error("error parsing payload JSON",
 					slog.Any("err", err),
@@ -311,6 +378,8 @@ func (client *Client) receiveMessages() {
 				continue
 			}
 
+			client.reportUnknownFields(rawMessage.Command, unknownFields)
+
 			decodedMessage := dto.Message{
 				RequestID: rawMessage.RequestID,
 				Command:   rawMessage.Command,
@@ -448,7 +517,34 @@ func (client *Client) SetLampPreference(cookerID CookerID, on bool) error {
 // probe.
 func (client *Client) SetProbe(cookerID CookerID, setpointCelsius float64) error {
 	command := dto.SetProbeCommand{
-		Setpoint: dto.NewTemperatureFromCelsius(setpointCelsius),
+		Setpoint: NewTemperatureCelsius(setpointCelsius).toDto(),
+	}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
+// SetTemperatureBulbs sends a command to adjust the setpoint of the running
+// stage's dry or wet bulb, depending on the mode.
+func (client *Client) SetTemperatureBulbs(cookerID CookerID, mode dto.TemperatureBulbsMode, setpointCelsius float64) error {
+	setting := &dto.TemperatureSetting{Setpoint: NewTemperatureCelsius(setpointCelsius).toDto()}
+	command := dto.SetTemperatureBulbsCommand{Mode: mode}
+	if mode == dto.TemperatureBulbsModeWet {
+		command.Wet = setting
+	} else {
+		command.Dry = setting
+	}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
+// SetSteamGenerators sends a command to adjust the running stage's steam, as a
+// relative humidity in wet mode or a steam percentage in dry mode.
+func (client *Client) SetSteamGenerators(cookerID CookerID, mode dto.SteamGeneratorMode, setpoint int) error {
+	command := dto.SetSteamGeneratorsCommand{Mode: mode}
+	if mode == dto.SteamGeneratorModeRelativeHumidity {
+		command.RelativeHumidity = &dto.SteamSetting{Setpoint: setpoint}
+	} else {
+		command.SteamPercentage = &dto.SteamSetting{Setpoint: setpoint}
 	}
 	_, _, err := client.SendCommand(cookerID, command)
 	return err
@@ -469,6 +565,74 @@ func (client *Client) DisconnectOvenFromAccount(cookerID CookerID) error {
 	return err
 }
 
+// HealthCheck sends a command that only succeeds if the oven is reachable.
+func (client *Client) HealthCheck(cookerID CookerID) error {
+	command := dto.HealthCheck{}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
+// RequestDiagnostics sends a diagnostic command to the oven and returns
+// whatever data it responds with. The supported commands are not documented.
+func (client *Client) RequestDiagnostics(cookerID CookerID, diagnosticCommand string) (data interface{}, err error) {
+	command := dto.RequestDiagnosticsCommand{
+		Command: diagnosticCommand,
+	}
+	_, response, err := client.SendCommand(cookerID, command)
+	if err != nil {
+		return nil, err
+	}
+	return response["data"], nil
+}
+
+// GetConfiguration sends a command that returns the oven's configuration. The
+// shape of the returned data is not documented.
+func (client *Client) GetConfiguration(cookerID CookerID) (data interface{}, err error) {
+	command := dto.GetConfiguration{}
+	_, response, err := client.SendCommand(cookerID, command)
+	if err != nil {
+		return nil, err
+	}
+	return response["data"], nil
+}
+
+// SetReportStateRate sends a command to change how often the oven sends its
+// state while cooking and while idle. The intervals are sent in whole seconds.
+func (client *Client) SetReportStateRate(cookerID CookerID, cooking time.Duration, idle time.Duration) error {
+	command := dto.SetReportStateRate{
+		Cooking: int(cooking.Seconds()),
+		Idle:    int(idle.Seconds()),
+	}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
+// SetReportStateRateDefault sends a command to restore the oven's default
+// state reporting intervals.
+func (client *Client) SetReportStateRateDefault(cookerID CookerID) error {
+	command := dto.SetReportStateRateDefault{}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
+// SetTimeZone sends a command to set the oven's time zone.
+func (client *Client) SetTimeZone(cookerID CookerID, timeZone dto.TimeZone) error {
+	command := dto.SetTimeZoneCommand{
+		TimeZone: timeZone,
+	}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
+// SetMetadata sends a command to attach arbitrary metadata to the oven.
+func (client *Client) SetMetadata(cookerID CookerID, metadata map[string]interface{}) error {
+	command := dto.SetMetadataCommand{
+		Metadata: metadata,
+	}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
 func (client *Client) SetName(cookerID CookerID, name string) error {
 	command := dto.NameWifiDeviceCommand{
 		Name: name,
@@ -527,7 +691,7 @@ func generateRandomCookUuid() string {
 	return cookIdPrefix + uuid.New().String()
 }
 
-func (client *Client) StartCook(cookerID CookerID, cookId string, stages []dto.CookingStage) error {
+func (client *Client) StartCook(cookerID CookerID, cookId string, stages []dto.OvenStage) error {
 	command := dto.StartCookCommandV1{
 		CookID: cookId,
 		Stages: stages,
@@ -556,3 +720,27 @@ func (client *Client) StopCook(cookerID CookerID) error {
 	_, _, err := client.SendCommand(cookerID, command)
 	return err
 }
+
+// StartDescale sends a command to start the descaling cycle. The oven must be
+// idle, with the door closed and descaling solution in the water tank.
+func (client *Client) StartDescale(cookerID CookerID) error {
+	command := dto.StartDescaleCommand{}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
+func (client *Client) AbortDescale(cookerID CookerID) error {
+	command := dto.AbortDescaleCommand{}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
+
+// StartFirmwareUpdate sends a command to download and install a firmware image.
+// Progress is reported through the oven state and firmware update events.
+func (client *Client) StartFirmwareUpdate(cookerID CookerID, downloadLink string) error {
+	command := dto.StartFirmwareUpdateCommand{
+		DownloadLink: downloadLink,
+	}
+	_, _, err := client.SendCommand(cookerID, command)
+	return err
+}
@@ -1,37 +1,90 @@
 package anova
 
-import "fmt"
+import (
+	"apocli/pkg/anova/dto"
+	"fmt"
+)
 
 type Cook struct {
 	Stages []*CookStage
+	// Shown on the oven and in the app for stages without a title of their own
+	Name string
+	// What the oven does once the last stage ends. Without one, the cook ends
+	// with the last stage.
+	End CookEnd
 
-	id   string
-	oven *Oven
+	id string
+	// Longest timer the stages may have
+	maxTimerSeconds int
+	oven            *Oven
 }
 
 func NewCook(stages ...*CookStage) (*Cook, error) {
-	for _, stage := range stages {
-		if err := (*stage).Validate(); err != nil {
-			return nil, ErrInvalidCookStage{Stage: stage, StageError: err}
-		}
-	}
+	return newCook(stages, maxTimerSeconds)
+}
+
+// NewLongCook is like NewCook, but accepts timers longer than the oven's limit
+// of 99 hours 59 minutes, of up to 60 days, e.g. for dehydrating or black
+// garlic. The oven runs such a stage as a chain of stages with the same
+// settings, one after another, so the cook carries on without apocli. Use
+// Progress to follow the chain as a single stage.
+func NewLongCook(stages ...*CookStage) (*Cook, error) {
+	return newCook(stages, maxLongCookTimerSeconds)
+}
 
-	return &Cook{
+func newCook(stages []*CookStage, maxTimerSeconds int) (*Cook, error) {
+	cook := &Cook{
 		Stages: stages,
 
-		id: generateRandomCookUuid(),
-	}, nil
+		id:              generateRandomCookUuid(),
+		maxTimerSeconds: maxTimerSeconds,
+	}
+	if err := cook.Validate(); err != nil {
+		return nil, err
+	}
+	return cook, nil
+}
+
+// Validate checks the cook's name, stages and end, and reports every rule they
+// break as an ErrValidation.
+func (cook *Cook) Validate() error {
+	return validate(func(validator *validator) {
+		for i, stage := range cook.Stages {
+			validator.checkStage(stage, fmt.Sprintf("stages[%d]", i), cook.maxTimerSeconds)
+		}
+		if cook.End != nil {
+			cook.End.check(validator, "end")
+		}
+	})
+}
+
+// ID returns the ID the cook is started with, as reported in the oven's state.
+func (cook *Cook) ID() string {
+	return cook.id
 }
 
 func (cook *Cook) Start(oven *Oven) error {
 	if cook.oven != nil {
 		return ErrCookAlreadyStarted{}
 	}
+	// The cook may have changed since it was created
+	if err := cook.Validate(); err != nil {
+		return err
+	}
 	cook.oven = oven
 	for _, stage := range cook.Stages {
 		stage.cook = cook
 	}
-	return oven.StartCook(cook.id, cook.Stages)
+	return oven.client.StartCook(oven.CookerID, cook.id, cook.toDto())
+}
+
+// toDto converts the cook's stages, followed by its end stage, if any.
+func (cook *Cook) toDto() []dto.OvenStage {
+	dtoStages := CookStages(cook.Stages).toOvenStages()
+	if cook.End != nil {
+		dtoStages = append(dtoStages, cook.End.toDto())
+	}
+	return dtoStages
 }
 
 //TODO fix; doesn't seem to do anything currently. maybe we need to use the set_* methods?
@@ -53,6 +106,11 @@ func (cook *Cook) Stop() error {
 	return err
 }
 
+// ErrInvalidCookStage is wrapped by the ErrValidation of a cook for each stage
+// that breaks a rule.
+//
+// Deprecated: Use ErrValidation, whose violations say which stage breaks which
+// rule.
 type ErrInvalidCookStage struct {
 	Stage      *CookStage
 	StageError error
@@ -62,6 +120,10 @@ func (err ErrInvalidCookStage) Error() string {
 	return fmt.Sprintf("invalid cook stage: %s (stage: %+v)", err.StageError, err.Stage)
 }
 
+func (err ErrInvalidCookStage) Unwrap() error {
+	return err.StageError
+}
+
 type ErrCookNotStarted struct{}
 
 func (err ErrCookNotStarted) Error() string {
@@ -0,0 +1,98 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"fmt"
+	"math"
+	"time"
+)
+
+// CookEnd is a stage added after the last stage of a cook, see Cook.End.
+type CookEnd interface {
+	Validatable
+
+	check(validator *validator, path string)
+	toDto() dto.OvenStage
+}
+
+// Stop ends the cook with a stop stage, which turns the heating off. With a
+// timer, the oven shows the stage until the timer runs out, e.g. while food
+// rests in the oven; without one, the cook ends once the stage is reached.
+type Stop struct {
+	Timer time.Duration
+
+	stageId string
+}
+
+func NewStop(timer time.Duration) *Stop {
+	return &Stop{
+		Timer:   timer,
+		stageId: generateRandomCookUuid(),
+	}
+}
+
+func (stop *Stop) Validate() error {
+	return validate(func(validator *validator) { stop.check(validator, "end") })
+}
+
+func (stop *Stop) check(validator *validator, path string) {
+	seconds := math.Round(stop.Timer.Seconds())
+	validator.check(seconds >= 0 && seconds <= maxTimerSeconds,
+		fieldPath(path, "stop.timer"), stop.Timer, fmt.Sprintf("0s to %s", maxTimerSeconds*time.Second),
+		ErrInvalidTimerDuration{})
+}
+
+func (stop *Stop) toDto() dto.OvenStage {
+	stage := dto.StopStage{
+		ID:   stop.stageId,
+		Type: dto.StageTypeStop,
+	}
+	if seconds := int(math.Round(stop.Timer.Seconds())); seconds > 0 {
+		stage.Timer = &dto.StageTimer{Initial: seconds}
+	}
+	return stage
+}
+
+// KeepWarm ends the cook with a stage that holds the food at a temperature,
+// e.g. 65 °C, until the cook is stopped. It starts as soon as the last stage
+// ends, without waiting for the oven to cool down to the setpoint. In wet mode,
+// the relative humidity is kept at 100%.
+type KeepWarm struct {
+	Setpoint        TemperatureSetpoint
+	HeatingElements HeatingElements
+
+	stageId string
+}
+
+func NewKeepWarm(setpoint TemperatureSetpoint, heatingElements HeatingElements) *KeepWarm {
+	return &KeepWarm{
+		Setpoint:        setpoint,
+		HeatingElements: heatingElements,
+		stageId:         generateRandomCookUuid(),
+	}
+}
+
+func (keepWarm *KeepWarm) Validate() error {
+	return validate(func(validator *validator) { keepWarm.check(validator, "end") })
+}
+
+func (keepWarm *KeepWarm) check(validator *validator, path string) {
+	keepWarm.cookStage().check(validator, fieldPath(path, "keepWarm"), 0)
+}
+
+func (keepWarm *KeepWarm) toDto() dto.OvenStage {
+	return keepWarm.cookStage().toDto()[0]
+}
+
+func (keepWarm *KeepWarm) cookStage() *CookStage {
+	return &CookStage{
+		RackPosition:        RackPositionMiddle,
+		FanSpeed:            FanSpeedHigh,
+		TemperatureSetpoint: keepWarm.Setpoint,
+		HeatingElements:     keepWarm.HeatingElements,
+		Title:               "Keep warm",
+
+		cookStageId:    keepWarm.stageId,
+		withoutPreheat: true,
+	}
+}
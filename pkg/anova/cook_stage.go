@@ -2,8 +2,10 @@ package anova
 
 import (
 	"apocli/pkg/anova/dto"
+	"fmt"
 	"log/slog"
 	"math"
+	"strings"
 	"time"
 )
 
@@ -22,6 +24,12 @@ const (
 	maxTempDryBulbBottomHeatingCelsius = 180
 
 	maxTimerSeconds = 359940 // 99 hours 59 minutes
+
+	// Relative humidity of wet stages without a steam percentage, as in the app
+	defaultRelativeHumidity SteamPercentage = 100
+
+	// Long cooks chain stages to run for longer than maxTimerSeconds
+	maxLongCookTimerSeconds = 60 * 24 * 60 * 60 // 60 days
 )
 
 type Validatable interface {
@@ -32,6 +40,9 @@ type StageEndCondition interface {
 	Validatable
 
 	isStageEndCondition()
+	// check validates the condition as the end of a stage that allows timers
+	// of up to maxTimerSeconds
+	check(validator *validator, path string, maxTimerSeconds int)
 }
 
 type TimerTrigger string
@@ -43,10 +54,12 @@ const (
 )
 
 func (trigger TimerTrigger) Validate() error {
-	if trigger != TimerTriggerImmediately && trigger != TimerTriggerWhenPreheated && trigger != TimerTriggerManually {
-		return ErrInvalidTimerTrigger{}
-	}
-	return nil
+	return validate(func(validator *validator) { trigger.check(validator, "trigger") })
+}
+
+func (trigger TimerTrigger) check(validator *validator, path string) {
+	validator.check(trigger == TimerTriggerImmediately || trigger == TimerTriggerWhenPreheated || trigger == TimerTriggerManually,
+		path, trigger, "immediately, preheat or manually", ErrInvalidTimerTrigger{})
 }
 
 type Timer struct {
@@ -64,14 +77,26 @@ func NewTimer(duration time.Duration, trigger TimerTrigger) *Timer {
 func (timer *Timer) isStageEndCondition() {}
 
 func (timer *Timer) Validate() error {
-	if err := timer.Trigger.Validate(); err != nil {
-		return err
-	}
+	return validate(func(validator *validator) { timer.check(validator, "timer", maxTimerSeconds) })
+}
+
+func (timer *Timer) check(validator *validator, path string, maxSeconds int) {
+	timer.Trigger.check(validator, fieldPath(path, "trigger"))
 	// See StageAdapter.isValidTimerValue
-	if timer.DurationSeconds < 0 || timer.DurationSeconds > maxTimerSeconds {
-		return ErrInvalidTimerDuration{}
+	validator.check(timer.DurationSeconds >= 0 && timer.DurationSeconds <= maxSeconds,
+		fieldPath(path, "duration"), time.Duration(timer.DurationSeconds)*time.Second,
+		fmt.Sprintf("0s to %s", time.Duration(maxSeconds)*time.Second), ErrInvalidTimerDuration{})
+}
+
+// segments splits the timer into durations the oven accepts, for long cooks.
+func (timer *Timer) segments() []int {
+	segments := []int{}
+	remaining := timer.DurationSeconds
+	for remaining > maxTimerSeconds {
+		segments = append(segments, maxTimerSeconds)
+		remaining -= maxTimerSeconds
 	}
-	return nil
+	return append(segments, remaining)
 }
 
 type Probe struct {
@@ -82,18 +107,27 @@ func NewProbeCelsius(temperature float64) *Probe {
 	return &Probe{TemperatureCelsius: temperature}
 }
 
+// NewProbeFahrenheit converts the temperature exactly; it's rounded to what the
+// oven accepts when the cook starts.
 func NewProbeFahrenheit(temperature float64) *Probe {
-	return NewProbeCelsius(dto.FahrenheitToCelsius(temperature))
+	return NewProbe(NewTemperatureFahrenheit(temperature))
+}
+
+func NewProbe(temperature Temperature) *Probe {
+	return NewProbeCelsius(temperature.Celsius())
 }
 
 func (probe *Probe) isStageEndCondition() {}
 
 func (probe *Probe) Validate() error {
+	return validate(func(validator *validator) { probe.check(validator, "probe", 0) })
+}
+
+func (probe *Probe) check(validator *validator, path string, _ int) {
 	// See StageAdapter.isValidTargetProbeTemperatureValue
-	if probe.TemperatureCelsius < minTempProbeCelsius || probe.TemperatureCelsius > maxTempProbeCelsius {
-		return ErrInvalidProbeTemperature{}
-	}
-	return nil
+	validator.check(probe.TemperatureCelsius >= minTempProbeCelsius && probe.TemperatureCelsius <= maxTempProbeCelsius,
+		fieldPath(path, "temperatureCelsius"), formatCelsius(probe.TemperatureCelsius),
+		formatCelsiusRange(minTempProbeCelsius, maxTempProbeCelsius), ErrInvalidProbeTemperature{})
 }
 
 type HeatingElements struct {
@@ -115,12 +149,36 @@ var (
 )
 
 func (heatingElements HeatingElements) Validate() error {
+	return validate(func(validator *validator) { heatingElements.check(validator, "heatingElements") })
+}
+
+func (heatingElements HeatingElements) check(validator *validator, path string) {
 	// Valid combos: top, rear, bottom, top + rear, top + bottom
-	if (!heatingElements.Top && !heatingElements.Rear && !heatingElements.Bottom) ||
-		(heatingElements.Rear && heatingElements.Bottom) {
-		return ErrInvalidHeatingElementCombination{}
+	validator.check((heatingElements.Top || heatingElements.Rear || heatingElements.Bottom) &&
+		!(heatingElements.Rear && heatingElements.Bottom),
+		path, heatingElements, "top, rear, bottom, top and rear, or top and bottom", ErrInvalidHeatingElementCombination{})
+}
+
+// BottomOnly returns whether only the bottom heating element is on, which
+// limits the dry bulb setpoint.
+func (heatingElements HeatingElements) BottomOnly() bool {
+	return heatingElements.Bottom && !heatingElements.Top && !heatingElements.Rear
+}
+
+func (heatingElements HeatingElements) String() string {
+	var elements []string
+	for _, element := range []struct {
+		on   bool
+		name string
+	}{{heatingElements.Top, "top"}, {heatingElements.Rear, "rear"}, {heatingElements.Bottom, "bottom"}} {
+		if element.on {
+			elements = append(elements, element.name)
+		}
 	}
-	return nil
+	if len(elements) == 0 {
+		return "none"
+	}
+	return strings.Join(elements, " and ")
 }
 
 type RackPosition int
@@ -132,10 +190,12 @@ const (
 )
 
 func (position RackPosition) Validate() error {
-	if position < RackPositionLow || position > RackPositionHigh {
-		return ErrInvalidRackPosition{}
-	}
-	return nil
+	return validate(func(validator *validator) { position.check(validator, "rackPosition") })
+}
+
+func (position RackPosition) check(validator *validator, path string) {
+	validator.check(position >= RackPositionLow && position <= RackPositionHigh,
+		path, int(position), fmt.Sprintf("%d to %d", RackPositionLow, RackPositionHigh), ErrInvalidRackPosition{})
 }
 
 type FanSpeed int
@@ -152,10 +212,12 @@ const (
 )
 
 func (speed FanSpeed) Validate() error {
-	if speed < FanSpeedOff || speed > FanSpeedHigh {
-		return ErrInvalidFanSpeed{}
-	}
-	return nil
+	return validate(func(validator *validator) { speed.check(validator, "fanSpeed") })
+}
+
+func (speed FanSpeed) check(validator *validator, path string) {
+	validator.check(speed >= FanSpeedOff && speed <= FanSpeedHigh,
+		path, int(speed), fmt.Sprintf("%d to %d", FanSpeedOff, FanSpeedHigh), ErrInvalidFanSpeed{})
 }
 
 type SteamPercentage float64
@@ -170,10 +232,12 @@ var (
 )
 
 func (percentage SteamPercentage) Validate() error {
-	if percentage < 0 || percentage > 100 {
-		return ErrInvalidSteamPercentage{}
-	}
-	return nil
+	return validate(func(validator *validator) { percentage.check(validator, "steamPercentage") })
+}
+
+func (percentage SteamPercentage) check(validator *validator, path string) {
+	validator.check(percentage >= 0 && percentage <= 100,
+		path, fmt.Sprintf("%g%%", float64(percentage)), "0 to 100%", ErrInvalidSteamPercentage{})
 }
 
 type TemperatureMode string
@@ -184,10 +248,12 @@ const (
 )
 
 func (mode TemperatureMode) Validate() error {
-	if mode != TemperatureModeDry && mode != TemperatureModeWet {
-		return ErrInvalidTemperatureMode{}
-	}
-	return nil
+	return validate(func(validator *validator) { mode.check(validator, "mode") })
+}
+
+func (mode TemperatureMode) check(validator *validator, path string) {
+	validator.check(mode == TemperatureModeDry || mode == TemperatureModeWet,
+		path, fmt.Sprintf("%q", mode), "dry or wet", ErrInvalidTemperatureMode{})
 }
 
 type TemperatureSetpoint struct {
@@ -202,6 +268,10 @@ func NewSetpoint(temperatureCelsius float64, mode TemperatureMode) TemperatureSe
 	}
 }
 
+func NewSetpointTemperature(temperature Temperature, mode TemperatureMode) TemperatureSetpoint {
+	return NewSetpoint(temperature.Celsius(), mode)
+}
+
 func NewSousVideSetpointCelsius(temperatureCelsius float64) TemperatureSetpoint {
 	return NewSetpoint(temperatureCelsius, TemperatureModeWet)
 }
@@ -210,15 +280,58 @@ func NewNonSousVideSetpointCelsius(temperatureCelsius float64) TemperatureSetpoi
 	return NewSetpoint(temperatureCelsius, TemperatureModeDry)
 }
 
+// Validate checks the setpoint against the range of its mode. Stages also
+// check it against the lower range with only the bottom heating element on.
 func (setpoint TemperatureSetpoint) Validate() error {
-	if err := setpoint.Mode.Validate(); err != nil {
-		return err
+	return validate(func(validator *validator) { setpoint.check(validator, "", RearElementOnly) })
+}
+
+func (setpoint TemperatureSetpoint) check(validator *validator, path string, heatingElements HeatingElements) {
+	setpoint.Mode.check(validator, fieldPath(path, "mode"))
+	if setpoint.Mode != TemperatureModeDry && setpoint.Mode != TemperatureModeWet {
+		return
 	}
-	if (setpoint.Mode == TemperatureModeDry && (setpoint.TemperatureCelsius < minTempDryBulbCelsius || setpoint.TemperatureCelsius > maxTempDryBulbCelsius)) ||
-		(setpoint.Mode == TemperatureModeWet && (setpoint.TemperatureCelsius < minTempWetBulbCelsius || setpoint.TemperatureCelsius > maxTempWetBulbCelsius)) {
-		return ErrInvalidTemperatureSetpoint{}
+	minCelsius, maxCelsius, allowed := setpointRange(setpoint.Mode, heatingElements)
+	validator.check(setpoint.TemperatureCelsius >= minCelsius && setpoint.TemperatureCelsius <= maxCelsius,
+		fieldPath(path, "temperatureCelsius"), formatCelsius(setpoint.TemperatureCelsius), allowed, ErrInvalidTemperatureSetpoint{})
+}
+
+// Clamp limits the setpoint to the range the oven accepts for its mode, which
+// is lower in dry mode when only the bottom heating element is on.
+func (setpoint TemperatureSetpoint) Clamp(heatingElements HeatingElements) TemperatureSetpoint {
+	minCelsius, maxCelsius, _ := setpointRange(setpoint.Mode, heatingElements)
+	setpoint.TemperatureCelsius = math.Max(minCelsius, math.Min(maxCelsius, setpoint.TemperatureCelsius))
+	return setpoint
+}
+
+// SetpointRangeCelsius returns the lowest and highest setpoints the oven
+// accepts in the mode with the heating elements.
+func SetpointRangeCelsius(mode TemperatureMode, heatingElements HeatingElements) (float64, float64) {
+	minCelsius, maxCelsius, _ := setpointRange(mode, heatingElements)
+	return minCelsius, maxCelsius
+}
+
+// setpointRange returns the setpoints the oven accepts, and describes them.
+func setpointRange(mode TemperatureMode, heatingElements HeatingElements) (float64, float64, string) {
+	switch {
+	case mode == TemperatureModeWet:
+		return minTempWetBulbCelsius, maxTempWetBulbCelsius,
+			formatCelsiusRange(minTempWetBulbCelsius, maxTempWetBulbCelsius) + " in wet mode"
+	case heatingElements.BottomOnly():
+		return minTempDryBulbBottomHeatingCelsius, maxTempDryBulbBottomHeatingCelsius,
+			formatCelsiusRange(minTempDryBulbBottomHeatingCelsius, maxTempDryBulbBottomHeatingCelsius) +
+				" in dry mode with only the bottom heating element on"
 	}
-	return nil
+	return minTempDryBulbCelsius, maxTempDryBulbCelsius,
+		formatCelsiusRange(minTempDryBulbCelsius, maxTempDryBulbCelsius) + " in dry mode"
+}
+
+func formatCelsius(celsius float64) string {
+	return fmt.Sprintf("%g °C", celsius)
+}
+
+func formatCelsiusRange(minCelsius float64, maxCelsius float64) string {
+	return fmt.Sprintf("%g to %g °C", minCelsius, maxCelsius)
 }
 
 type CookStage struct {
@@ -229,10 +342,27 @@ type CookStage struct {
 	SteamPercentage     *SteamPercentage
 	Terminator          StageEndCondition
 
+	// Shown on the oven and in the app. Stages without a title show the cook's
+	// name instead.
+	Title       string
+	Description string
+	// Optional URLs of media shown in the app
+	PhotoURL          string
+	VideoURL          string
+	VideoThumbnailURL string
+
+	// Open the vent, e.g. to let moisture out for a crisp finish
+	VentOpen bool
+
 	//TODO support transition settings between stages (currently, we always do automatic transitions)
 
 	preheatStageId string
 	cookStageId    string
+	// IDs of the stages that continue the cook stage's timer, when it is longer
+	// than the oven allows. Generated as needed.
+	continuationStageIds []string
+	// Set for stages that should start right away, even with no timer
+	withoutPreheat bool
 	cook           *Cook
 }
 
@@ -253,36 +383,36 @@ func NewCookStage(rackPosition RackPosition, fanSpeed FanSpeed, temperatureSetpo
 }
 
 func (stage *CookStage) Validate() error {
+	return stage.validateAt("", maxTimerSeconds)
+}
+
+// validateAt validates the stage at the path in a cook, allowing timers of up
+// to maxTimerSeconds.
+func (stage *CookStage) validateAt(path string, maxTimerSeconds int) error {
+	return validate(func(validator *validator) { stage.check(validator, path, maxTimerSeconds) })
+}
+
+// check validates the stage, allowing timers of up to maxTimerSeconds.
+func (stage *CookStage) check(validator *validator, path string, maxTimerSeconds int) {
 	// Validate individual fields
-	if err := stage.RackPosition.Validate(); err != nil {
-		return err
-	}
-	if err := stage.FanSpeed.Validate(); err != nil {
-		return err
-	}
-	if err := stage.TemperatureSetpoint.Validate(); err != nil {
-		return err
-	}
-	if err := stage.HeatingElements.Validate(); err != nil {
-		return err
-	}
+	stage.RackPosition.check(validator, fieldPath(path, "rackPosition"))
+	stage.FanSpeed.check(validator, fieldPath(path, "fanSpeed"))
+	stage.TemperatureSetpoint.check(validator, path, stage.HeatingElements)
+	stage.HeatingElements.check(validator, fieldPath(path, "heatingElements"))
 	if stage.SteamPercentage != nil {
-		if err := (*stage.SteamPercentage).Validate(); err != nil {
-			return err
-		}
+		stage.SteamPercentage.check(validator, fieldPath(path, "steamPercentage"))
 	}
-	if stage.Terminator != nil {
-		if err := stage.Terminator.Validate(); err != nil {
-			return err
-		}
+	switch terminator := stage.Terminator.(type) {
+	case *Timer:
+		terminator.check(validator, fieldPath(path, "timer"), maxTimerSeconds)
+	case *Probe:
+		terminator.check(validator, fieldPath(path, "probe"), maxTimerSeconds)
 	}
 
 	// Validate combinations of fields
-	if stage.FanSpeed != FanSpeedHigh && (stage.HeatingElements.Rear || stage.SteamPercentage != nil) {
-		return ErrInvalidFanSpeed{}
-	}
-
-	return nil
+	validator.check(stage.FanSpeed == FanSpeedHigh || !(stage.HeatingElements.Rear || stage.SteamPercentage != nil),
+		fieldPath(path, "fanSpeed"), int(stage.FanSpeed),
+		fmt.Sprintf("%d with the rear heating element or steam on", FanSpeedHigh), ErrInvalidFanSpeed{})
 }
 
 //TODO fix; doesn't seem to do anything currently
@@ -311,9 +441,33 @@ func stageRackPositionToPtr(value dto.StageRackPosition) *dto.StageRackPosition
 	return &v
 }
 
-// toDto converts a stage into one or more DTO stages. This is necessary to support timers
+// stringToPtr returns nil for empty strings, so that they are left out.
+func stringToPtr(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// StageIDs returns the IDs of the DTO stages the stage is converted into, in
+// order: the preheat stage, if any, the cook stage, and the stages continuing a
+// long cook's timer.
+func (stage *CookStage) StageIDs() []string {
+	dtoStages := stage.toDto()
+	ids := make([]string, 0, len(dtoStages))
+	for _, dtoStage := range dtoStages {
+		ids = append(ids, dtoStage.ID)
+	}
+	return ids
+}
+
+// toDto converts a stage into one or more DTO stages. This is necessary to support timers,
+// and timers longer than the oven allows, which continue in further stages
 func (stage *CookStage) toDto() []dto.CookingStage {
-	description := "desc"
+	title := stage.Title
+	if title == "" && stage.cook != nil {
+		title = stage.cook.Name
+	}
 	cookStage := dto.CookingStage{
 		StepType: "stage",
 		ID:       stage.cookStageId,
@@ -331,16 +485,19 @@ func (stage *CookStage) toDto() []dto.CookingStage {
 			Mode: dto.TemperatureBulbsMode(stage.TemperatureSetpoint.Mode),
 			// Rest populated below
 		},
-		//TODO when is this ever not false?
-		Vent: &dto.StageVent{Open: false},
+		Vent: &dto.StageVent{Open: stage.VentOpen},
 
 		RackPosition: stageRackPositionToPtr(dto.StageRackPosition(stage.RackPosition)),
 
-		Title:       dto.NewTitle("foo bar"),
-		Description: &description,
+		Title:       dto.NewTitle(title),
+		Description: stringToPtr(stage.Description),
+
+		PhotoUrl:          stringToPtr(stage.PhotoURL),
+		VideoUrl:          stringToPtr(stage.VideoURL),
+		VideoThumbnailUrl: stringToPtr(stage.VideoThumbnailURL),
 	}
 
-	setpointTemperature := dto.NewTemperatureFromCelsius(stage.TemperatureSetpoint.TemperatureCelsius)
+	setpointTemperature := NewTemperatureCelsius(stage.TemperatureSetpoint.TemperatureCelsius).toDto()
 	switch stage.TemperatureSetpoint.Mode {
 	case TemperatureModeDry:
 		cookStage.TemperatureBulbs.Dry = &dto.TemperatureSetting{Setpoint: setpointTemperature}
@@ -356,20 +513,25 @@ func (stage *CookStage) toDto() []dto.CookingStage {
 	case TemperatureModeWet:
 		cookStage.TemperatureBulbs.Wet = &dto.TemperatureSetting{Setpoint: setpointTemperature}
 
+		// The app always sets a relative humidity in wet (sous vide) mode, and
+		// defaults to 100%
+		relativeHumidity := defaultRelativeHumidity
 		if stage.SteamPercentage != nil {
-			cookStage.SteamGenerators = &dto.StageSteamGenerators{
-				Mode: dto.SteamGeneratorModeRelativeHumidity,
-				RelativeHumidity: &dto.SteamSetting{
-					Setpoint: int(*stage.SteamPercentage),
-				},
-			}
+			relativeHumidity = *stage.SteamPercentage
+		}
+		cookStage.SteamGenerators = &dto.StageSteamGenerators{
+			Mode: dto.SteamGeneratorModeRelativeHumidity,
+			RelativeHumidity: &dto.SteamSetting{
+				Setpoint: int(relativeHumidity),
+			},
 		}
 	default:
 		slog.Error("unknown temperature setpoint mode",
 			slog.String("mode", string(stage.TemperatureSetpoint.Mode)))
 	}
 
-	needsPreheat := true
+	needsPreheat := !stage.withoutPreheat
+	var timerSegments []int
 	if stage.Terminator != nil {
 		switch terminator := stage.Terminator.(type) {
 		case *Timer:
@@ -379,27 +541,40 @@ func (stage *CookStage) toDto() []dto.CookingStage {
 				cookStage.UserActionRequired = true
 			}
 
+			timerSegments = terminator.segments()
 			cookStage.TimerAdded = timerAdded
-			cookStage.Timer = &dto.StageTimer{Initial: terminator.DurationSeconds}
+			cookStage.Timer = &dto.StageTimer{Initial: timerSegments[0]}
 
 		case *Probe:
 			cookStage.ProbeAdded = probeAdded
 			cookStage.TemperatureProbe = &dto.StageTemperatureProbe{
-				Setpoint: dto.NewTemperatureFromCelsius(terminator.TemperatureCelsius),
+				Setpoint: NewTemperatureCelsius(terminator.TemperatureCelsius).toDto(),
 			}
 		}
 	}
 
-	if !needsPreheat {
-		return []dto.CookingStage{cookStage}
+	dtoStages := []dto.CookingStage{cookStage}
+	if needsPreheat {
+		preheatStage := cookStage
+		preheatStage.ID = stage.preheatStageId
+		preheatStage.Type = dto.StageTypePreheat
+		preheatStage.UserActionRequired = false
+		dtoStages = []dto.CookingStage{preheatStage, cookStage}
 	}
 
-	preheatStage := cookStage
-	preheatStage.ID = stage.preheatStageId
-	preheatStage.Type = dto.StageTypePreheat
-	preheatStage.UserActionRequired = false
-
-	return []dto.CookingStage{preheatStage, cookStage}
+	// Continue long timers in further stages, which start as soon as the
+	// previous one ends
+	for i, seconds := range timerSegments[min(len(timerSegments), 1):] {
+		if i >= len(stage.continuationStageIds) {
+			stage.continuationStageIds = append(stage.continuationStageIds, generateRandomCookUuid())
+		}
+		continuationStage := cookStage
+		continuationStage.ID = stage.continuationStageIds[i]
+		continuationStage.UserActionRequired = false
+		continuationStage.Timer = &dto.StageTimer{Initial: seconds}
+		dtoStages = append(dtoStages, continuationStage)
+	}
+	return dtoStages
 }
 
 type CookStages []*CookStage
@@ -412,6 +587,14 @@ func (stages CookStages) ToDto() []dto.CookingStage {
 	return dtoStages
 }
 
+func (stages CookStages) toOvenStages() []dto.OvenStage {
+	dtoStages := make([]dto.OvenStage, 0)
+	for _, stage := range stages.ToDto() {
+		dtoStages = append(dtoStages, stage)
+	}
+	return dtoStages
+}
+
 type ErrNotAssociatedWithCook struct{}
 
 func (err ErrNotAssociatedWithCook) Error() string {
@@ -0,0 +1,273 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// Daemon shares one upstream Client with any number of local clients connected
+// through OptionDaemonSocket. Commands from local clients are relayed upstream
+// as-is, their responses are routed back to the sender, and events are sent to
+// every local client.
+//
+// The backend only sends the oven list, user state and oven states right after
+// connecting, so the daemon keeps the latest of each and replays them to every
+// new local client.
+type Daemon struct {
+	client *Client
+
+	mutex    sync.Mutex
+	listener net.Listener
+	conns    map[*daemonConn]struct{}
+	// Local clients waiting for a response, by request ID
+	pendingRequests map[dto.RequestID]pendingRequest
+
+	wifiList   []byte
+	userState  []byte
+	ovenStates map[CookerID][]byte
+	// Cooker IDs in the order they were first seen, so replays are stable
+	ovenOrder []CookerID
+}
+
+type daemonConn struct {
+	transport *socketTransport
+}
+
+type pendingRequest struct {
+	conn   *daemonConn
+	sentAt time.Time
+}
+
+func NewDaemon() *Daemon {
+	return &Daemon{
+		conns:           make(map[*daemonConn]struct{}),
+		pendingRequests: make(map[dto.RequestID]pendingRequest),
+		ovenStates:      make(map[CookerID][]byte),
+	}
+}
+
+// OptionDaemon relays the client's connection through the daemon. The client
+// must still be read from, e.g. by a Service, as events continue to be
+// delivered to it.
+func OptionDaemon(daemon *Daemon) func(*Client) error {
+	return func(client *Client) error {
+		if client.daemonSocketPath != "" {
+			return errors.New("a daemon cannot connect through another daemon")
+		}
+		daemon.client = client
+		client.frameInterceptor = daemon.handleUpstreamFrame
+		return nil
+	}
+}
+
+// Serve accepts local clients until the listener or daemon is closed.
+func (daemon *Daemon) Serve(listener net.Listener) error {
+	if daemon.client == nil {
+		return errors.New("daemon has no client; use OptionDaemon")
+	}
+
+	daemon.mutex.Lock()
+	daemon.listener = listener
+	daemon.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		go daemon.serveConn(&daemonConn{transport: newSocketTransport(conn)})
+	}
+}
+
+// Close stops accepting local clients and disconnects the existing ones. The
+// upstream client is left open.
+func (daemon *Daemon) Close() {
+	daemon.mutex.Lock()
+	defer daemon.mutex.Unlock()
+
+	if daemon.listener != nil {
+		_ = daemon.listener.Close()
+	}
+	for conn := range daemon.conns {
+		_ = conn.transport.Close()
+	}
+}
+
+func (daemon *Daemon) serveConn(conn *daemonConn) {
+	daemon.mutex.Lock()
+	// Replay while holding the lock, so no events can be sent in between
+	for _, frame := range daemon.snapshot() {
+		if !daemon.send(conn, frame) {
+			daemon.mutex.Unlock()
+			return
+		}
+	}
+	daemon.conns[conn] = struct{}{}
+	daemon.mutex.Unlock()
+
+	slog.Debug("daemon client connected")
+	defer func() {
+		daemon.mutex.Lock()
+		delete(daemon.conns, conn)
+		for requestID, request := range daemon.pendingRequests {
+			if request.conn == conn {
+				delete(daemon.pendingRequests, requestID)
+			}
+		}
+		daemon.mutex.Unlock()
+
+		_ = conn.transport.Close()
+		slog.Debug("daemon client disconnected")
+	}()
+
+	for {
+		frame, err := conn.transport.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var message dto.RawMessage
+		err = json.Unmarshal(frame, &message)
+		if err != nil || message.RequestID == nil {
+			slog.Warn("daemon client sent an invalid command; ignoring", slog.Any("err", err))
+			continue
+		}
+
+		daemon.mutex.Lock()
+		daemon.prunePendingRequests()
+		daemon.pendingRequests[*message.RequestID] = pendingRequest{conn: conn, sentAt: time.Now()}
+		daemon.mutex.Unlock()
+
+		daemon.client.connMutex.Lock()
+		err = daemon.client.conn.WriteMessage(frame)
+		daemon.client.connMutex.Unlock()
+		if err != nil {
+			slog.Error("failed to relay command upstream", slog.Any("err", err))
+		}
+	}
+}
+
+// prunePendingRequests forgets requests that will never be acknowledged. The
+// mutex must be held.
+func (daemon *Daemon) prunePendingRequests() {
+	for requestID, request := range daemon.pendingRequests {
+		if time.Since(request.sentAt) > 2*requestAcknowledgementTimeout {
+			delete(daemon.pendingRequests, requestID)
+		}
+	}
+}
+
+// snapshot returns the frames to replay to a new local client. The mutex must
+// be held.
+func (daemon *Daemon) snapshot() [][]byte {
+	var frames [][]byte
+	if daemon.wifiList != nil {
+		frames = append(frames, daemon.wifiList)
+	}
+	if daemon.userState != nil {
+		frames = append(frames, daemon.userState)
+	}
+	for _, cookerID := range daemon.ovenOrder {
+		frames = append(frames, daemon.ovenStates[cookerID])
+	}
+	return frames
+}
+
+// handleUpstreamFrame is the upstream client's frame interceptor. It consumes
+// responses to relayed commands, and lets everything else through to the client.
+func (daemon *Daemon) handleUpstreamFrame(frame []byte) bool {
+	var message dto.RawMessage
+	err := json.Unmarshal(frame, &message)
+	if err != nil {
+		return false
+	}
+
+	daemon.mutex.Lock()
+	defer daemon.mutex.Unlock()
+
+	if message.Command == "RESPONSE" {
+		if message.RequestID == nil {
+			return false
+		}
+		request, exists := daemon.pendingRequests[*message.RequestID]
+		if !exists {
+			// One of the upstream client's own requests
+			return false
+		}
+		delete(daemon.pendingRequests, *message.RequestID)
+		daemon.send(request.conn, frame)
+		return true
+	}
+
+	daemon.cache(message, frame)
+	for conn := range daemon.conns {
+		daemon.send(conn, frame)
+	}
+	return false
+}
+
+// cache keeps the latest of each snapshot event. The mutex must be held.
+func (daemon *Daemon) cache(message dto.RawMessage, frame []byte) {
+	switch message.Command {
+	case "EVENT_APO_WIFI_LIST":
+		daemon.wifiList = frame
+
+		// Forget the state of ovens that have been removed
+		var ovens []struct {
+			CookerID CookerID `json:"cookerId"`
+		}
+		if json.Unmarshal(message.Payload, &ovens) != nil {
+			return
+		}
+		listed := make(map[CookerID]bool)
+		for _, oven := range ovens {
+			listed[oven.CookerID] = true
+		}
+		ovenOrder := daemon.ovenOrder[:0]
+		for _, cookerID := range daemon.ovenOrder {
+			if listed[cookerID] {
+				ovenOrder = append(ovenOrder, cookerID)
+			} else {
+				delete(daemon.ovenStates, cookerID)
+			}
+		}
+		daemon.ovenOrder = ovenOrder
+
+	case "EVENT_USER_STATE":
+		daemon.userState = frame
+
+	case "EVENT_APO_STATE":
+		var state struct {
+			CookerID CookerID `json:"cookerId"`
+		}
+		if json.Unmarshal(message.Payload, &state) != nil || state.CookerID == "" {
+			return
+		}
+		if _, exists := daemon.ovenStates[state.CookerID]; !exists {
+			daemon.ovenOrder = append(daemon.ovenOrder, state.CookerID)
+		}
+		daemon.ovenStates[state.CookerID] = frame
+	}
+}
+
+// send writes a frame to a local client, disconnecting it on failure so a stuck
+// client can't hold up the others for long.
+func (daemon *Daemon) send(conn *daemonConn, frame []byte) bool {
+	_ = conn.transport.conn.SetWriteDeadline(time.Now().Add(time.Second))
+	err := conn.transport.WriteMessage(frame)
+	if err != nil {
+		slog.Warn("failed to send to daemon client; disconnecting", slog.Any("err", err))
+		_ = conn.transport.Close()
+		return false
+	}
+	return true
+}
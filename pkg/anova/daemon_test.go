@@ -0,0 +1,103 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeUpstream stands in for the backend's WebSocket.
+type fakeUpstream struct {
+	inbound  chan []byte
+	outbound chan []byte
+}
+
+func newFakeUpstream() *fakeUpstream {
+	return &fakeUpstream{
+		inbound:  make(chan []byte, 10),
+		outbound: make(chan []byte, 10),
+	}
+}
+
+func (upstream *fakeUpstream) ReadMessage() ([]byte, error) {
+	frame, ok := <-upstream.inbound
+	if !ok {
+		return nil, errTransportClosed
+	}
+	return frame, nil
+}
+
+func (upstream *fakeUpstream) WriteMessage(frame []byte) error {
+	upstream.outbound <- frame
+	return nil
+}
+
+func (upstream *fakeUpstream) Close() error {
+	return nil
+}
+
+func TestDaemon(t *testing.T) {
+	upstream := newFakeUpstream()
+	upstreamClient := &Client{
+		conn: upstream,
+
+		stop: make(chan bool),
+
+		inboundMessages:  make(chan dto.Message, 100),
+		requestResponses: make(map[dto.RequestID]chan map[string]interface{}),
+	}
+	daemon := NewDaemon()
+	err := OptionDaemon(daemon)(upstreamClient)
+	if err != nil {
+		t.Fatalf("failed to attach daemon: %+v", err)
+	}
+	go upstreamClient.receiveMessages()
+
+	// Sent before any local client connects, so it must be replayed
+	upstream.inbound <- []byte(`{"command":"EVENT_APO_WIFI_LIST","payload":[{"cookerId":"0123456789abcdef","name":"Kitchen","pairedAt":"2024-01-01T00:00:00Z","type":"oven_v2"}]}`)
+	upstreamMessage, err := upstreamClient.ReadMessage()
+	if err != nil || upstreamMessage.Command != "EVENT_APO_WIFI_LIST" {
+		t.Fatalf("expected upstream client to still receive events, got %+v (err: %+v)", upstreamMessage, err)
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to listen: %+v", err)
+	}
+	go daemon.Serve(listener)
+	defer daemon.Close()
+
+	client, err := NewClient("", OptionDaemonSocket(socketPath))
+	if err != nil {
+		t.Fatalf("failed to connect to daemon: %+v", err)
+	}
+
+	message, err := client.ReadMessage()
+	if err != nil || message.Command != "EVENT_APO_WIFI_LIST" {
+		t.Fatalf("expected replayed oven list, got %+v (err: %+v)", message, err)
+	}
+	if ovens := *message.Payload.(*dto.WifiListEvent); len(ovens) != 1 || ovens[0].Name != "Kitchen" {
+		t.Errorf("unexpected oven list %+v", ovens)
+	}
+
+	// Acknowledge the relayed command as the backend would
+	go func() {
+		select {
+		case frame := <-upstream.outbound:
+			var command dto.RawMessage
+			_ = json.Unmarshal(frame, &command)
+			upstream.inbound <- []byte(fmt.Sprintf(`{"command":"RESPONSE","requestId":"%s","payload":{"status":"ok"}}`, *command.RequestID))
+		case <-time.After(requestAcknowledgementTimeout):
+		}
+	}()
+
+	err = client.SetLamp("0123456789abcdef", true)
+	if err != nil {
+		t.Errorf("expected relayed command to be acknowledged: %+v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// UnknownFieldsReport lists the fields of a received message that apocli
+// doesn't know about, and which were therefore ignored.
+type UnknownFieldsReport struct {
+	Command dto.MessageType
+	// Dotted paths from the root of the message, e.g.
+	// "payload.state.nodes.newNode". Array elements are denoted by "[]".
+	Fields []string
+}
+
+// UnknownFieldsObserver is called for every received message with unknown
+// fields, e.g. to alert on schema drift after an app update.
+type UnknownFieldsObserver func(report UnknownFieldsReport)
+
+// OptionUnknownFieldsObserver registers a function to be called for every
+// message with unknown fields. It is not called in strict mode.
+func OptionUnknownFieldsObserver(observer UnknownFieldsObserver) func(*Client) error {
+	return func(client *Client) error {
+		client.unknownFieldsObservers = append(client.unknownFieldsObservers, observer)
+		return nil
+	}
+}
+
+// OptionStrictDecoding drops every message with unknown fields or fields of
+// the wrong type, instead of decoding what it can. Useful in tests, to notice
+// when the DTOs fall behind the backend.
+func OptionStrictDecoding(client *Client) error {
+	client.strictDecoding = true
+	return nil
+}
+
+// decodeLayer decodes one layer of a message into v. Unless decoding strictly,
+// unknown fields are collected into unknownFields, prefixed with path, and
+// fields of the wrong type are skipped.
+func (client *Client) decodeLayer(data []byte, v interface{}, path string, unknownFields *[]string) error {
+	if client.strictDecoding {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	}
+
+	err := json.Unmarshal(data, v)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		// encoding/json still decodes every other field
+		slog.Warn("ignored field with unexpected type",
+			slog.String("field", joinPath(path, typeErr.Field)),
+			slog.String("value", typeErr.Value),
+			slog.String("expected", typeErr.Type.String()))
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fields, err := dto.UnknownFields(data, reflect.TypeOf(v))
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		*unknownFields = append(*unknownFields, joinPath(path, field))
+	}
+	return nil
+}
+
+// reportUnknownFields logs each unknown field the first time it is seen, and
+// notifies every observer about every message.
+func (client *Client) reportUnknownFields(command dto.MessageType, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+
+	if client.loggedUnknownFields == nil {
+		client.loggedUnknownFields = make(map[string]bool)
+	}
+	for _, field := range fields {
+		key := string(command) + " " + field
+		if client.loggedUnknownFields[key] {
+			continue
+		}
+		client.loggedUnknownFields[key] = true
+		slog.Warn("received unknown field; it was ignored",
+			slog.String("command", string(command)),
+			slog.String("field", field))
+	}
+
+	report := UnknownFieldsReport{Command: command, Fields: fields}
+	for _, observer := range client.unknownFieldsObservers {
+		observer(report)
+	}
+}
+
+func joinPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	if field == "" || strings.HasPrefix(field, "[]") {
+		return path + field
+	}
+	return path + "." + field
+}
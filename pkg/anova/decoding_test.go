@@ -0,0 +1,75 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const driftedWifiList = `{"command":"EVENT_APO_WIFI_LIST","sentAt":1,"payload":[{"cookerId":"0123456789abcdef","name":"Kitchen","pairedAt":"2024-01-01T00:00:00Z","type":"oven_v2","color":"black"}]}`
+
+func newDecodingTestClient(options ...func(*Client) error) (*Client, *fakeUpstream) {
+	upstream := newFakeUpstream()
+	client := &Client{
+		conn: upstream,
+
+		stop: make(chan bool),
+
+		inboundMessages:  make(chan dto.Message, 100),
+		requestResponses: make(map[dto.RequestID]chan map[string]interface{}),
+	}
+	for _, option := range options {
+		_ = option(client)
+	}
+	go client.receiveMessages()
+	return client, upstream
+}
+
+func TestLenientDecoding(t *testing.T) {
+	reports := make(chan UnknownFieldsReport, 1)
+	client, upstream := newDecodingTestClient(OptionUnknownFieldsObserver(func(report UnknownFieldsReport) {
+		reports <- report
+	}))
+	defer close(upstream.inbound)
+
+	upstream.inbound <- []byte(driftedWifiList)
+	message, err := client.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %+v", err)
+	}
+	if ovens := *message.Payload.(*dto.WifiListEvent); len(ovens) != 1 || ovens[0].Name != "Kitchen" {
+		t.Errorf("expected known fields to be decoded, got %+v", ovens)
+	}
+
+	report := <-reports
+	expected := UnknownFieldsReport{
+		Command: "EVENT_APO_WIFI_LIST",
+		Fields:  []string{"sentAt", "payload[].color"},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected report %+v, got %+v", expected, report)
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	client, upstream := newDecodingTestClient(OptionStrictDecoding)
+	defer close(upstream.inbound)
+
+	upstream.inbound <- []byte(driftedWifiList)
+	upstream.inbound <- []byte(`{"command":"EVENT_APO_WIFI_LIST","payload":[]}`)
+
+	message, err := client.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %+v", err)
+	}
+	if ovens := *message.Payload.(*dto.WifiListEvent); len(ovens) != 0 {
+		t.Errorf("expected message with unknown fields to be dropped, got %+v", ovens)
+	}
+
+	select {
+	case message := <-client.inboundMessages:
+		t.Errorf("unexpected message %+v", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -36,10 +36,14 @@ type Temperature struct {
 	Fahrenheit float64 `json:"fahrenheit,omitempty"`
 }
 
+// CelsiusToFahrenheit rounds to whole degrees Fahrenheit, as sent to the oven.
 func CelsiusToFahrenheit(celsius float64) float64 {
 	return math.Round(1.8*celsius + 32)
 }
 
+// FahrenheitToCelsius rounds to whole degrees Celsius, e.g. 135 °F to 57 °C.
+//
+// Deprecated: use anova.NewTemperatureFahrenheit, which converts exactly.
 func FahrenheitToCelsius(fahrenheit float64) float64 {
 	return math.Round((fahrenheit - 32) * 5.0 / 9.0)
 }
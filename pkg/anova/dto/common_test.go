@@ -75,3 +75,18 @@ func TestTitle_MarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestTitle_RoundTrip(t *testing.T) {
+	// Titles sent with a cook come back in the oven's state
+	for _, title := range []string{"Sear the crust", ""} {
+		encoded, err := json.Marshal(CookingStage{Title: NewTitle(title)})
+		if err != nil {
+			t.Fatalf("marshal failed: %+v", err)
+		}
+		var stage Stage
+		err = json.Unmarshal(encoded, &stage)
+		if err != nil || stage.Title == nil || stage.Title.String != title {
+			t.Errorf("expected title %q to round-trip, got %+v from %s (err: %+v)", title, stage.Title, encoded, err)
+		}
+	}
+}
@@ -0,0 +1,696 @@
+// Command schemagen generates Go types from the JSON Schemas embedded in package
+// dto. It is run by "go generate ./pkg/anova/dto".
+//
+// Variants of a type, i.e. anyOf, allOf and if/then/else, are merged into a
+// single struct with the properties of every variant, in the same way as the
+// hand-written DTOs. Properties are only required if every variant requires
+// them.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Schemas read from the schema folder, in the order they are generated
+var schemaFiles = []string{
+	"oven_state_schema.json",
+	"oven_command_schema.json",
+	"multi_user_command_schema.json",
+}
+
+// Definitions whose names aren't usable as Go identifiers
+var definitionNames = map[string]string{
+	"{and?:StageCondition|any|undefined;or?:StageCondition|any|undefined;}": "StageConditionGroup",
+	"__type": "Object",
+}
+
+// Property names that aren't usable as Go identifiers
+var propertyNames = map[string]string{
+	"<":  "LessThan",
+	"<=": "LessThanOrEqual",
+	"=":  "Equal",
+	">":  "GreaterThan",
+	">=": "GreaterThanOrEqual",
+}
+
+var messageTypePattern = regexp.MustCompile(`^[A-Z0-9_]+$`)
+
+// schema is the subset of JSON Schema draft-07 used by the schemas.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 interface{}        `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	AnyOf                []*schema          `json:"anyOf"`
+	AllOf                []*schema          `json:"allOf"`
+	If                   *schema            `json:"if"`
+	Then                 *schema            `json:"then"`
+	Else                 *schema            `json:"else"`
+	Definitions          map[string]*schema `json:"definitions"`
+}
+
+type kind int
+
+const (
+	kindAny kind = iota
+	kindString
+	kindEnum
+	kindNumber
+	kindBool
+	kindTime
+	kindArray
+	kindMap
+	kindObject
+)
+
+// goType is a resolved Go type expression.
+type goType struct {
+	expr string
+	kind kind
+	// Only for kindEnum
+	values []string
+}
+
+// optional returns the type of an optional field: a pointer, unless the type
+// already has a zero value that encodes to nothing.
+func (t goType) optional() string {
+	switch t.kind {
+	case kindAny, kindArray, kindMap:
+		return t.expr
+	}
+	return "*" + t.expr
+}
+
+type generator struct {
+	definitions map[string]*schema
+
+	// Declarations by type name
+	decls map[string]string
+	// Go names reserved for every definition
+	definitionGoNames map[string]string
+	// Types of definitions and inline schemas, once resolved
+	definitionTypes map[string]goType
+	inlineTypes     map[*schema]goType
+	// Identifiers already declared
+	identifiers map[string]bool
+}
+
+func newGenerator() *generator {
+	return &generator{
+		definitions:       make(map[string]*schema),
+		decls:             make(map[string]string),
+		definitionGoNames: make(map[string]string),
+		definitionTypes:   make(map[string]goType),
+		inlineTypes:       make(map[*schema]goType),
+		identifiers:       map[string]bool{"MessageType": true, "ApoStateEvent": true},
+	}
+}
+
+func (g *generator) addDefinitions(file string, root *schema) error {
+	for name, definition := range root.Definitions {
+		if existing, exists := g.definitions[name]; exists {
+			a, _ := json.Marshal(existing)
+			b, _ := json.Marshal(definition)
+			if !bytes.Equal(a, b) {
+				return fmt.Errorf("%s: definition %s differs from an earlier schema", file, name)
+			}
+			continue
+		}
+		g.definitions[name] = definition
+	}
+	return nil
+}
+
+func (g *generator) deref(node *schema) *schema {
+	for node.Ref != "" {
+		node = g.definitions[strings.TrimPrefix(node.Ref, "#/definitions/")]
+	}
+	return node
+}
+
+func refName(node *schema) string {
+	return strings.TrimPrefix(node.Ref, "#/definitions/")
+}
+
+// identifier converts a schema name into an exported Go identifier, with the
+// usual initialisms, e.g. "cookId" becomes "CookID".
+func identifier(name string) string {
+	if mapped, exists := definitionNames[name]; exists {
+		return mapped
+	}
+	if mapped, exists := propertyNames[name]; exists {
+		return mapped
+	}
+
+	var words []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if strings.ToUpper(part) == part {
+			// e.g. "CMD_APO_SET_LAMP"
+			part = strings.ToLower(part)
+		}
+		// Split camel case into words
+		start := 0
+		for i, r := range part {
+			if i > 0 && unicode.IsUpper(r) {
+				words = append(words, part[start:i])
+				start = i
+			}
+		}
+		words = append(words, part[start:])
+	}
+
+	var result strings.Builder
+	for i, word := range words {
+		// Keep "CookV1_1" distinguishable from "CookV11"
+		if i > 0 && strings.Trim(word, "0123456789") == "" {
+			result.WriteString("_")
+		}
+		switch strings.ToLower(word) {
+		case "id", "url":
+			result.WriteString(strings.ToUpper(word))
+		default:
+			result.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return result.String()
+}
+
+// declare reserves a type name, adding a suffix if it is already taken.
+func (g *generator) declare(name string) string {
+	unique := name
+	for i := 2; g.identifiers[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.identifiers[unique] = true
+	return unique
+}
+
+func (g *generator) definitionType(name string) goType {
+	if t, exists := g.definitionTypes[name]; exists {
+		return t
+	}
+	node := g.definitions[name]
+	goName := g.definitionGoNames[name]
+
+	// Recursive references resolve to the struct being declared
+	g.definitionTypes[name] = goType{expr: goName, kind: kindObject}
+
+	var comment string
+	if node.Description != "" {
+		comment = fmt.Sprintf("// %s %s\n", goName, lowerFirst(node.Description))
+	}
+
+	var t goType
+	switch {
+	case node.Ref != "":
+		target := g.definitionType(refName(node))
+		g.decls[goName] = fmt.Sprintf("%stype %s = %s\n", comment, goName, target.expr)
+		t = goType{expr: goName, kind: target.kind, values: target.values}
+	default:
+		t = g.resolveNamed(node, goName, comment)
+	}
+
+	g.definitionTypes[name] = t
+	return t
+}
+
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// resolve returns the type of an inline schema. Inline structs and enums are
+// declared as goName.
+func (g *generator) resolve(node *schema, goName string) goType {
+	if node.Ref != "" {
+		return g.definitionType(refName(node))
+	}
+	if t, exists := g.inlineTypes[node]; exists {
+		return t
+	}
+
+	var t goType
+	switch g.kindOf(node) {
+	case kindObject, kindEnum:
+		t = g.resolveNamed(node, g.declare(goName), "")
+	default:
+		t = g.resolvePlain(node, goName)
+	}
+	g.inlineTypes[node] = t
+	return t
+}
+
+// resolveNamed declares goName as the type of node.
+func (g *generator) resolveNamed(node *schema, goName string, comment string) goType {
+	switch g.kindOf(node) {
+	case kindObject:
+		g.declareStruct(node, goName, comment)
+		return goType{expr: goName, kind: kindObject}
+	case kindEnum:
+		values := g.enumValues(node)
+		g.declareEnum(goName, values, comment)
+		return goType{expr: goName, kind: kindEnum, values: values}
+	}
+
+	t := g.resolvePlain(node, goName)
+	g.decls[goName] = fmt.Sprintf("%stype %s = %s\n", comment, goName, t.expr)
+	return goType{expr: goName, kind: t.kind}
+}
+
+// resolvePlain resolves schemas that aren't structs or enums.
+func (g *generator) resolvePlain(node *schema, goName string) goType {
+	switch g.kindOf(node) {
+	case kindString:
+		return goType{expr: "string", kind: kindString}
+	case kindNumber:
+		return goType{expr: "float64", kind: kindNumber}
+	case kindBool:
+		return goType{expr: "bool", kind: kindBool}
+	case kindTime:
+		return goType{expr: "time.Time", kind: kindTime}
+	case kindArray:
+		item := g.resolve(node.Items, goName+"Item")
+		return goType{expr: "[]" + item.expr, kind: kindArray}
+	case kindMap:
+		value := goType{expr: "interface{}"}
+		if node.AdditionalProperties != nil {
+			value = g.resolve(node.AdditionalProperties, goName+"Value")
+		}
+		return goType{expr: "map[string]" + value.expr, kind: kindMap}
+	}
+
+	if len(node.AnyOf) > 0 {
+		return g.resolveUnion(node.AnyOf, goName)
+	}
+	return goType{expr: "interface{}", kind: kindAny}
+}
+
+// kindOf classifies a schema, merging the kinds of its variants.
+func (g *generator) kindOf(node *schema) kind {
+	node = g.deref(node)
+	switch {
+	case len(node.Enum) > 0 && node.Type == "string":
+		return kindEnum
+	case node.Properties != nil || node.If != nil || len(node.AllOf) > 0:
+		return kindObject
+	case len(node.AnyOf) > 0:
+		return g.unionKind(node.AnyOf)
+	}
+
+	switch node.Type {
+	case "string":
+		if node.Format == "date-time" {
+			return kindTime
+		}
+		return kindString
+	case "number", "integer":
+		return kindNumber
+	case "boolean":
+		return kindBool
+	case "array":
+		return kindArray
+	case "object":
+		return kindMap
+	}
+	return kindAny
+}
+
+func (g *generator) unionKind(variants []*schema) kind {
+	result := g.kindOf(variants[0])
+	for _, variant := range variants[1:] {
+		k := g.kindOf(variant)
+		switch {
+		case k == result:
+		case (k == kindString || k == kindEnum) && (result == kindString || result == kindEnum):
+			result = kindString
+		default:
+			return kindAny
+		}
+	}
+	return result
+}
+
+// resolveUnion resolves anyOf variants that aren't all objects or enums.
+func (g *generator) resolveUnion(variants []*schema, goName string) goType {
+	switch g.unionKind(variants) {
+	case kindString:
+		return goType{expr: "string", kind: kindString}
+	case kindNumber:
+		return goType{expr: "float64", kind: kindNumber}
+	case kindBool:
+		return goType{expr: "bool", kind: kindBool}
+	case kindTime:
+		return goType{expr: "time.Time", kind: kindTime}
+	case kindArray:
+		items := make([]*schema, len(variants))
+		for i, variant := range variants {
+			items[i] = g.deref(variant).Items
+		}
+		item := g.resolve(&schema{AnyOf: items}, goName+"Item")
+		return goType{expr: "[]" + item.expr, kind: kindArray}
+	}
+	return goType{expr: "interface{}", kind: kindAny}
+}
+
+func (g *generator) enumValues(node *schema) []string {
+	node = g.deref(node)
+	seen := make(map[string]bool)
+	var values []string
+	add := func(value string) {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	for _, value := range node.Enum {
+		add(fmt.Sprint(value))
+	}
+	for _, variant := range node.AnyOf {
+		for _, value := range g.enumValues(variant) {
+			add(value)
+		}
+	}
+	return values
+}
+
+func (g *generator) declareEnum(goName string, values []string, comment string) {
+	var decl strings.Builder
+	fmt.Fprintf(&decl, "%stype %s string\n\n", comment, goName)
+	decl.WriteString("const (\n")
+	for _, value := range values {
+		fmt.Fprintf(&decl, "\t%s %s = %q\n", g.declare(goName+identifier(value)), goName, value)
+	}
+	decl.WriteString(")\n")
+	g.decls[goName] = decl.String()
+}
+
+// property is a property merged from every variant of a schema.
+type property struct {
+	variants []*schema
+	required bool
+}
+
+// collect merges the properties of a schema and all of its variants.
+func (g *generator) collect(node *schema) map[string]*property {
+	node = g.deref(node)
+	properties := make(map[string]*property)
+	add := func(name string, variants []*schema, required bool) {
+		existing, exists := properties[name]
+		if !exists {
+			existing = &property{}
+			properties[name] = existing
+		}
+		for _, variant := range variants {
+			if !containsSchema(existing.variants, variant, g) {
+				existing.variants = append(existing.variants, variant)
+			}
+		}
+		existing.required = existing.required || required
+	}
+
+	for name, child := range node.Properties {
+		add(name, []*schema{child}, false)
+	}
+	for _, name := range node.Required {
+		if _, exists := properties[name]; exists {
+			properties[name].required = true
+		}
+	}
+
+	// Every member of allOf applies
+	for _, member := range node.AllOf {
+		for name, p := range g.collect(member) {
+			add(name, p.variants, p.required)
+		}
+	}
+
+	// Only one of anyOf or then/else applies, so their properties are only
+	// required if every variant requires them
+	var variants []*schema
+	variants = append(variants, node.AnyOf...)
+	if node.If != nil {
+		for _, branch := range []*schema{node.Then, node.Else} {
+			if branch != nil {
+				variants = append(variants, branch)
+			}
+		}
+	}
+	var variantProperties []map[string]*property
+	for _, variant := range variants {
+		variantProperties = append(variantProperties, g.collect(variant))
+	}
+	for _, vp := range variantProperties {
+		for name, p := range vp {
+			requiredByAll := p.required
+			for _, other := range variantProperties {
+				if otherProperty, exists := other[name]; !exists || !otherProperty.required {
+					requiredByAll = false
+				}
+			}
+			add(name, p.variants, requiredByAll)
+		}
+	}
+	return properties
+}
+
+func containsSchema(schemas []*schema, node *schema, g *generator) bool {
+	encoded, _ := json.Marshal(node)
+	for _, existing := range schemas {
+		if existing == node || g.deref(existing) == g.deref(node) {
+			return true
+		}
+		existingEncoded, _ := json.Marshal(existing)
+		if bytes.Equal(existingEncoded, encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) declareStruct(node *schema, goName string, comment string) {
+	properties := g.collect(node)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Reserve the declaration before resolving fields, which may refer back
+	g.decls[goName] = ""
+
+	var decl strings.Builder
+	fmt.Fprintf(&decl, "%stype %s struct {\n", comment, goName)
+	for _, name := range names {
+		p := properties[name]
+		fieldName := identifier(name)
+
+		var t goType
+		if len(p.variants) == 1 {
+			t = g.resolve(p.variants[0], goName+fieldName)
+		} else {
+			t = g.resolveVariants(p.variants, goName+fieldName)
+		}
+
+		if p.required {
+			fmt.Fprintf(&decl, "\t%s %s `json:%q`\n", fieldName, t.expr, name)
+		} else {
+			fmt.Fprintf(&decl, "\t%s %s `json:%q`\n", fieldName, t.optional(), name+",omitempty")
+		}
+	}
+	decl.WriteString("}\n")
+	g.decls[goName] = decl.String()
+}
+
+// resolveVariants resolves a property declared differently by several
+// variants, e.g. a mode enum with one value per variant.
+func (g *generator) resolveVariants(variants []*schema, goName string) goType {
+	union := &schema{AnyOf: variants}
+	switch g.kindOf(union) {
+	case kindObject:
+		name := g.declare(goName)
+		g.declareStruct(union, name, "")
+		return goType{expr: name, kind: kindObject}
+	case kindEnum:
+		name := g.declare(goName)
+		values := g.enumValues(union)
+		g.declareEnum(name, values, "")
+		return goType{expr: name, kind: kindEnum, values: values}
+	}
+	return g.resolveUnion(variants, goName)
+}
+
+// command is a command found in the command schemas.
+type command struct {
+	messageType string
+	payload     string
+}
+
+func (g *generator) commands(root *schema) ([]command, error) {
+	var commands []command
+	for _, variant := range g.deref(root.Properties["payload"]).AnyOf {
+		name := refName(variant)
+		node := g.deref(variant)
+
+		var messageType string
+		for _, value := range g.enumValues(node.Properties["type"]) {
+			if messageTypePattern.MatchString(value) {
+				messageType = value
+				break
+			}
+		}
+		if messageType == "" {
+			return nil, fmt.Errorf("command %s has no message type", name)
+		}
+
+		payloadName := identifier(name) + "Payload"
+		payloadNode := node.Properties["payload"]
+		if payloadNode == nil {
+			payloadName = g.declare(payloadName)
+			g.decls[payloadName] = fmt.Sprintf("type %s struct{}\n", payloadName)
+			commands = append(commands, command{messageType: messageType, payload: payloadName})
+			continue
+		}
+
+		// Versions of a payload are listed separately, as in the DTOs
+		versions := []*schema{payloadNode}
+		if union := g.deref(payloadNode); len(union.AnyOf) > 0 && union.Properties == nil {
+			versions = union.AnyOf
+		}
+		for _, version := range versions {
+			payload := g.resolve(version, payloadName)
+			commands = append(commands, command{messageType: messageType, payload: payload.expr})
+		}
+	}
+	return commands, nil
+}
+
+func generate(schemaFolder string, packageName string) ([]byte, error) {
+	g := newGenerator()
+	roots := make(map[string]*schema)
+	for _, file := range schemaFiles {
+		raw, err := os.ReadFile(filepath.Join(schemaFolder, file))
+		if err != nil {
+			return nil, err
+		}
+		var root schema
+		err = json.Unmarshal(raw, &root)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %+v", file, err)
+		}
+		err = g.addDefinitions(file, &root)
+		if err != nil {
+			return nil, err
+		}
+		roots[file] = &root
+	}
+
+	// Every definition is generated, even if unused
+	definitionNames := make([]string, 0, len(g.definitions))
+	for name := range g.definitions {
+		definitionNames = append(definitionNames, name)
+	}
+	sort.Strings(definitionNames)
+	// Definitions take precedence over inline types for names
+	for _, name := range definitionNames {
+		g.definitionGoNames[name] = g.declare(identifier(name))
+	}
+	for _, name := range definitionNames {
+		g.definitionType(name)
+	}
+
+	ovenState := g.declare("OvenState")
+	g.declareStruct(roots["oven_state_schema.json"], ovenState,
+		"// OvenState is the state of an oven, as sent in EVENT_APO_STATE messages.\n")
+
+	var commands []command
+	for _, file := range []string{"oven_command_schema.json", "multi_user_command_schema.json"} {
+		fileCommands, err := g.commands(roots[file])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %+v", file, err)
+		}
+		commands = append(commands, fileCommands...)
+	}
+	payloads := make(map[string]string)
+	for _, c := range commands {
+		if existing, exists := payloads[c.payload]; exists {
+			return nil, fmt.Errorf("commands %s and %s share payload type %s", existing, c.messageType, c.payload)
+		}
+		payloads[c.payload] = c.messageType
+	}
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "// Code generated by schemagen from the JSON Schemas in pkg/anova/dto/schemas. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&source, "// Package %s contains the DTOs described by the JSON Schemas embedded in\n", packageName)
+	fmt.Fprintf(&source, "// package dto. They are used to check that the hand-written DTOs keep up with\n")
+	fmt.Fprintf(&source, "// the schemas.\n")
+	fmt.Fprintf(&source, "package %s\n\n", packageName)
+	source.WriteString("import (\n\t\"reflect\"\n\t\"time\"\n)\n\n")
+	source.WriteString("type MessageType string\n\n")
+
+	source.WriteString("var RequestTypeToMessageType = map[reflect.Type]MessageType{\n")
+	for _, c := range commands {
+		fmt.Fprintf(&source, "\treflect.TypeOf((*%s)(nil)).Elem(): %q,\n", c.payload, c.messageType)
+	}
+	source.WriteString("}\n\n")
+
+	source.WriteString("// MessageTypeToResponseType only includes EVENT_APO_STATE, the only event\n")
+	source.WriteString("// described by the schemas.\n")
+	source.WriteString("var MessageTypeToResponseType = map[MessageType]reflect.Type{\n")
+	source.WriteString("\t\"EVENT_APO_STATE\": reflect.TypeOf((*ApoStateEvent)(nil)).Elem(),\n")
+	source.WriteString("}\n\n")
+
+	source.WriteString("// ApoStateEvent is the payload of EVENT_APO_STATE messages. The schemas only\n")
+	source.WriteString("// describe its state.\n")
+	fmt.Fprintf(&source, "type ApoStateEvent struct {\n\tCookerID string `json:\"cookerId\"`\n\tType string `json:\"type\"`\n\tState %s `json:\"state\"`\n}\n\n", ovenState)
+
+	declNames := make([]string, 0, len(g.decls))
+	for name := range g.decls {
+		declNames = append(declNames, name)
+	}
+	sort.Strings(declNames)
+	for _, name := range declNames {
+		source.WriteString(g.decls[name])
+		source.WriteString("\n")
+	}
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated source: %+v\n%s", err, source.Bytes())
+	}
+	return formatted, nil
+}
+
+func main() {
+	schemaFolder := flag.String("schemas", "schemas", "Folder containing the JSON Schemas")
+	output := flag.String("out", "schemadto/schemadto.go", "File to write the generated code to")
+	packageName := flag.String("package", "schemadto", "Package name of the generated code")
+	flag.Parse()
+
+	source, err := generate(*schemaFolder, *packageName)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(*output), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(*output, source, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "schemagen: %+v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGeneratedCodeIsUpToDate(t *testing.T) {
+	generated, err := generate("../../schemas", "schemadto")
+	if err != nil {
+		t.Fatalf("failed to generate: %+v", err)
+	}
+	existing, err := os.ReadFile("../../schemadto/schemadto.go")
+	if err != nil {
+		t.Fatalf("failed to read generated code: %+v", err)
+	}
+	if !bytes.Equal(generated, existing) {
+		t.Errorf("schemadto is out of date; run \"go generate ./pkg/anova/dto\"")
+	}
+}
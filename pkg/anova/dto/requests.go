@@ -91,8 +91,12 @@ type SetSteamGeneratorsCommand struct {
 	SteamPercentage  *SteamSetting `json:"steamPercentage,omitempty"`
 }
 
+// SetTemperatureBulbsCommand changes the setpoint of the running stage. Only the
+// bulb matching the mode is set.
 type SetTemperatureBulbsCommand struct {
-	//TODO
+	Mode TemperatureBulbsMode `json:"mode"`
+	Dry  *TemperatureSetting  `json:"dry,omitempty"`
+	Wet  *TemperatureSetting  `json:"wet,omitempty"`
 }
 
 type SetTemperatureUnitCommand struct {
@@ -126,7 +130,6 @@ type CookingStage struct {
 	TemperatureBulbs *StageTemperatureBulbs `json:"temperatureBulbs"`
 	Vent             *StageVent             `json:"vent"`
 
-	//TODO
 	Title       *Title  `json:"title,omitempty"`
 	Description *string `json:"description,omitempty"`
 
@@ -160,8 +163,8 @@ func (stage StopStage) isOvenStage() {}
 
 type StartCookCommandV1 struct {
 	CookID string `json:"cookId,omitempty"`
-	//TODO make this OvenStage like it should be
-	Stages []CookingStage `json:"stages"`
+	// CookingStage or StopStage
+	Stages []OvenStage `json:"stages"`
 }
 
 type StartCookCommandV2 struct {
@@ -241,9 +244,12 @@ type RegisterPushTokenCommand struct {
 }
 
 type TimeZone struct {
-	ID        string `json:"id"`
-	Code      string `json:"code"`
-	GMTOffset int    `json:"gmt_offset"`
+	// IANA name, e.g. "America/New_York"
+	ID string `json:"id"`
+	// Abbreviation, e.g. "EST"
+	Code string `json:"code"`
+	// Offset from UTC in seconds
+	GMTOffset int `json:"gmt_offset"`
 }
 
 // Yes, these fields are snake_case
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"reflect"
 	"time"
 )
@@ -55,8 +56,41 @@ type WifiRemovedEvent struct {
 	//TODO
 }
 
+// WifiFirmwareUpdateEvent reports the progress of an over-the-air firmware
+// update. There is no schema for this event, so the fields shared with the oven
+// state's OtaUpdateV1 are decoded where present, and every field is kept in
+// Fields.
 type WifiFirmwareUpdateEvent struct {
-	//TODO
+	CookerID CookerID
+	Mode     OtaUpdateMode
+	Progress *float64
+
+	Fields map[string]interface{}
+}
+
+func (event *WifiFirmwareUpdateEvent) UnmarshalJSON(data []byte) error {
+	var fields map[string]interface{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	event.Fields = fields
+	// Other events are inconsistent about the casing of this field
+	for _, key := range []string{"cookerId", "cooker_id"} {
+		if cookerID, ok := fields[key].(string); ok {
+			event.CookerID = CookerID(cookerID)
+			break
+		}
+	}
+	if mode, ok := fields["mode"].(string); ok {
+		event.Mode = OtaUpdateMode(mode)
+	}
+	if progress, ok := fields["progress"].(float64); ok {
+		event.Progress = &progress
+	}
+
+	return nil
 }
 
 type WifiListEvent []struct {
@@ -129,7 +163,7 @@ type Stage struct {
 	Description *string     `json:"description,omitempty"`
 	Timer       *StageTimer `json:"timer,omitempty"`
 	// integer 0 for no title, populated string for title
-	Title *interface{} `json:"title,omitempty"`
+	Title *Title `json:"title,omitempty"`
 
 	Fan              *StageFan              `json:"fan"`
 	HeatingElements  *StageHeatingElements  `json:"heatingElements,omitempty"`
@@ -250,6 +284,10 @@ type TemperatureBulbsNodeV1 struct {
 
 type TemperatureProbeNodeV1 struct {
 	Connected bool `json:"connected"`
+	// Only reported while the probe is connected
+	Current *Temperature `json:"current,omitempty"`
+	// Only reported while a stage has a probe target
+	Setpoint *Temperature `json:"setpoint,omitempty"`
 }
 
 type TimerMode string
@@ -322,7 +360,8 @@ const (
 )
 
 type OtaUpdateV1 struct {
-	Mode OtaUpdateMode
+	Mode     OtaUpdateMode `json:"mode"`
+	Progress *float64      `json:"progress,omitempty"`
 }
 
 type SystemInfoV1 struct {
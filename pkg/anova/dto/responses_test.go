@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWifiFirmwareUpdateEvent_UnmarshalJSON(t *testing.T) {
+	var event WifiFirmwareUpdateEvent
+	err := json.Unmarshal([]byte(`{"cookerId":"0123456789abcdef","mode":"update","progress":42,"extra":true}`), &event)
+	if err != nil {
+		t.Fatalf("unmarshal failed: %+v", err)
+	}
+
+	if event.CookerID != "0123456789abcdef" || event.Mode != OtaUpdateModeUpdate ||
+		event.Progress == nil || *event.Progress != 42 || event.Fields["extra"] != true {
+		t.Errorf("unexpected unmarshal result %+v", event)
+	}
+
+	event = WifiFirmwareUpdateEvent{}
+	err = json.Unmarshal([]byte(`{"cooker_id":"0123456789abcdef"}`), &event)
+	if err != nil {
+		t.Fatalf("unmarshal failed: %+v", err)
+	}
+	if event.CookerID != "0123456789abcdef" || event.Mode != "" || event.Progress != nil {
+		t.Errorf("unexpected unmarshal result %+v", event)
+	}
+}
@@ -1,5 +1,7 @@
 package dto
 
+//go:generate go run ./internal/schemagen -schemas schemas -out schemadto/schemadto.go
+
 import (
 	_ "embed"
 	"github.com/xeipuuv/gojsonschema"
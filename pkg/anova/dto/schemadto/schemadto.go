@@ -0,0 +1,2828 @@
+// Code generated by schemagen from the JSON Schemas in pkg/anova/dto/schemas. DO NOT EDIT.
+
+// Package schemadto contains the DTOs described by the JSON Schemas embedded in
+// package dto. They are used to check that the hand-written DTOs keep up with
+// the schemas.
+package schemadto
+
+import (
+	"reflect"
+	"time"
+)
+
+type MessageType string
+
+var RequestTypeToMessageType = map[reflect.Type]MessageType{
+	reflect.TypeOf((*SetFanCommandPayload)(nil)).Elem():                   "CMD_APO_SET_FAN",
+	reflect.TypeOf((*SetHeatingElementsCommandPayload)(nil)).Elem():       "CMD_APO_SET_HEATING_ELEMENTS",
+	reflect.TypeOf((*SetLampCommandPayload)(nil)).Elem():                  "CMD_APO_SET_LAMP",
+	reflect.TypeOf((*SetLampPreferenceCommandPayload)(nil)).Elem():        "CMD_APO_SET_LAMP_PREFERENCE",
+	reflect.TypeOf((*SetProbeCommandPayloadV1)(nil)).Elem():               "CMD_APO_SET_PROBE",
+	reflect.TypeOf((*SetProbeCommandPayloadV2)(nil)).Elem():               "CMD_APO_SET_PROBE",
+	reflect.TypeOf((*SetSteamGeneratorsCommandPayload2)(nil)).Elem():      "CMD_APO_SET_STEAM_GENERATORS",
+	reflect.TypeOf((*SetSteamGeneratorsCommandPayload3)(nil)).Elem():      "CMD_APO_SET_STEAM_GENERATORS",
+	reflect.TypeOf((*SetTemperatureBulbsCommandPayloadWetV1)(nil)).Elem(): "CMD_APO_SET_TEMPERATURE_BULBS",
+	reflect.TypeOf((*SetTemperatureBulbsCommandPayloadDryV1)(nil)).Elem(): "CMD_APO_SET_TEMPERATURE_BULBS",
+	reflect.TypeOf((*SetTemperatureBulbsCommandPayloadWetV2)(nil)).Elem(): "CMD_APO_SET_TEMPERATURE_BULBS",
+	reflect.TypeOf((*SetTemperatureBulbsCommandPayloadDryV2)(nil)).Elem(): "CMD_APO_SET_TEMPERATURE_BULBS",
+	reflect.TypeOf((*SetTemperatureUnitCommandPayload)(nil)).Elem():       "CMD_APO_SET_TEMPERATURE_UNIT",
+	reflect.TypeOf((*SetTimerCommandPayload)(nil)).Elem():                 "CMD_APO_SET_TIMER",
+	reflect.TypeOf((*SetVentCommandPayload)(nil)).Elem():                  "CMD_APO_SET_VENT",
+	reflect.TypeOf((*StartCookCommandPayloadV1)(nil)).Elem():              "CMD_APO_START",
+	reflect.TypeOf((*StartCookCommandPayloadV2)(nil)).Elem():              "CMD_APO_START",
+	reflect.TypeOf((*StartDescaleCommandPayload)(nil)).Elem():             "CMD_APO_START_DESCALE",
+	reflect.TypeOf((*AbortDescaleCommandPayload)(nil)).Elem():             "CMD_APO_ABORT_DESCALE",
+	reflect.TypeOf((*StartFirmwareUpdateCommandPayload)(nil)).Elem():      "CMD_APO_OTA",
+	reflect.TypeOf((*StartStageCommandPayload)(nil)).Elem():               "CMD_APO_START_STAGE",
+	reflect.TypeOf((*StopCookCommandPayload)(nil)).Elem():                 "CMD_APO_STOP",
+	reflect.TypeOf((*StageV2)(nil)).Elem():                                "CMD_APO_UPDATE_COOK_STAGE",
+	reflect.TypeOf((*CookingStage)(nil)).Elem():                           "CMD_APO_UPDATE_COOK_STAGE",
+	reflect.TypeOf((*StopStage)(nil)).Elem():                              "CMD_APO_UPDATE_COOK_STAGE",
+	reflect.TypeOf((*UpdateCookStagesCommandPayload)(nil)).Elem():         "CMD_APO_UPDATE_COOK_STAGES",
+	reflect.TypeOf((*UpdateCookStagesV1CommandPayload)(nil)).Elem():       "CMD_APO_UPDATE_COOK_STAGES",
+	reflect.TypeOf((*SetReportStateRatePayload)(nil)).Elem():              "CMD_APO_SET_REPORT_STATE_RATE",
+	reflect.TypeOf((*SetReportStateRateDefaultPayload)(nil)).Elem():       "CMD_APO_SET_REPORT_STATE_RATE_DEFAULT",
+	reflect.TypeOf((*SetBoilerTimePayload)(nil)).Elem():                   "CMD_APO_SET_BOILER_TIME",
+	reflect.TypeOf((*AuthTokenV2Payload)(nil)).Elem():                     "AUTH_TOKEN_V2",
+	reflect.TypeOf((*HealthCheckPayload)(nil)).Elem():                     "CMD_APO_HEALTHCHECK",
+	reflect.TypeOf((*SetMetadataCommandPayload)(nil)).Elem():              "CMD_APO_SET_METADATA",
+	reflect.TypeOf((*GetConfigurationPayload)(nil)).Elem():                "CMD_APO_GET_CONFIGURATION",
+	reflect.TypeOf((*SetConfigurationPayload)(nil)).Elem():                "CMD_APO_SET_CONFIGURATION",
+	reflect.TypeOf((*NameWifiDeviceCommandPayload)(nil)).Elem():           "CMD_APO_NAME_WIFI_DEVICE",
+	reflect.TypeOf((*DisconnectCommandPayload)(nil)).Elem():               "CMD_APO_DISCONNECT",
+	reflect.TypeOf((*RequestDiagnosticsCommandPayload)(nil)).Elem():       "CMD_APO_REQUEST_DIAGNOSTIC",
+	reflect.TypeOf((*RegisterPushTokenCommandPayload)(nil)).Elem():        "CMD_APO_REGISTER_PUSH_TOKEN",
+	reflect.TypeOf((*SetTimeZoneCommandPayload)(nil)).Elem():              "CMD_APO_SET_TIME_ZONE",
+	reflect.TypeOf((*CloudflareSrtPayload)(nil)).Elem():                   "CMD_APO_START_LIVE_STREAM",
+	reflect.TypeOf((*StopLiveStreamCommandPayload)(nil)).Elem():           "CMD_APO_STOP_LIVE_STREAM",
+	reflect.TypeOf((*GenerateNewPairingCodePayload)(nil)).Elem():          "CMD_GENERATE_NEW_PAIRING",
+	reflect.TypeOf((*AddUserWithPairingCodePayload)(nil)).Elem():          "CMD_ADD_USER_WITH_PAIRING",
+	reflect.TypeOf((*ListUsersForDevicePayload)(nil)).Elem():              "CMD_LIST_USERS",
+	reflect.TypeOf((*PairAlexaPayload)(nil)).Elem():                       "CMD_USER_PAIR_ALEXA",
+	reflect.TypeOf((*UnpairAlexaPayload)(nil)).Elem():                     "CMD_USER_UNPAIR_ALEXA",
+}
+
+// MessageTypeToResponseType only includes EVENT_APO_STATE, the only event
+// described by the schemas.
+var MessageTypeToResponseType = map[MessageType]reflect.Type{
+	"EVENT_APO_STATE": reflect.TypeOf((*ApoStateEvent)(nil)).Elem(),
+}
+
+// ApoStateEvent is the payload of EVENT_APO_STATE messages. The schemas only
+// describe its state.
+type ApoStateEvent struct {
+	CookerID string    `json:"cookerId"`
+	Type     string    `json:"type"`
+	State    OvenState `json:"state"`
+}
+
+type AbortDescaleCommand struct {
+	ID   string                  `json:"id"`
+	Type AbortDescaleCommandType `json:"type"`
+}
+
+type AbortDescaleCommandPayload struct{}
+
+type AbortDescaleCommandType string
+
+const (
+	AbortDescaleCommandTypeCmdApoAbortDescale AbortDescaleCommandType = "CMD_APO_ABORT_DESCALE"
+	AbortDescaleCommandTypeAbortDescale       AbortDescaleCommandType = "abortDescale"
+)
+
+type AddUserWithPairingCode struct {
+	ID      string                        `json:"id"`
+	Payload AddUserWithPairingCodePayload `json:"payload"`
+	Type    AddUserWithPairingCodeType    `json:"type"`
+}
+
+type AddUserWithPairingCodePayload struct {
+	Data string `json:"data"`
+}
+
+type AddUserWithPairingCodeType string
+
+const (
+	AddUserWithPairingCodeTypeCmdAddUserWithPairing AddUserWithPairingCodeType = "CMD_ADD_USER_WITH_PAIRING"
+)
+
+type AuthTokenV2 struct {
+	ID      string             `json:"id"`
+	Payload AuthTokenV2Payload `json:"payload"`
+	Type    AuthTokenV2Type    `json:"type"`
+}
+
+type AuthTokenV2Payload struct {
+	Platform             string   `json:"platform"`
+	SupportedAccessories []string `json:"supportedAccessories"`
+	Token                string   `json:"token"`
+}
+
+type AuthTokenV2Type string
+
+const (
+	AuthTokenV2TypeAuthTokenV2 AuthTokenV2Type = "AUTH_TOKEN_V2"
+)
+
+type BoilerSteamGeneratorNode struct {
+	Boiler BoilerSteamGeneratorNodeBoiler `json:"boiler"`
+}
+
+type BoilerSteamGeneratorNodeBoiler struct {
+	Celsius         float64 `json:"celsius"`
+	DescaleRequired bool    `json:"descaleRequired"`
+	Dosed           bool    `json:"dosed"`
+	Failed          bool    `json:"failed"`
+	NtcConnected    *bool   `json:"ntcConnected,omitempty"`
+	Overheated      bool    `json:"overheated"`
+	Watts           float64 `json:"watts"`
+}
+
+type BoilerSteamGeneratorNodeV2 struct {
+	Boiler BoilerSteamGeneratorNodeV2Boiler `json:"boiler"`
+}
+
+type BoilerSteamGeneratorNodeV2Boiler struct {
+	Celsius                       float64 `json:"celsius"`
+	Dc12VInletPumpStatus          string  `json:"dc12VInletPumpStatus"`
+	Dc12VOutletValveDescaleStatus string  `json:"dc12VOutletValveDescaleStatus"`
+	DescaleRequired               bool    `json:"descaleRequired"`
+	Dosed                         bool    `json:"dosed"`
+	Failed                        bool    `json:"failed"`
+	NtcConnected                  bool    `json:"ntcConnected"`
+	Watts                         float64 `json:"watts"`
+}
+
+type CavityLampNode struct {
+	Failed *bool `json:"failed,omitempty"`
+	On     bool  `json:"on"`
+}
+
+type CloudflareSrtPayload = CloudflareSrtPayload_1
+
+type CloudflareSrtPayload_1 struct {
+	Srt CloudflareSrtPayload_1Srt `json:"srt"`
+}
+
+type CloudflareSrtPayload_1Srt struct {
+	Passphrase string `json:"passphrase"`
+	StreamID   string `json:"streamId"`
+	URL        string `json:"url"`
+}
+
+type CommandType string
+
+const (
+	CommandTypeAuthTokenV2                     CommandType = "AUTH_TOKEN_V2"
+	CommandTypeCmdApoAbortDescale              CommandType = "CMD_APO_ABORT_DESCALE"
+	CommandTypeCmdApoDisconnect                CommandType = "CMD_APO_DISCONNECT"
+	CommandTypeCmdApoGetConfiguration          CommandType = "CMD_APO_GET_CONFIGURATION"
+	CommandTypeCmdApoHealthcheck               CommandType = "CMD_APO_HEALTHCHECK"
+	CommandTypeCmdApoNameWifiDevice            CommandType = "CMD_APO_NAME_WIFI_DEVICE"
+	CommandTypeCmdApoOta                       CommandType = "CMD_APO_OTA"
+	CommandTypeCmdApoRegisterPushToken         CommandType = "CMD_APO_REGISTER_PUSH_TOKEN"
+	CommandTypeCmdApoRequestDiagnostic         CommandType = "CMD_APO_REQUEST_DIAGNOSTIC"
+	CommandTypeCmdApoSetBoilerTime             CommandType = "CMD_APO_SET_BOILER_TIME"
+	CommandTypeCmdApoSetConfiguration          CommandType = "CMD_APO_SET_CONFIGURATION"
+	CommandTypeCmdApoSetFan                    CommandType = "CMD_APO_SET_FAN"
+	CommandTypeCmdApoSetHeatingElements        CommandType = "CMD_APO_SET_HEATING_ELEMENTS"
+	CommandTypeCmdApoSetLamp                   CommandType = "CMD_APO_SET_LAMP"
+	CommandTypeCmdApoSetLampPreference         CommandType = "CMD_APO_SET_LAMP_PREFERENCE"
+	CommandTypeCmdApoSetMetadata               CommandType = "CMD_APO_SET_METADATA"
+	CommandTypeCmdApoSetProbe                  CommandType = "CMD_APO_SET_PROBE"
+	CommandTypeCmdApoSetReportStateRate        CommandType = "CMD_APO_SET_REPORT_STATE_RATE"
+	CommandTypeCmdApoSetReportStateRateDefault CommandType = "CMD_APO_SET_REPORT_STATE_RATE_DEFAULT"
+	CommandTypeCmdApoSetSteamGenerators        CommandType = "CMD_APO_SET_STEAM_GENERATORS"
+	CommandTypeCmdApoSetTemperatureBulbs       CommandType = "CMD_APO_SET_TEMPERATURE_BULBS"
+	CommandTypeCmdApoSetTemperatureUnit        CommandType = "CMD_APO_SET_TEMPERATURE_UNIT"
+	CommandTypeCmdApoSetTimer                  CommandType = "CMD_APO_SET_TIMER"
+	CommandTypeCmdApoSetTimeZone               CommandType = "CMD_APO_SET_TIME_ZONE"
+	CommandTypeCmdApoSetVent                   CommandType = "CMD_APO_SET_VENT"
+	CommandTypeCmdApoStart                     CommandType = "CMD_APO_START"
+	CommandTypeCmdApoStartDescale              CommandType = "CMD_APO_START_DESCALE"
+	CommandTypeCmdApoStartLiveStream           CommandType = "CMD_APO_START_LIVE_STREAM"
+	CommandTypeCmdApoStartStage                CommandType = "CMD_APO_START_STAGE"
+	CommandTypeCmdApoStop                      CommandType = "CMD_APO_STOP"
+	CommandTypeCmdApoStopLiveStream            CommandType = "CMD_APO_STOP_LIVE_STREAM"
+	CommandTypeCmdApoUpdateCookStage           CommandType = "CMD_APO_UPDATE_COOK_STAGE"
+	CommandTypeCmdApoUpdateCookStages          CommandType = "CMD_APO_UPDATE_COOK_STAGES"
+	CommandTypeAbortDescale                    CommandType = "abortDescale"
+	CommandTypeGetConfiguration                CommandType = "getConfiguration"
+	CommandTypeLampPreference                  CommandType = "lampPreference"
+	CommandTypeRequestDiagnostic               CommandType = "requestDiagnostic"
+	CommandTypeSetBoilerTime                   CommandType = "setBoilerTime"
+	CommandTypeSetConfiguration                CommandType = "setConfiguration"
+	CommandTypeSetFan                          CommandType = "setFan"
+	CommandTypeSetHeatingElements              CommandType = "setHeatingElements"
+	CommandTypeSetLamp                         CommandType = "setLamp"
+	CommandTypeSetProbe                        CommandType = "setProbe"
+	CommandTypeSetReportStateRate              CommandType = "setReportStateRate"
+	CommandTypeSetReportStateRateDefault       CommandType = "setReportStateRateDefault"
+	CommandTypeSetSteamGenerators              CommandType = "setSteamGenerators"
+	CommandTypeSetTemperatureBulbs             CommandType = "setTemperatureBulbs"
+	CommandTypeSetTemperatureUnit              CommandType = "setTemperatureUnit"
+	CommandTypeSetTimer                        CommandType = "setTimer"
+	CommandTypeSetVent                         CommandType = "setVent"
+	CommandTypeStartCook                       CommandType = "startCook"
+	CommandTypeStartDescale                    CommandType = "startDescale"
+	CommandTypeStartFirmwareUpdate             CommandType = "startFirmwareUpdate"
+	CommandTypeStartStage                      CommandType = "startStage"
+	CommandTypeStopCook                        CommandType = "stopCook"
+	CommandTypeUpdateCookStage                 CommandType = "updateCookStage"
+	CommandTypeUpdateCookStages                CommandType = "updateCookStages"
+)
+
+type Conditions struct {
+	LessThan           interface{} `json:"<,omitempty"`
+	LessThanOrEqual    interface{} `json:"<=,omitempty"`
+	Equal              interface{} `json:"=,omitempty"`
+	GreaterThan        interface{} `json:">,omitempty"`
+	GreaterThanOrEqual interface{} `json:">=,omitempty"`
+	Equals             interface{} `json:"equals,omitempty"`
+	Exists             interface{} `json:"exists,omitempty"`
+}
+
+type ConnectedTemperatureProbeNodeV1 struct {
+	Connected bool                                   `json:"connected"`
+	Current   ConnectedTemperatureProbeNodeV1Current `json:"current"`
+}
+
+type ConnectedTemperatureProbeNodeV1Current struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type ConnectedTemperatureProbeNodeV2 struct {
+	Connected bool                                   `json:"connected"`
+	Current   ConnectedTemperatureProbeNodeV2Current `json:"current"`
+}
+
+type ConnectedTemperatureProbeNodeV2Current struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type CookV1 = CookV1_1
+
+type CookV1_1 struct {
+	ActiveStageID                    string               `json:"activeStageId"`
+	ActiveStageIndex                 float64              `json:"activeStageIndex"`
+	ActiveStageSecondsElapsed        float64              `json:"activeStageSecondsElapsed"`
+	CookID                           string               `json:"cookId"`
+	SecondsElapsed                   float64              `json:"secondsElapsed"`
+	StageTransitionPendingUserAction bool                 `json:"stageTransitionPendingUserAction"`
+	Stages                           []CookV1_1StagesItem `json:"stages"`
+}
+
+type CookV1_1StagesItem struct {
+	Description        *string                `json:"description,omitempty"`
+	Fan                *StageFan              `json:"fan,omitempty"`
+	HeatingElements    *StageHeatingElements  `json:"heatingElements,omitempty"`
+	ID                 string                 `json:"id"`
+	PhotoURL           *string                `json:"photoUrl,omitempty"`
+	ProbeAdded         *bool                  `json:"probeAdded,omitempty"`
+	RackPosition       *StageRackPosition     `json:"rackPosition,omitempty"`
+	SteamGenerators    *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs   *StageTemperatureBulbs `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe   *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer              *StageTimer            `json:"timer,omitempty"`
+	Title              interface{}            `json:"title,omitempty"`
+	Type               StageType              `json:"type"`
+	UserActionRequired bool                   `json:"userActionRequired"`
+	Vent               *StageVent             `json:"vent,omitempty"`
+	VideoThumbnailURL  *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL           *string                `json:"videoUrl,omitempty"`
+}
+
+type CookV2 = CookV2_1
+
+type CookV2_1 struct {
+	ActiveStageID                    string    `json:"activeStageId"`
+	ActiveStageIndex                 float64   `json:"activeStageIndex"`
+	ActiveStageMode                  string    `json:"activeStageMode"`
+	ActiveStageStartedTimestamp      string    `json:"activeStageStartedTimestamp"`
+	CookID                           string    `json:"cookId"`
+	CookableType                     string    `json:"cookableType"`
+	OriginSource                     string    `json:"originSource"`
+	StageTransitionPendingUserAction bool      `json:"stageTransitionPendingUserAction"`
+	Stages                           []StageV2 `json:"stages"`
+	StartedTimestamp                 string    `json:"startedTimestamp"`
+}
+
+type CookingStage struct {
+	Description        *string                `json:"description,omitempty"`
+	Fan                StageFan               `json:"fan"`
+	HeatingElements    StageHeatingElements   `json:"heatingElements"`
+	ID                 string                 `json:"id"`
+	PhotoURL           *string                `json:"photoUrl,omitempty"`
+	ProbeAdded         *bool                  `json:"probeAdded,omitempty"`
+	RackPosition       *StageRackPosition     `json:"rackPosition,omitempty"`
+	SteamGenerators    *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs   StageTemperatureBulbs  `json:"temperatureBulbs"`
+	TemperatureProbe   *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer              *StageTimer            `json:"timer,omitempty"`
+	Title              interface{}            `json:"title,omitempty"`
+	Type               StageType              `json:"type"`
+	UserActionRequired bool                   `json:"userActionRequired"`
+	Vent               StageVent              `json:"vent"`
+	VideoThumbnailURL  *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL           *string                `json:"videoUrl,omitempty"`
+}
+
+// Date enables basic storage and retrieval of dates and times.
+type Date = time.Time
+
+type Dc12VLineNode struct {
+	NumberOfFaults     float64 `json:"numberOfFaults"`
+	NumberOfRejections float64 `json:"numberOfRejections"`
+}
+
+type DisconnectCommand struct {
+	ID      string                   `json:"id"`
+	Payload DisconnectCommandPayload `json:"payload"`
+	Type    DisconnectCommandType    `json:"type"`
+}
+
+type DisconnectCommandPayload struct {
+	UserID *string `json:"userId,omitempty"`
+}
+
+type DisconnectCommandType string
+
+const (
+	DisconnectCommandTypeCmdApoDisconnect DisconnectCommandType = "CMD_APO_DISCONNECT"
+)
+
+type DisconnectedTemperatureProbeNode struct {
+	Connected bool `json:"connected"`
+}
+
+type DisplayBoardNode struct {
+	PowerConsumption float64 `json:"powerConsumption"`
+}
+
+type DisplayFanNode struct {
+	Dc12VStatus string `json:"dc12VStatus"`
+	Speed       string `json:"speed"`
+}
+
+type DoorLampNode struct {
+	Failed      *bool  `json:"failed,omitempty"`
+	On          bool   `json:"on"`
+	Preferences string `json:"preferences"`
+}
+
+type DoorNode struct {
+	Closed bool `json:"closed"`
+}
+
+type DryTemperatureBulbNodeV1 struct {
+	Dry       DryTemperatureBulbNodeV1Dry       `json:"dry"`
+	DryBottom DryTemperatureBulbNodeV1DryBottom `json:"dryBottom"`
+	DryTop    DryTemperatureBulbNodeV1DryTop    `json:"dryTop"`
+	Mode      DryTemperatureBulbNodeV1Mode      `json:"mode"`
+	Wet       DryTemperatureBulbNodeV1Wet       `json:"wet"`
+}
+
+type DryTemperatureBulbNodeV1Dry struct {
+	Current  DryTemperatureBulbNodeV1DryCurrent  `json:"current"`
+	Setpoint DryTemperatureBulbNodeV1DrySetpoint `json:"setpoint"`
+}
+
+type DryTemperatureBulbNodeV1DryBottom struct {
+	Current    DryTemperatureBulbNodeV1DryBottomCurrent `json:"current"`
+	Overheated bool                                     `json:"overheated"`
+}
+
+type DryTemperatureBulbNodeV1DryBottomCurrent struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type DryTemperatureBulbNodeV1DryCurrent struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type DryTemperatureBulbNodeV1DrySetpoint struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type DryTemperatureBulbNodeV1DryTop struct {
+	Current    DryTemperatureBulbNodeV1DryTopCurrent `json:"current"`
+	Overheated bool                                  `json:"overheated"`
+}
+
+type DryTemperatureBulbNodeV1DryTopCurrent struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type DryTemperatureBulbNodeV1Mode string
+
+const (
+	DryTemperatureBulbNodeV1ModeDry DryTemperatureBulbNodeV1Mode = "dry"
+)
+
+type DryTemperatureBulbNodeV1Wet struct {
+	Current    DryTemperatureBulbNodeV1WetCurrent `json:"current"`
+	DoseFailed bool                               `json:"doseFailed"`
+	Dosed      bool                               `json:"dosed"`
+}
+
+type DryTemperatureBulbNodeV1WetCurrent struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type DryTemperatureBulbNodeV2 struct {
+	Dry       DryTemperatureBulbNodeV2Dry       `json:"dry"`
+	DryBottom DryTemperatureBulbNodeV2DryBottom `json:"dryBottom"`
+	DryTop    DryTemperatureBulbNodeV2DryTop    `json:"dryTop"`
+	Mode      DryTemperatureBulbNodeV2Mode      `json:"mode"`
+	Wet       DryTemperatureBulbNodeV2Wet       `json:"wet"`
+}
+
+type DryTemperatureBulbNodeV2Dry struct {
+	Current  DryTemperatureBulbNodeV2DryCurrent   `json:"current"`
+	Setpoint *DryTemperatureBulbNodeV2DrySetpoint `json:"setpoint,omitempty"`
+}
+
+type DryTemperatureBulbNodeV2DryBottom struct {
+	Current      DryTemperatureBulbNodeV2DryBottomCurrent `json:"current"`
+	NtcConnected bool                                     `json:"ntcConnected"`
+}
+
+type DryTemperatureBulbNodeV2DryBottomCurrent struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type DryTemperatureBulbNodeV2DryCurrent struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type DryTemperatureBulbNodeV2DrySetpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type DryTemperatureBulbNodeV2DryTop struct {
+	Current      DryTemperatureBulbNodeV2DryTopCurrent `json:"current"`
+	NtcConnected bool                                  `json:"ntcConnected"`
+}
+
+type DryTemperatureBulbNodeV2DryTopCurrent struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type DryTemperatureBulbNodeV2Mode string
+
+const (
+	DryTemperatureBulbNodeV2ModeDry DryTemperatureBulbNodeV2Mode = "dry"
+)
+
+type DryTemperatureBulbNodeV2Wet struct {
+	Current          DryTemperatureBulbNodeV2WetCurrent `json:"current"`
+	Dc12VInletStatus string                             `json:"dc12VInletStatus"`
+	Dosed            bool                               `json:"dosed"`
+	NtcConnected     bool                               `json:"ntcConnected"`
+}
+
+type DryTemperatureBulbNodeV2WetCurrent struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type EvaporatorSteamGeneratorNode struct {
+	Evaporator EvaporatorSteamGeneratorNodeEvaporator `json:"evaporator"`
+}
+
+type EvaporatorSteamGeneratorNodeEvaporator struct {
+	Celsius    float64 `json:"celsius"`
+	Failed     bool    `json:"failed"`
+	Overheated bool    `json:"overheated"`
+	Watts      float64 `json:"watts"`
+}
+
+type EvaporatorSteamGeneratorNodeV2 struct {
+	Evaporator EvaporatorSteamGeneratorNodeV2Evaporator `json:"evaporator"`
+}
+
+type EvaporatorSteamGeneratorNodeV2Evaporator struct {
+	Celsius      float64 `json:"celsius"`
+	Failed       bool    `json:"failed"`
+	NtcConnected bool    `json:"ntcConnected"`
+	Watts        float64 `json:"watts"`
+}
+
+type ExhaustFanNode struct {
+	Dc12VStatus string `json:"dc12VStatus"`
+	Speed       string `json:"speed"`
+}
+
+type ExhaustVentNode struct {
+	Dc12VStatus string               `json:"dc12VStatus"`
+	State       ExhaustVentNodeState `json:"state"`
+}
+
+type ExhaustVentNodeState string
+
+const (
+	ExhaustVentNodeStateClosed  ExhaustVentNodeState = "closed"
+	ExhaustVentNodeStateOpenMax ExhaustVentNodeState = "open-max"
+	ExhaustVentNodeStateOpenMid ExhaustVentNodeState = "open-mid"
+)
+
+type FanNode struct {
+	Failed bool    `json:"failed"`
+	Speed  float64 `json:"speed"`
+}
+
+type FanNodeV2 struct {
+	Failed bool   `json:"failed"`
+	Speed  string `json:"speed"`
+}
+
+type GenerateNewPairingCode struct {
+	ID   string                     `json:"id"`
+	Type GenerateNewPairingCodeType `json:"type"`
+}
+
+type GenerateNewPairingCodePayload struct{}
+
+type GenerateNewPairingCodeType string
+
+const (
+	GenerateNewPairingCodeTypeCmdGenerateNewPairing GenerateNewPairingCodeType = "CMD_GENERATE_NEW_PAIRING"
+)
+
+type GetConfiguration struct {
+	ID      string                  `json:"id"`
+	Payload GetConfigurationPayload `json:"payload"`
+	Type    GetConfigurationType    `json:"type"`
+}
+
+type GetConfigurationPayload struct {
+}
+
+type GetConfigurationType string
+
+const (
+	GetConfigurationTypeCmdApoGetConfiguration GetConfigurationType = "CMD_APO_GET_CONFIGURATION"
+	GetConfigurationTypeGetConfiguration       GetConfigurationType = "getConfiguration"
+)
+
+type HealthCheck struct {
+	ID   string          `json:"id"`
+	Type HealthCheckType `json:"type"`
+}
+
+type HealthCheckPayload struct{}
+
+type HealthCheckType string
+
+const (
+	HealthCheckTypeCmdApoHealthcheck HealthCheckType = "CMD_APO_HEALTHCHECK"
+)
+
+type HeatingElement struct {
+	Failed bool    `json:"failed"`
+	On     bool    `json:"on"`
+	Watts  float64 `json:"watts"`
+}
+
+type HeatingElementsNode struct {
+	Bottom HeatingElement `json:"bottom"`
+	Rear   HeatingElement `json:"rear"`
+	Top    HeatingElement `json:"top"`
+}
+
+type IdleSteamGeneratorNode struct {
+	Mode IdleSteamGeneratorNodeMode `json:"mode"`
+}
+
+type IdleSteamGeneratorNodeMode string
+
+const (
+	IdleSteamGeneratorNodeModeIdle IdleSteamGeneratorNodeMode = "idle"
+)
+
+type LampNode struct {
+	Failed     bool   `json:"failed"`
+	On         bool   `json:"on"`
+	Preference string `json:"preference"`
+}
+
+type LedFanNode struct {
+	Dc12VStatus string `json:"dc12VStatus"`
+	Speed       string `json:"speed"`
+}
+
+type ListUsersForDevice struct {
+	ID   string                 `json:"id"`
+	Type ListUsersForDeviceType `json:"type"`
+}
+
+type ListUsersForDevicePayload struct{}
+
+type ListUsersForDeviceType string
+
+const (
+	ListUsersForDeviceTypeCmdListUsers ListUsersForDeviceType = "CMD_LIST_USERS"
+)
+
+type MultiUserCommand struct {
+	ID      string                   `json:"id"`
+	Payload *MultiUserCommandPayload `json:"payload,omitempty"`
+	Type    MultiUserCommandType2    `json:"type"`
+}
+
+type MultiUserCommandPayload struct {
+	Code *string `json:"code,omitempty"`
+	Data *string `json:"data,omitempty"`
+}
+
+type MultiUserCommandType string
+
+const (
+	MultiUserCommandTypeCmdAddUserWithPairing MultiUserCommandType = "CMD_ADD_USER_WITH_PAIRING"
+	MultiUserCommandTypeCmdGenerateNewPairing MultiUserCommandType = "CMD_GENERATE_NEW_PAIRING"
+	MultiUserCommandTypeCmdListUsers          MultiUserCommandType = "CMD_LIST_USERS"
+	MultiUserCommandTypeCmdUserPairAlexa2     MultiUserCommandType = "CMD_USER_PAIR_ALEXA"
+	MultiUserCommandTypeCmdUserUnpairAlexa2   MultiUserCommandType = "CMD_USER_UNPAIR_ALEXA"
+)
+
+type MultiUserCommandType2 string
+
+const (
+	MultiUserCommandType2CmdGenerateNewPairing MultiUserCommandType2 = "CMD_GENERATE_NEW_PAIRING"
+	MultiUserCommandType2CmdAddUserWithPairing MultiUserCommandType2 = "CMD_ADD_USER_WITH_PAIRING"
+	MultiUserCommandType2CmdListUsers          MultiUserCommandType2 = "CMD_LIST_USERS"
+	MultiUserCommandType2CmdUserPairAlexa      MultiUserCommandType2 = "CMD_USER_PAIR_ALEXA"
+	MultiUserCommandType2CmdUserUnpairAlexa    MultiUserCommandType2 = "CMD_USER_UNPAIR_ALEXA"
+)
+
+type MultiUserCommandTypeCmdUserPairAlexa string
+
+const (
+	MultiUserCommandTypeCmdUserPairAlexaCmdUserPairAlexa MultiUserCommandTypeCmdUserPairAlexa = "CMD_USER_PAIR_ALEXA"
+)
+
+type MultiUserCommandTypeCmdUserUnpairAlexa string
+
+const (
+	MultiUserCommandTypeCmdUserUnpairAlexaCmdUserUnpairAlexa MultiUserCommandTypeCmdUserUnpairAlexa = "CMD_USER_UNPAIR_ALEXA"
+)
+
+type NameWifiDeviceCommand struct {
+	ID      string                       `json:"id"`
+	Payload NameWifiDeviceCommandPayload `json:"payload"`
+	Type    NameWifiDeviceCommandType    `json:"type"`
+}
+
+type NameWifiDeviceCommandPayload struct {
+	Name string `json:"name"`
+}
+
+type NameWifiDeviceCommandType string
+
+const (
+	NameWifiDeviceCommandTypeCmdApoNameWifiDevice NameWifiDeviceCommandType = "CMD_APO_NAME_WIFI_DEVICE"
+)
+
+type NodesV1 struct {
+	Door                 DoorNode                 `json:"door"`
+	Fan                  FanNode                  `json:"fan"`
+	HeatingElements      HeatingElementsNode      `json:"heatingElements"`
+	Lamp                 LampNode                 `json:"lamp"`
+	SteamGenerators      SteamGeneratorsNode      `json:"steamGenerators"`
+	TemperatureBulbs     TemperatureBulbsNodeV1   `json:"temperatureBulbs"`
+	TemperatureProbe     TemperatureProbeNodeV1   `json:"temperatureProbe"`
+	Timer                TimerNodeV1              `json:"timer"`
+	UserInterfaceCircuit UserInterfaceCircuitNode `json:"userInterfaceCircuit"`
+	Vent                 VentNode                 `json:"vent"`
+	WaterTank            WaterTankNodeV1          `json:"waterTank"`
+}
+
+type NodesV2 struct {
+	CavityLamp       CavityLampNode         `json:"cavityLamp"`
+	Dc12VLine        Dc12VLineNode          `json:"dc12VLine"`
+	DisplayBoard     DisplayBoardNode       `json:"displayBoard"`
+	DisplayFan       DisplayFanNode         `json:"displayFan"`
+	Door             DoorNode               `json:"door"`
+	DoorLamp         DoorLampNode           `json:"doorLamp"`
+	ExhaustFan       ExhaustFanNode         `json:"exhaustFan"`
+	ExhaustVent      ExhaustVentNode        `json:"exhaustVent"`
+	Fan              FanNodeV2              `json:"fan"`
+	HeatingElements  HeatingElementsNode    `json:"heatingElements"`
+	LedFan           LedFanNode             `json:"ledFan"`
+	PowerBoardFan    PowerBoardFanNode      `json:"powerBoardFan"`
+	SteamGenerators  SteamGeneratorsNodeV2  `json:"steamGenerators"`
+	TemperatureBulbs TemperatureBulbsNodeV2 `json:"temperatureBulbs"`
+	TemperatureProbe TemperatureProbeNodeV2 `json:"temperatureProbe"`
+	Timer            TimerNodeV2            `json:"timer"`
+	WasteWaterTank   WasteWaterTankNode     `json:"wasteWaterTank"`
+	WaterTank        WaterTankNodeV2        `json:"waterTank"`
+}
+
+type Object = map[string]interface{}
+
+type OtaUpdateMode string
+
+const (
+	OtaUpdateModeError    OtaUpdateMode = "error"
+	OtaUpdateModeReboot   OtaUpdateMode = "reboot"
+	OtaUpdateModeRollback OtaUpdateMode = "rollback"
+	OtaUpdateModeUpdate   OtaUpdateMode = "update"
+)
+
+type OtaUpdateMode_1 string
+
+const (
+	OtaUpdateMode_1Default            OtaUpdateMode_1 = "default"
+	OtaUpdateMode_1Downloading        OtaUpdateMode_1 = "downloading"
+	OtaUpdateMode_1DownloadingPartial OtaUpdateMode_1 = "downloading_partial"
+	OtaUpdateMode_1Error              OtaUpdateMode_1 = "error"
+	OtaUpdateMode_1PowerBoardUpdating OtaUpdateMode_1 = "power_board_updating"
+	OtaUpdateMode_1Reboot             OtaUpdateMode_1 = "reboot"
+	OtaUpdateMode_1Rollback           OtaUpdateMode_1 = "rollback"
+	OtaUpdateMode_1Update             OtaUpdateMode_1 = "update"
+	OtaUpdateMode_1UpdatePartial      OtaUpdateMode_1 = "update_partial"
+)
+
+type OtaUpdateV1 = OtaUpdateV1_1
+
+type OtaUpdateV1_1 struct {
+	Mode     OtaUpdateMode `json:"mode"`
+	Progress *float64      `json:"progress,omitempty"`
+}
+
+type OtaUpdateV2 = OtaUpdateV2_1
+
+type OtaUpdateV2_1 struct {
+	Mode     OtaUpdateMode_1 `json:"mode"`
+	Progress *float64        `json:"progress,omitempty"`
+}
+
+type OvenCommand struct {
+	ID      string              `json:"id"`
+	Payload *OvenCommandPayload `json:"payload,omitempty"`
+	Type    OvenCommandType     `json:"type"`
+}
+
+type OvenCommandPayload struct {
+	AppID                            *string                             `json:"appId,omitempty"`
+	Bottom                           *OvenCommandPayloadBottom           `json:"bottom,omitempty"`
+	Command                          *string                             `json:"command,omitempty"`
+	CookID                           *string                             `json:"cookId,omitempty"`
+	CookableID                       *string                             `json:"cookableId,omitempty"`
+	CookableType                     *OvenCommandPayloadCookableType     `json:"cookableType,omitempty"`
+	CookerID                         *string                             `json:"cookerId,omitempty"`
+	Cooking                          *float64                            `json:"cooking,omitempty"`
+	Description                      *string                             `json:"description,omitempty"`
+	Do                               *StageDo                            `json:"do,omitempty"`
+	DownloadLink                     *string                             `json:"downloadLink,omitempty"`
+	Dry                              *OvenCommandPayloadDry              `json:"dry,omitempty"`
+	Entry                            *StageEntry                         `json:"entry,omitempty"`
+	Exit                             *StageExit                          `json:"exit,omitempty"`
+	ExpiresAt                        *Date                               `json:"expiresAt,omitempty"`
+	Fan                              *StageFan                           `json:"fan,omitempty"`
+	HeatingElements                  *StageHeatingElements               `json:"heatingElements,omitempty"`
+	ID                               *string                             `json:"id,omitempty"`
+	Idle                             *float64                            `json:"idle,omitempty"`
+	Initial                          *float64                            `json:"initial,omitempty"`
+	Metadata                         Record                              `json:"metadata,omitempty"`
+	Mode                             *OvenCommandPayloadMode             `json:"mode,omitempty"`
+	Name                             *string                             `json:"name,omitempty"`
+	On                               *bool                               `json:"on,omitempty"`
+	Open                             *bool                               `json:"open,omitempty"`
+	OriginSource                     *OvenCommandPayloadOriginSource     `json:"originSource,omitempty"`
+	PhotoURL                         *string                             `json:"photoUrl,omitempty"`
+	Platform                         *string                             `json:"platform,omitempty"`
+	Preset                           *string                             `json:"preset,omitempty"`
+	ProbeAdded                       *bool                               `json:"probeAdded,omitempty"`
+	RackPosition                     *float64                            `json:"rackPosition,omitempty"`
+	Rear                             *OvenCommandPayloadRear             `json:"rear,omitempty"`
+	RelativeHumidity                 *OvenCommandPayloadRelativeHumidity `json:"relativeHumidity,omitempty"`
+	Setpoint                         *OvenCommandPayloadSetpoint         `json:"setpoint,omitempty"`
+	Speed                            *float64                            `json:"speed,omitempty"`
+	Srt                              *CloudflareSrtPayload_1Srt          `json:"srt,omitempty"`
+	StageID                          *string                             `json:"stageId,omitempty"`
+	StageTransitionPendingUserAction *bool                               `json:"stageTransitionPendingUserAction,omitempty"`
+	Stages                           []OvenCommandPayloadStagesItem      `json:"stages,omitempty"`
+	SteamGenerators                  *StageSteamGenerators               `json:"steamGenerators,omitempty"`
+	SteamPercentage                  *OvenCommandPayloadSteamPercentage  `json:"steamPercentage,omitempty"`
+	SupportedAccessories             []string                            `json:"supportedAccessories,omitempty"`
+	TemperatureBulbs                 *StageTemperatureBulbs              `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe                 *StageTemperatureProbe              `json:"temperatureProbe,omitempty"`
+	TemperatureUnit                  *TemperatureUnit                    `json:"temperatureUnit,omitempty"`
+	Time                             *float64                            `json:"time,omitempty"`
+	TimeZone                         *OvenCommandPayloadTimeZone         `json:"time_zone,omitempty"`
+	Timer                            *StageTimer                         `json:"timer,omitempty"`
+	Title                            interface{}                         `json:"title,omitempty"`
+	Token                            *string                             `json:"token,omitempty"`
+	Top                              *OvenCommandPayloadTop              `json:"top,omitempty"`
+	Type                             *string                             `json:"type,omitempty"`
+	UserActionRequired               *bool                               `json:"userActionRequired,omitempty"`
+	UserID                           *string                             `json:"userId,omitempty"`
+	Vent                             *StageVent                          `json:"vent,omitempty"`
+	VideoThumbnailURL                *string                             `json:"videoThumbnailUrl,omitempty"`
+	VideoURL                         *string                             `json:"videoUrl,omitempty"`
+	Wet                              *OvenCommandPayloadWet              `json:"wet,omitempty"`
+}
+
+type OvenCommandPayloadBottom struct {
+	On bool `json:"on"`
+}
+
+type OvenCommandPayloadCookableType string
+
+const (
+	OvenCommandPayloadCookableTypeGuide  OvenCommandPayloadCookableType = "guide"
+	OvenCommandPayloadCookableTypeManual OvenCommandPayloadCookableType = "manual"
+	OvenCommandPayloadCookableTypeRecipe OvenCommandPayloadCookableType = "recipe"
+)
+
+type OvenCommandPayloadDry struct {
+	Setpoint OvenCommandPayloadDrySetpoint `json:"setpoint"`
+}
+
+type OvenCommandPayloadDrySetpoint struct {
+	Celsius    *float64 `json:"celsius,omitempty"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenCommandPayloadMode string
+
+const (
+	OvenCommandPayloadModeRelativeHumidity OvenCommandPayloadMode = "relative-humidity"
+	OvenCommandPayloadModeSteamPercentage  OvenCommandPayloadMode = "steam-percentage"
+	OvenCommandPayloadModeWet              OvenCommandPayloadMode = "wet"
+	OvenCommandPayloadModeDry              OvenCommandPayloadMode = "dry"
+)
+
+type OvenCommandPayloadOriginSource string
+
+const (
+	OvenCommandPayloadOriginSourceAndroid  OvenCommandPayloadOriginSource = "android"
+	OvenCommandPayloadOriginSourceHardware OvenCommandPayloadOriginSource = "hardware"
+	OvenCommandPayloadOriginSourceIos      OvenCommandPayloadOriginSource = "ios"
+)
+
+type OvenCommandPayloadRear struct {
+	On bool `json:"on"`
+}
+
+type OvenCommandPayloadRelativeHumidity struct {
+	Setpoint float64 `json:"setpoint"`
+}
+
+type OvenCommandPayloadSetpoint struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenCommandPayloadStagesItem struct {
+	Description                      *string                `json:"description,omitempty"`
+	Do                               *StageDo               `json:"do,omitempty"`
+	Entry                            *StageEntry            `json:"entry,omitempty"`
+	Exit                             *StageExit             `json:"exit,omitempty"`
+	Fan                              *StageFan              `json:"fan,omitempty"`
+	HeatingElements                  *StageHeatingElements  `json:"heatingElements,omitempty"`
+	ID                               string                 `json:"id"`
+	PhotoURL                         *string                `json:"photoUrl,omitempty"`
+	Preset                           *string                `json:"preset,omitempty"`
+	ProbeAdded                       *bool                  `json:"probeAdded,omitempty"`
+	RackPosition                     *float64               `json:"rackPosition,omitempty"`
+	StageTransitionPendingUserAction *bool                  `json:"stageTransitionPendingUserAction,omitempty"`
+	SteamGenerators                  *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs                 *StageTemperatureBulbs `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe                 *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer                            *StageTimer            `json:"timer,omitempty"`
+	Title                            interface{}            `json:"title,omitempty"`
+	Type                             *StageType             `json:"type,omitempty"`
+	UserActionRequired               *bool                  `json:"userActionRequired,omitempty"`
+	Vent                             *StageVent             `json:"vent,omitempty"`
+	VideoThumbnailURL                *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL                         *string                `json:"videoUrl,omitempty"`
+}
+
+type OvenCommandPayloadSteamPercentage struct {
+	Setpoint float64 `json:"setpoint"`
+}
+
+type OvenCommandPayloadTimeZone struct {
+	Code      string  `json:"code"`
+	GmtOffset float64 `json:"gmt_offset"`
+	ID        string  `json:"id"`
+}
+
+type OvenCommandPayloadTop struct {
+	On bool `json:"on"`
+}
+
+type OvenCommandPayloadWet struct {
+	Setpoint OvenCommandPayloadWetSetpoint `json:"setpoint"`
+}
+
+type OvenCommandPayloadWetSetpoint struct {
+	Celsius    *float64 `json:"celsius,omitempty"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenCommandType string
+
+const (
+	OvenCommandTypeCmdApoSetFan                    OvenCommandType = "CMD_APO_SET_FAN"
+	OvenCommandTypeSetFan                          OvenCommandType = "setFan"
+	OvenCommandTypeCmdApoSetHeatingElements        OvenCommandType = "CMD_APO_SET_HEATING_ELEMENTS"
+	OvenCommandTypeSetHeatingElements              OvenCommandType = "setHeatingElements"
+	OvenCommandTypeCmdApoSetLamp                   OvenCommandType = "CMD_APO_SET_LAMP"
+	OvenCommandTypeSetLamp                         OvenCommandType = "setLamp"
+	OvenCommandTypeCmdApoSetLampPreference         OvenCommandType = "CMD_APO_SET_LAMP_PREFERENCE"
+	OvenCommandTypeLampPreference                  OvenCommandType = "lampPreference"
+	OvenCommandTypeCmdApoSetProbe                  OvenCommandType = "CMD_APO_SET_PROBE"
+	OvenCommandTypeSetProbe                        OvenCommandType = "setProbe"
+	OvenCommandTypeCmdApoSetSteamGenerators        OvenCommandType = "CMD_APO_SET_STEAM_GENERATORS"
+	OvenCommandTypeSetSteamGenerators              OvenCommandType = "setSteamGenerators"
+	OvenCommandTypeCmdApoSetTemperatureBulbs       OvenCommandType = "CMD_APO_SET_TEMPERATURE_BULBS"
+	OvenCommandTypeSetTemperatureBulbs             OvenCommandType = "setTemperatureBulbs"
+	OvenCommandTypeCmdApoSetTemperatureUnit        OvenCommandType = "CMD_APO_SET_TEMPERATURE_UNIT"
+	OvenCommandTypeSetTemperatureUnit              OvenCommandType = "setTemperatureUnit"
+	OvenCommandTypeCmdApoSetTimer                  OvenCommandType = "CMD_APO_SET_TIMER"
+	OvenCommandTypeSetTimer                        OvenCommandType = "setTimer"
+	OvenCommandTypeCmdApoSetVent                   OvenCommandType = "CMD_APO_SET_VENT"
+	OvenCommandTypeSetVent                         OvenCommandType = "setVent"
+	OvenCommandTypeCmdApoStart                     OvenCommandType = "CMD_APO_START"
+	OvenCommandTypeStartCook                       OvenCommandType = "startCook"
+	OvenCommandTypeCmdApoStartDescale              OvenCommandType = "CMD_APO_START_DESCALE"
+	OvenCommandTypeStartDescale                    OvenCommandType = "startDescale"
+	OvenCommandTypeCmdApoAbortDescale              OvenCommandType = "CMD_APO_ABORT_DESCALE"
+	OvenCommandTypeAbortDescale                    OvenCommandType = "abortDescale"
+	OvenCommandTypeCmdApoOta                       OvenCommandType = "CMD_APO_OTA"
+	OvenCommandTypeStartFirmwareUpdate             OvenCommandType = "startFirmwareUpdate"
+	OvenCommandTypeCmdApoStartStage                OvenCommandType = "CMD_APO_START_STAGE"
+	OvenCommandTypeStartStage                      OvenCommandType = "startStage"
+	OvenCommandTypeCmdApoStop                      OvenCommandType = "CMD_APO_STOP"
+	OvenCommandTypeStopCook                        OvenCommandType = "stopCook"
+	OvenCommandTypeCmdApoUpdateCookStage           OvenCommandType = "CMD_APO_UPDATE_COOK_STAGE"
+	OvenCommandTypeUpdateCookStage                 OvenCommandType = "updateCookStage"
+	OvenCommandTypeCmdApoUpdateCookStages          OvenCommandType = "CMD_APO_UPDATE_COOK_STAGES"
+	OvenCommandTypeUpdateCookStages                OvenCommandType = "updateCookStages"
+	OvenCommandTypeCmdApoSetReportStateRate        OvenCommandType = "CMD_APO_SET_REPORT_STATE_RATE"
+	OvenCommandTypeSetReportStateRate              OvenCommandType = "setReportStateRate"
+	OvenCommandTypeCmdApoSetReportStateRateDefault OvenCommandType = "CMD_APO_SET_REPORT_STATE_RATE_DEFAULT"
+	OvenCommandTypeSetReportStateRateDefault       OvenCommandType = "setReportStateRateDefault"
+	OvenCommandTypeCmdApoSetBoilerTime             OvenCommandType = "CMD_APO_SET_BOILER_TIME"
+	OvenCommandTypeSetBoilerTime                   OvenCommandType = "setBoilerTime"
+	OvenCommandTypeAuthTokenV2                     OvenCommandType = "AUTH_TOKEN_V2"
+	OvenCommandTypeCmdApoHealthcheck               OvenCommandType = "CMD_APO_HEALTHCHECK"
+	OvenCommandTypeCmdApoSetMetadata               OvenCommandType = "CMD_APO_SET_METADATA"
+	OvenCommandTypeCmdApoGetConfiguration          OvenCommandType = "CMD_APO_GET_CONFIGURATION"
+	OvenCommandTypeGetConfiguration                OvenCommandType = "getConfiguration"
+	OvenCommandTypeCmdApoSetConfiguration          OvenCommandType = "CMD_APO_SET_CONFIGURATION"
+	OvenCommandTypeSetConfiguration                OvenCommandType = "setConfiguration"
+	OvenCommandTypeCmdApoNameWifiDevice            OvenCommandType = "CMD_APO_NAME_WIFI_DEVICE"
+	OvenCommandTypeCmdApoDisconnect                OvenCommandType = "CMD_APO_DISCONNECT"
+	OvenCommandTypeCmdApoRequestDiagnostic         OvenCommandType = "CMD_APO_REQUEST_DIAGNOSTIC"
+	OvenCommandTypeRequestDiagnostic               OvenCommandType = "requestDiagnostic"
+	OvenCommandTypeCmdApoRegisterPushToken         OvenCommandType = "CMD_APO_REGISTER_PUSH_TOKEN"
+	OvenCommandTypeCmdApoSetTimeZone               OvenCommandType = "CMD_APO_SET_TIME_ZONE"
+	OvenCommandTypeCmdApoStartLiveStream           OvenCommandType = "CMD_APO_START_LIVE_STREAM"
+	OvenCommandTypeCmdApoStopLiveStream            OvenCommandType = "CMD_APO_STOP_LIVE_STREAM"
+)
+
+// OvenState is the state of an oven, as sent in EVENT_APO_STATE messages.
+type OvenState struct {
+	Cook             *OvenStateCook      `json:"cook,omitempty"`
+	Nodes            OvenStateNodes      `json:"nodes"`
+	State            OvenStateState      `json:"state"`
+	SystemInfo       OvenStateSystemInfo `json:"systemInfo"`
+	UpdatedTimestamp string              `json:"updatedTimestamp"`
+	Version          float64             `json:"version"`
+}
+
+type OvenStateCook struct {
+	ActiveStageID                    string                    `json:"activeStageId"`
+	ActiveStageIndex                 float64                   `json:"activeStageIndex"`
+	ActiveStageMode                  *string                   `json:"activeStageMode,omitempty"`
+	ActiveStageSecondsElapsed        *float64                  `json:"activeStageSecondsElapsed,omitempty"`
+	ActiveStageStartedTimestamp      *string                   `json:"activeStageStartedTimestamp,omitempty"`
+	CookID                           string                    `json:"cookId"`
+	CookableType                     *string                   `json:"cookableType,omitempty"`
+	OriginSource                     *string                   `json:"originSource,omitempty"`
+	SecondsElapsed                   *float64                  `json:"secondsElapsed,omitempty"`
+	StageTransitionPendingUserAction bool                      `json:"stageTransitionPendingUserAction"`
+	Stages                           []OvenStateCookStagesItem `json:"stages"`
+	StartedTimestamp                 *string                   `json:"startedTimestamp,omitempty"`
+}
+
+type OvenStateCookStagesItem struct {
+	Description                      *string                `json:"description,omitempty"`
+	Do                               *StageDo               `json:"do,omitempty"`
+	Entry                            *StageEntry            `json:"entry,omitempty"`
+	Exit                             *StageExit             `json:"exit,omitempty"`
+	Fan                              *StageFan              `json:"fan,omitempty"`
+	HeatingElements                  *StageHeatingElements  `json:"heatingElements,omitempty"`
+	ID                               string                 `json:"id"`
+	PhotoURL                         *string                `json:"photoUrl,omitempty"`
+	Preset                           *string                `json:"preset,omitempty"`
+	ProbeAdded                       *bool                  `json:"probeAdded,omitempty"`
+	RackPosition                     *float64               `json:"rackPosition,omitempty"`
+	StageTransitionPendingUserAction *bool                  `json:"stageTransitionPendingUserAction,omitempty"`
+	SteamGenerators                  *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs                 *StageTemperatureBulbs `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe                 *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer                            *StageTimer            `json:"timer,omitempty"`
+	Title                            interface{}            `json:"title,omitempty"`
+	Type                             *StageType             `json:"type,omitempty"`
+	UserActionRequired               *bool                  `json:"userActionRequired,omitempty"`
+	Vent                             *StageVent             `json:"vent,omitempty"`
+	VideoThumbnailURL                *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL                         *string                `json:"videoUrl,omitempty"`
+}
+
+type OvenStateNodes struct {
+	CavityLamp           *CavityLampNode                `json:"cavityLamp,omitempty"`
+	Dc12VLine            *Dc12VLineNode                 `json:"dc12VLine,omitempty"`
+	DisplayBoard         *DisplayBoardNode              `json:"displayBoard,omitempty"`
+	DisplayFan           *DisplayFanNode                `json:"displayFan,omitempty"`
+	Door                 DoorNode                       `json:"door"`
+	DoorLamp             *DoorLampNode                  `json:"doorLamp,omitempty"`
+	ExhaustFan           *ExhaustFanNode                `json:"exhaustFan,omitempty"`
+	ExhaustVent          *ExhaustVentNode               `json:"exhaustVent,omitempty"`
+	Fan                  OvenStateNodesFan              `json:"fan"`
+	HeatingElements      HeatingElementsNode            `json:"heatingElements"`
+	Lamp                 *LampNode                      `json:"lamp,omitempty"`
+	LedFan               *LedFanNode                    `json:"ledFan,omitempty"`
+	PowerBoardFan        *PowerBoardFanNode             `json:"powerBoardFan,omitempty"`
+	SteamGenerators      OvenStateNodesSteamGenerators  `json:"steamGenerators"`
+	TemperatureBulbs     OvenStateNodesTemperatureBulbs `json:"temperatureBulbs"`
+	TemperatureProbe     OvenStateNodesTemperatureProbe `json:"temperatureProbe"`
+	Timer                OvenStateNodesTimer            `json:"timer"`
+	UserInterfaceCircuit *UserInterfaceCircuitNode      `json:"userInterfaceCircuit,omitempty"`
+	Vent                 *VentNode                      `json:"vent,omitempty"`
+	WasteWaterTank       *WasteWaterTankNode            `json:"wasteWaterTank,omitempty"`
+	WaterTank            OvenStateNodesWaterTank        `json:"waterTank"`
+}
+
+type OvenStateNodesFan struct {
+	Failed bool        `json:"failed"`
+	Speed  interface{} `json:"speed"`
+}
+
+type OvenStateNodesSteamGenerators struct {
+	Boiler           OvenStateNodesSteamGeneratorsBoiler           `json:"boiler"`
+	Evaporator       OvenStateNodesSteamGeneratorsEvaporator       `json:"evaporator"`
+	Mode             OvenStateNodesSteamGeneratorsMode             `json:"mode"`
+	RelativeHumidity *SteamGeneratorsNodeRelativeHumidity          `json:"relativeHumidity,omitempty"`
+	SteamPercentage  *OvenStateNodesSteamGeneratorsSteamPercentage `json:"steamPercentage,omitempty"`
+}
+
+type OvenStateNodesSteamGeneratorsBoiler struct {
+	Celsius                       float64 `json:"celsius"`
+	Dc12VInletPumpStatus          *string `json:"dc12VInletPumpStatus,omitempty"`
+	Dc12VOutletValveDescaleStatus *string `json:"dc12VOutletValveDescaleStatus,omitempty"`
+	DescaleRequired               bool    `json:"descaleRequired"`
+	Dosed                         bool    `json:"dosed"`
+	Failed                        bool    `json:"failed"`
+	NtcConnected                  *bool   `json:"ntcConnected,omitempty"`
+	Overheated                    *bool   `json:"overheated,omitempty"`
+	Watts                         float64 `json:"watts"`
+}
+
+type OvenStateNodesSteamGeneratorsEvaporator struct {
+	Celsius      float64 `json:"celsius"`
+	Failed       bool    `json:"failed"`
+	NtcConnected *bool   `json:"ntcConnected,omitempty"`
+	Overheated   *bool   `json:"overheated,omitempty"`
+	Watts        float64 `json:"watts"`
+}
+
+type OvenStateNodesSteamGeneratorsMode string
+
+const (
+	OvenStateNodesSteamGeneratorsModeRelativeHumidity OvenStateNodesSteamGeneratorsMode = "relative-humidity"
+	OvenStateNodesSteamGeneratorsModeSteamPercentage  OvenStateNodesSteamGeneratorsMode = "steam-percentage"
+	OvenStateNodesSteamGeneratorsModeIdle             OvenStateNodesSteamGeneratorsMode = "idle"
+)
+
+type OvenStateNodesSteamGeneratorsSteamPercentage struct {
+	Current  *float64 `json:"current,omitempty"`
+	Setpoint float64  `json:"setpoint"`
+}
+
+type OvenStateNodesTemperatureBulbs struct {
+	Dry       *OvenStateNodesTemperatureBulbsDry       `json:"dry,omitempty"`
+	DryBottom *OvenStateNodesTemperatureBulbsDryBottom `json:"dryBottom,omitempty"`
+	DryTop    *OvenStateNodesTemperatureBulbsDryTop    `json:"dryTop,omitempty"`
+	Mode      OvenStateNodesTemperatureBulbsMode       `json:"mode"`
+	Wet       *OvenStateNodesTemperatureBulbsWet       `json:"wet,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsDry struct {
+	Current  OvenStateNodesTemperatureBulbsDryCurrent   `json:"current"`
+	Setpoint *OvenStateNodesTemperatureBulbsDrySetpoint `json:"setpoint,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsDryBottom struct {
+	Current      OvenStateNodesTemperatureBulbsDryBottomCurrent `json:"current"`
+	NtcConnected *bool                                          `json:"ntcConnected,omitempty"`
+	Overheated   *bool                                          `json:"overheated,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsDryBottomCurrent struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsDryCurrent struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsDrySetpoint struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsDryTop struct {
+	Current      OvenStateNodesTemperatureBulbsDryTopCurrent `json:"current"`
+	NtcConnected *bool                                       `json:"ntcConnected,omitempty"`
+	Overheated   *bool                                       `json:"overheated,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsDryTopCurrent struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsMode string
+
+const (
+	OvenStateNodesTemperatureBulbsModeWet OvenStateNodesTemperatureBulbsMode = "wet"
+	OvenStateNodesTemperatureBulbsModeDry OvenStateNodesTemperatureBulbsMode = "dry"
+)
+
+type OvenStateNodesTemperatureBulbsWet struct {
+	Current          OvenStateNodesTemperatureBulbsWetCurrent   `json:"current"`
+	Dc12VInletStatus *string                                    `json:"dc12VInletStatus,omitempty"`
+	DoseFailed       *bool                                      `json:"doseFailed,omitempty"`
+	Dosed            bool                                       `json:"dosed"`
+	NtcConnected     *bool                                      `json:"ntcConnected,omitempty"`
+	Setpoint         *OvenStateNodesTemperatureBulbsWetSetpoint `json:"setpoint,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsWetCurrent struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenStateNodesTemperatureBulbsWetSetpoint struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenStateNodesTemperatureProbe struct {
+	Connected    bool                                    `json:"connected"`
+	Current      *OvenStateNodesTemperatureProbeCurrent  `json:"current,omitempty"`
+	NtcConnected *bool                                   `json:"ntcConnected,omitempty"`
+	Overheated   *bool                                   `json:"overheated,omitempty"`
+	Setpoint     *OvenStateNodesTemperatureProbeSetpoint `json:"setpoint,omitempty"`
+}
+
+type OvenStateNodesTemperatureProbeCurrent struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenStateNodesTemperatureProbeSetpoint struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type OvenStateNodesTimer struct {
+	Current            *float64                `json:"current,omitempty"`
+	Initial            *float64                `json:"initial,omitempty"`
+	Mode               OvenStateNodesTimerMode `json:"mode"`
+	StartedAtTimestamp *string                 `json:"startedAtTimestamp,omitempty"`
+}
+
+type OvenStateNodesTimerMode string
+
+const (
+	OvenStateNodesTimerModeIdle     OvenStateNodesTimerMode = "idle"
+	OvenStateNodesTimerModePaused   OvenStateNodesTimerMode = "paused"
+	OvenStateNodesTimerModeRunning  OvenStateNodesTimerMode = "running"
+	OvenStateNodesTimerModeComplete OvenStateNodesTimerMode = "complete"
+)
+
+type OvenStateNodesWaterTank struct {
+	Empty   bool  `json:"empty"`
+	Low     *bool `json:"low,omitempty"`
+	Removed *bool `json:"removed,omitempty"`
+}
+
+type OvenStateState struct {
+	CavityOverheated    *bool           `json:"cavityOverheated,omitempty"`
+	Mode                StateV1Mode     `json:"mode"`
+	ProcessedCommandIds []string        `json:"processedCommandIds"`
+	TemperatureUnit     TemperatureUnit `json:"temperatureUnit"`
+}
+
+type OvenStateSystemInfo struct {
+	BetaFeature               *string                       `json:"betaFeature,omitempty"`
+	DeviceID                  *string                       `json:"deviceId,omitempty"`
+	FirmwareUpdatedTimestamp  string                        `json:"firmwareUpdatedTimestamp"`
+	FirmwareVersion           string                        `json:"firmwareVersion"`
+	HardwareVersion           string                        `json:"hardwareVersion"`
+	LastConnectedTimestamp    string                        `json:"lastConnectedTimestamp"`
+	LastDisconnectedTimestamp string                        `json:"lastDisconnectedTimestamp"`
+	Online                    bool                          `json:"online"`
+	OtaUpdate                 *OvenStateSystemInfoOtaUpdate `json:"otaUpdate,omitempty"`
+	PowerHertz                float64                       `json:"powerHertz"`
+	PowerMains                float64                       `json:"powerMains"`
+	ReleaseTrack              *string                       `json:"releaseTrack,omitempty"`
+	TriacsFailed              *bool                         `json:"triacsFailed,omitempty"`
+	UiFirmwareVersion         *string                       `json:"uiFirmwareVersion,omitempty"`
+	UiHardwareVersion         *string                       `json:"uiHardwareVersion,omitempty"`
+}
+
+type OvenStateSystemInfoOtaUpdate struct {
+	Mode     OvenStateSystemInfoOtaUpdateMode `json:"mode"`
+	Progress *float64                         `json:"progress,omitempty"`
+}
+
+type OvenStateSystemInfoOtaUpdateMode string
+
+const (
+	OvenStateSystemInfoOtaUpdateModeError              OvenStateSystemInfoOtaUpdateMode = "error"
+	OvenStateSystemInfoOtaUpdateModeReboot             OvenStateSystemInfoOtaUpdateMode = "reboot"
+	OvenStateSystemInfoOtaUpdateModeRollback           OvenStateSystemInfoOtaUpdateMode = "rollback"
+	OvenStateSystemInfoOtaUpdateModeUpdate             OvenStateSystemInfoOtaUpdateMode = "update"
+	OvenStateSystemInfoOtaUpdateModeDefault            OvenStateSystemInfoOtaUpdateMode = "default"
+	OvenStateSystemInfoOtaUpdateModeDownloading        OvenStateSystemInfoOtaUpdateMode = "downloading"
+	OvenStateSystemInfoOtaUpdateModeDownloadingPartial OvenStateSystemInfoOtaUpdateMode = "downloading_partial"
+	OvenStateSystemInfoOtaUpdateModePowerBoardUpdating OvenStateSystemInfoOtaUpdateMode = "power_board_updating"
+	OvenStateSystemInfoOtaUpdateModeUpdatePartial      OvenStateSystemInfoOtaUpdateMode = "update_partial"
+)
+
+type OvenStateV1 struct {
+	Cook             *CookV1      `json:"cook,omitempty"`
+	Nodes            NodesV1      `json:"nodes"`
+	State            StateV1      `json:"state"`
+	SystemInfo       SystemInfoV1 `json:"systemInfo"`
+	UpdatedTimestamp string       `json:"updatedTimestamp"`
+	Version          float64      `json:"version"`
+}
+
+type OvenStateV2 struct {
+	Cook             *CookV2      `json:"cook,omitempty"`
+	Nodes            NodesV2      `json:"nodes"`
+	State            StateV2      `json:"state"`
+	SystemInfo       SystemInfoV2 `json:"systemInfo"`
+	UpdatedTimestamp string       `json:"updatedTimestamp"`
+	Version          float64      `json:"version"`
+}
+
+type PairAlexa struct {
+	ID      string                               `json:"id"`
+	Payload PairAlexaPayload                     `json:"payload"`
+	Type    MultiUserCommandTypeCmdUserPairAlexa `json:"type"`
+}
+
+type PairAlexaPayload struct {
+	Code string `json:"code"`
+}
+
+type PowerBoardFanNode struct {
+	Dc12VStatus string `json:"dc12VStatus"`
+	On          bool   `json:"on"`
+}
+
+type Record = Object
+
+type RegisterPushTokenCommand struct {
+	ID      string                          `json:"id"`
+	Payload RegisterPushTokenCommandPayload `json:"payload"`
+	Type    RegisterPushTokenCommandType    `json:"type"`
+}
+
+type RegisterPushTokenCommandPayload struct {
+	AppID    string `json:"appId"`
+	Platform string `json:"platform"`
+	Token    string `json:"token"`
+}
+
+type RegisterPushTokenCommandType string
+
+const (
+	RegisterPushTokenCommandTypeCmdApoRegisterPushToken RegisterPushTokenCommandType = "CMD_APO_REGISTER_PUSH_TOKEN"
+)
+
+type RelativeHumiditySteamGeneratorNode struct {
+	Mode             RelativeHumiditySteamGeneratorNodeMode `json:"mode"`
+	RelativeHumidity SteamGeneratorsNodeRelativeHumidity    `json:"relativeHumidity"`
+}
+
+type RelativeHumiditySteamGeneratorNodeMode string
+
+const (
+	RelativeHumiditySteamGeneratorNodeModeRelativeHumidity RelativeHumiditySteamGeneratorNodeMode = "relative-humidity"
+)
+
+type RelativeHumiditySteamGeneratorNodeV2 struct {
+	Mode             RelativeHumiditySteamGeneratorNodeV2Mode `json:"mode"`
+	RelativeHumidity SteamGeneratorsNodeV2RelativeHumidity    `json:"relativeHumidity"`
+}
+
+type RelativeHumiditySteamGeneratorNodeV2Mode string
+
+const (
+	RelativeHumiditySteamGeneratorNodeV2ModeRelativeHumidity RelativeHumiditySteamGeneratorNodeV2Mode = "relative-humidity"
+)
+
+type RequestDiagnosticsCommand struct {
+	ID      string                           `json:"id"`
+	Payload RequestDiagnosticsCommandPayload `json:"payload"`
+	Type    RequestDiagnosticsCommandType    `json:"type"`
+}
+
+type RequestDiagnosticsCommandPayload struct {
+	Command string `json:"command"`
+}
+
+type RequestDiagnosticsCommandType string
+
+const (
+	RequestDiagnosticsCommandTypeCmdApoRequestDiagnostic RequestDiagnosticsCommandType = "CMD_APO_REQUEST_DIAGNOSTIC"
+	RequestDiagnosticsCommandTypeRequestDiagnostic       RequestDiagnosticsCommandType = "requestDiagnostic"
+)
+
+type SetBoilerTime struct {
+	ID      string               `json:"id"`
+	Payload SetBoilerTimePayload `json:"payload"`
+	Type    SetBoilerTimeType    `json:"type"`
+}
+
+type SetBoilerTimePayload struct {
+	Time float64 `json:"time"`
+}
+
+type SetBoilerTimeType string
+
+const (
+	SetBoilerTimeTypeCmdApoSetBoilerTime SetBoilerTimeType = "CMD_APO_SET_BOILER_TIME"
+	SetBoilerTimeTypeSetBoilerTime       SetBoilerTimeType = "setBoilerTime"
+)
+
+type SetConfiguration struct {
+	ID      string                  `json:"id"`
+	Payload SetConfigurationPayload `json:"payload"`
+	Type    SetConfigurationType    `json:"type"`
+}
+
+type SetConfigurationPayload struct {
+	ExpiresAt Date   `json:"expiresAt"`
+	Token     string `json:"token"`
+}
+
+type SetConfigurationType string
+
+const (
+	SetConfigurationTypeCmdApoSetConfiguration SetConfigurationType = "CMD_APO_SET_CONFIGURATION"
+	SetConfigurationTypeSetConfiguration       SetConfigurationType = "setConfiguration"
+)
+
+type SetFanCommand struct {
+	ID      string               `json:"id"`
+	Payload SetFanCommandPayload `json:"payload"`
+	Type    SetFanCommandType    `json:"type"`
+}
+
+type SetFanCommandPayload struct {
+	Speed float64 `json:"speed"`
+}
+
+type SetFanCommandType string
+
+const (
+	SetFanCommandTypeCmdApoSetFan SetFanCommandType = "CMD_APO_SET_FAN"
+	SetFanCommandTypeSetFan       SetFanCommandType = "setFan"
+)
+
+type SetHeatingElementsCommand struct {
+	ID      string                           `json:"id"`
+	Payload SetHeatingElementsCommandPayload `json:"payload"`
+	Type    SetHeatingElementsCommandType    `json:"type"`
+}
+
+type SetHeatingElementsCommandPayload struct {
+	Bottom OvenCommandPayloadBottom `json:"bottom"`
+	Rear   OvenCommandPayloadRear   `json:"rear"`
+	Top    OvenCommandPayloadTop    `json:"top"`
+}
+
+type SetHeatingElementsCommandType string
+
+const (
+	SetHeatingElementsCommandTypeCmdApoSetHeatingElements SetHeatingElementsCommandType = "CMD_APO_SET_HEATING_ELEMENTS"
+	SetHeatingElementsCommandTypeSetHeatingElements       SetHeatingElementsCommandType = "setHeatingElements"
+)
+
+type SetLampCommand struct {
+	ID      string                `json:"id"`
+	Payload SetLampCommandPayload `json:"payload"`
+	Type    SetLampCommandType    `json:"type"`
+}
+
+type SetLampCommandPayload struct {
+	On bool `json:"on"`
+}
+
+type SetLampCommandType string
+
+const (
+	SetLampCommandTypeCmdApoSetLamp SetLampCommandType = "CMD_APO_SET_LAMP"
+	SetLampCommandTypeSetLamp       SetLampCommandType = "setLamp"
+)
+
+type SetLampPreferenceCommand struct {
+	ID      string                          `json:"id"`
+	Payload SetLampPreferenceCommandPayload `json:"payload"`
+	Type    SetLampPreferenceCommandType    `json:"type"`
+}
+
+type SetLampPreferenceCommandPayload struct {
+	On bool `json:"on"`
+}
+
+type SetLampPreferenceCommandType string
+
+const (
+	SetLampPreferenceCommandTypeCmdApoSetLampPreference SetLampPreferenceCommandType = "CMD_APO_SET_LAMP_PREFERENCE"
+	SetLampPreferenceCommandTypeLampPreference          SetLampPreferenceCommandType = "lampPreference"
+)
+
+type SetMetadataCommand struct {
+	ID      string                    `json:"id"`
+	Payload SetMetadataCommandPayload `json:"payload"`
+	Type    SetMetadataCommandType    `json:"type"`
+}
+
+type SetMetadataCommandPayload struct {
+	Metadata Record `json:"metadata"`
+}
+
+type SetMetadataCommandType string
+
+const (
+	SetMetadataCommandTypeCmdApoSetMetadata SetMetadataCommandType = "CMD_APO_SET_METADATA"
+)
+
+type SetProbeCommand struct {
+	ID      string                 `json:"id"`
+	Payload SetProbeCommandPayload `json:"payload"`
+	Type    SetProbeCommandType    `json:"type"`
+}
+
+type SetProbeCommandPayload struct {
+	Setpoint SetProbeCommandPayloadSetpoint `json:"setpoint"`
+}
+
+type SetProbeCommandPayloadSetpoint struct {
+	Celsius    float64  `json:"celsius"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type SetProbeCommandPayloadV1 struct {
+	Setpoint SetProbeCommandPayloadV1Setpoint `json:"setpoint"`
+}
+
+type SetProbeCommandPayloadV1Setpoint struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type SetProbeCommandPayloadV2 struct {
+	Setpoint SetProbeCommandPayloadV2Setpoint `json:"setpoint"`
+}
+
+type SetProbeCommandPayloadV2Setpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type SetProbeCommandType string
+
+const (
+	SetProbeCommandTypeCmdApoSetProbe SetProbeCommandType = "CMD_APO_SET_PROBE"
+	SetProbeCommandTypeSetProbe       SetProbeCommandType = "setProbe"
+)
+
+type SetReportStateRate struct {
+	ID      string                    `json:"id"`
+	Payload SetReportStateRatePayload `json:"payload"`
+	Type    SetReportStateRateType    `json:"type"`
+}
+
+type SetReportStateRateDefault struct {
+	ID   string                        `json:"id"`
+	Type SetReportStateRateDefaultType `json:"type"`
+}
+
+type SetReportStateRateDefaultPayload struct{}
+
+type SetReportStateRateDefaultType string
+
+const (
+	SetReportStateRateDefaultTypeCmdApoSetReportStateRateDefault SetReportStateRateDefaultType = "CMD_APO_SET_REPORT_STATE_RATE_DEFAULT"
+	SetReportStateRateDefaultTypeSetReportStateRateDefault       SetReportStateRateDefaultType = "setReportStateRateDefault"
+)
+
+type SetReportStateRatePayload struct {
+	Cooking float64 `json:"cooking"`
+	Idle    float64 `json:"idle"`
+}
+
+type SetReportStateRateType string
+
+const (
+	SetReportStateRateTypeCmdApoSetReportStateRate SetReportStateRateType = "CMD_APO_SET_REPORT_STATE_RATE"
+	SetReportStateRateTypeSetReportStateRate       SetReportStateRateType = "setReportStateRate"
+)
+
+type SetSteamGeneratorsCommand struct {
+	ID      string                           `json:"id"`
+	Payload SetSteamGeneratorsCommandPayload `json:"payload"`
+	Type    SetSteamGeneratorsCommandType    `json:"type"`
+}
+
+type SetSteamGeneratorsCommandPayload struct {
+	Mode             SetSteamGeneratorsCommandPayloadMode `json:"mode"`
+	RelativeHumidity *OvenCommandPayloadRelativeHumidity  `json:"relativeHumidity,omitempty"`
+	SteamPercentage  *OvenCommandPayloadSteamPercentage   `json:"steamPercentage,omitempty"`
+}
+
+type SetSteamGeneratorsCommandPayload2 struct {
+	Mode             SetSteamGeneratorsCommandPayload2Mode `json:"mode"`
+	RelativeHumidity OvenCommandPayloadRelativeHumidity    `json:"relativeHumidity"`
+}
+
+type SetSteamGeneratorsCommandPayload2Mode string
+
+const (
+	SetSteamGeneratorsCommandPayload2ModeRelativeHumidity SetSteamGeneratorsCommandPayload2Mode = "relative-humidity"
+)
+
+type SetSteamGeneratorsCommandPayload3 struct {
+	Mode            SetSteamGeneratorsCommandPayload3Mode `json:"mode"`
+	SteamPercentage OvenCommandPayloadSteamPercentage     `json:"steamPercentage"`
+}
+
+type SetSteamGeneratorsCommandPayload3Mode string
+
+const (
+	SetSteamGeneratorsCommandPayload3ModeSteamPercentage SetSteamGeneratorsCommandPayload3Mode = "steam-percentage"
+)
+
+type SetSteamGeneratorsCommandPayloadMode string
+
+const (
+	SetSteamGeneratorsCommandPayloadModeRelativeHumidity SetSteamGeneratorsCommandPayloadMode = "relative-humidity"
+	SetSteamGeneratorsCommandPayloadModeSteamPercentage  SetSteamGeneratorsCommandPayloadMode = "steam-percentage"
+)
+
+type SetSteamGeneratorsCommandType string
+
+const (
+	SetSteamGeneratorsCommandTypeCmdApoSetSteamGenerators SetSteamGeneratorsCommandType = "CMD_APO_SET_STEAM_GENERATORS"
+	SetSteamGeneratorsCommandTypeSetSteamGenerators       SetSteamGeneratorsCommandType = "setSteamGenerators"
+)
+
+type SetTemperatureBulbsCommand struct {
+	ID      string                            `json:"id"`
+	Payload SetTemperatureBulbsCommandPayload `json:"payload"`
+	Type    SetTemperatureBulbsCommandType    `json:"type"`
+}
+
+type SetTemperatureBulbsCommandPayload struct {
+	Dry  *SetTemperatureBulbsCommandPayloadDry `json:"dry,omitempty"`
+	Mode SetTemperatureBulbsCommandPayloadMode `json:"mode"`
+	Wet  *SetTemperatureBulbsCommandPayloadWet `json:"wet,omitempty"`
+}
+
+type SetTemperatureBulbsCommandPayloadDry struct {
+	Setpoint SetTemperatureBulbsCommandPayloadDrySetpoint `json:"setpoint"`
+}
+
+type SetTemperatureBulbsCommandPayloadDrySetpoint struct {
+	Celsius    *float64 `json:"celsius,omitempty"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type SetTemperatureBulbsCommandPayloadDryV1 struct {
+	Dry  SetTemperatureBulbsCommandPayloadDryV1Dry  `json:"dry"`
+	Mode SetTemperatureBulbsCommandPayloadDryV1Mode `json:"mode"`
+}
+
+type SetTemperatureBulbsCommandPayloadDryV1Dry struct {
+	Setpoint SetTemperatureBulbsCommandPayloadDryV1DrySetpoint `json:"setpoint"`
+}
+
+type SetTemperatureBulbsCommandPayloadDryV1DrySetpoint struct {
+	Celsius    *float64 `json:"celsius,omitempty"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type SetTemperatureBulbsCommandPayloadDryV1Mode string
+
+const (
+	SetTemperatureBulbsCommandPayloadDryV1ModeDry SetTemperatureBulbsCommandPayloadDryV1Mode = "dry"
+)
+
+type SetTemperatureBulbsCommandPayloadDryV2 struct {
+	Dry  SetTemperatureBulbsCommandPayloadDryV2Dry  `json:"dry"`
+	Mode SetTemperatureBulbsCommandPayloadDryV2Mode `json:"mode"`
+}
+
+type SetTemperatureBulbsCommandPayloadDryV2Dry struct {
+	Setpoint SetTemperatureBulbsCommandPayloadDryV2DrySetpoint `json:"setpoint"`
+}
+
+type SetTemperatureBulbsCommandPayloadDryV2DrySetpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type SetTemperatureBulbsCommandPayloadDryV2Mode string
+
+const (
+	SetTemperatureBulbsCommandPayloadDryV2ModeDry SetTemperatureBulbsCommandPayloadDryV2Mode = "dry"
+)
+
+type SetTemperatureBulbsCommandPayloadMode string
+
+const (
+	SetTemperatureBulbsCommandPayloadModeWet SetTemperatureBulbsCommandPayloadMode = "wet"
+	SetTemperatureBulbsCommandPayloadModeDry SetTemperatureBulbsCommandPayloadMode = "dry"
+)
+
+type SetTemperatureBulbsCommandPayloadWet struct {
+	Setpoint SetTemperatureBulbsCommandPayloadWetSetpoint `json:"setpoint"`
+}
+
+type SetTemperatureBulbsCommandPayloadWetSetpoint struct {
+	Celsius    *float64 `json:"celsius,omitempty"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type SetTemperatureBulbsCommandPayloadWetV1 struct {
+	Mode SetTemperatureBulbsCommandPayloadWetV1Mode `json:"mode"`
+	Wet  SetTemperatureBulbsCommandPayloadWetV1Wet  `json:"wet"`
+}
+
+type SetTemperatureBulbsCommandPayloadWetV1Mode string
+
+const (
+	SetTemperatureBulbsCommandPayloadWetV1ModeWet SetTemperatureBulbsCommandPayloadWetV1Mode = "wet"
+)
+
+type SetTemperatureBulbsCommandPayloadWetV1Wet struct {
+	Setpoint SetTemperatureBulbsCommandPayloadWetV1WetSetpoint `json:"setpoint"`
+}
+
+type SetTemperatureBulbsCommandPayloadWetV1WetSetpoint struct {
+	Celsius    *float64 `json:"celsius,omitempty"`
+	Fahrenheit *float64 `json:"fahrenheit,omitempty"`
+}
+
+type SetTemperatureBulbsCommandPayloadWetV2 struct {
+	Mode SetTemperatureBulbsCommandPayloadWetV2Mode `json:"mode"`
+	Wet  SetTemperatureBulbsCommandPayloadWetV2Wet  `json:"wet"`
+}
+
+type SetTemperatureBulbsCommandPayloadWetV2Mode string
+
+const (
+	SetTemperatureBulbsCommandPayloadWetV2ModeWet SetTemperatureBulbsCommandPayloadWetV2Mode = "wet"
+)
+
+type SetTemperatureBulbsCommandPayloadWetV2Wet struct {
+	Setpoint SetTemperatureBulbsCommandPayloadWetV2WetSetpoint `json:"setpoint"`
+}
+
+type SetTemperatureBulbsCommandPayloadWetV2WetSetpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type SetTemperatureBulbsCommandType string
+
+const (
+	SetTemperatureBulbsCommandTypeCmdApoSetTemperatureBulbs SetTemperatureBulbsCommandType = "CMD_APO_SET_TEMPERATURE_BULBS"
+	SetTemperatureBulbsCommandTypeSetTemperatureBulbs       SetTemperatureBulbsCommandType = "setTemperatureBulbs"
+)
+
+type SetTemperatureUnitCommand struct {
+	ID      string                           `json:"id"`
+	Payload SetTemperatureUnitCommandPayload `json:"payload"`
+	Type    SetTemperatureUnitCommandType    `json:"type"`
+}
+
+type SetTemperatureUnitCommandPayload struct {
+	TemperatureUnit TemperatureUnit `json:"temperatureUnit"`
+}
+
+type SetTemperatureUnitCommandType string
+
+const (
+	SetTemperatureUnitCommandTypeCmdApoSetTemperatureUnit SetTemperatureUnitCommandType = "CMD_APO_SET_TEMPERATURE_UNIT"
+	SetTemperatureUnitCommandTypeSetTemperatureUnit       SetTemperatureUnitCommandType = "setTemperatureUnit"
+)
+
+type SetTimeZoneCommand struct {
+	ID      string                    `json:"id"`
+	Payload SetTimeZoneCommandPayload `json:"payload"`
+	Type    SetTimeZoneCommandType    `json:"type"`
+}
+
+type SetTimeZoneCommandPayload struct {
+	TimeZone OvenCommandPayloadTimeZone `json:"time_zone"`
+}
+
+type SetTimeZoneCommandType string
+
+const (
+	SetTimeZoneCommandTypeCmdApoSetTimeZone SetTimeZoneCommandType = "CMD_APO_SET_TIME_ZONE"
+)
+
+type SetTimerCommand struct {
+	ID      string                 `json:"id"`
+	Payload SetTimerCommandPayload `json:"payload"`
+	Type    SetTimerCommandType    `json:"type"`
+}
+
+type SetTimerCommandPayload struct {
+	Initial float64 `json:"initial"`
+}
+
+type SetTimerCommandType string
+
+const (
+	SetTimerCommandTypeCmdApoSetTimer SetTimerCommandType = "CMD_APO_SET_TIMER"
+	SetTimerCommandTypeSetTimer       SetTimerCommandType = "setTimer"
+)
+
+type SetVentCommand struct {
+	ID      string                `json:"id"`
+	Payload SetVentCommandPayload `json:"payload"`
+	Type    SetVentCommandType    `json:"type"`
+}
+
+type SetVentCommandPayload struct {
+	Open bool `json:"open"`
+}
+
+type SetVentCommandType string
+
+const (
+	SetVentCommandTypeCmdApoSetVent SetVentCommandType = "CMD_APO_SET_VENT"
+	SetVentCommandTypeSetVent       SetVentCommandType = "setVent"
+)
+
+type Stage struct {
+	Description                      *string                `json:"description,omitempty"`
+	Do                               *StageDo               `json:"do,omitempty"`
+	Entry                            *StageEntry            `json:"entry,omitempty"`
+	Exit                             *StageExit             `json:"exit,omitempty"`
+	Fan                              *StageFan              `json:"fan,omitempty"`
+	HeatingElements                  *StageHeatingElements  `json:"heatingElements,omitempty"`
+	ID                               string                 `json:"id"`
+	PhotoURL                         *string                `json:"photoUrl,omitempty"`
+	Preset                           *string                `json:"preset,omitempty"`
+	ProbeAdded                       *bool                  `json:"probeAdded,omitempty"`
+	RackPosition                     *float64               `json:"rackPosition,omitempty"`
+	StageTransitionPendingUserAction *bool                  `json:"stageTransitionPendingUserAction,omitempty"`
+	SteamGenerators                  *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs                 *StageTemperatureBulbs `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe                 *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer                            *StageTimer            `json:"timer,omitempty"`
+	Title                            interface{}            `json:"title,omitempty"`
+	Type                             *StageType             `json:"type,omitempty"`
+	UserActionRequired               *bool                  `json:"userActionRequired,omitempty"`
+	Vent                             *StageVent             `json:"vent,omitempty"`
+	VideoThumbnailURL                *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL                         *string                `json:"videoUrl,omitempty"`
+}
+
+type StageConditionGroup struct {
+	And interface{} `json:"and,omitempty"`
+	Or  interface{} `json:"or,omitempty"`
+}
+
+type StageConditions = interface{}
+
+type StageConditions_1 = interface{}
+
+type StageDo struct {
+	ExhaustVent      *StageExhaustVent        `json:"exhaustVent,omitempty"`
+	Fan              StageFan_1               `json:"fan"`
+	HeatingElements  StageHeatingElements_1   `json:"heatingElements"`
+	SteamGenerators  *StageSteamGenerators_1  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs StageTemperatureBulbs_1  `json:"temperatureBulbs"`
+	TemperatureProbe *StageTemperatureProbe_2 `json:"temperatureProbe,omitempty"`
+	Timer            *StageTimer_2            `json:"timer,omitempty"`
+	Type             StageType_1              `json:"type"`
+}
+
+type StageDryTemperatureBulb struct {
+	Dry  StageTemperatureBulbsDry    `json:"dry"`
+	Mode StageDryTemperatureBulbMode `json:"mode"`
+}
+
+type StageDryTemperatureBulbMode string
+
+const (
+	StageDryTemperatureBulbModeDry StageDryTemperatureBulbMode = "dry"
+)
+
+type StageDryTemperatureBulb_1 struct {
+	Dry  StageTemperatureBulbs_1Dry    `json:"dry"`
+	Mode StageDryTemperatureBulb_1Mode `json:"mode"`
+}
+
+type StageDryTemperatureBulb_1Mode string
+
+const (
+	StageDryTemperatureBulb_1ModeDry StageDryTemperatureBulb_1Mode = "dry"
+)
+
+type StageEntry struct {
+	Conditions StageConditions `json:"conditions"`
+}
+
+type StageExhaustVent = StageExhaustVent_1
+
+type StageExhaustVent_1 struct {
+	State StageExhaustVent_1State `json:"state"`
+}
+
+type StageExhaustVent_1State string
+
+const (
+	StageExhaustVent_1StateClosed  StageExhaustVent_1State = "closed"
+	StageExhaustVent_1StateOpenMax StageExhaustVent_1State = "open-max"
+	StageExhaustVent_1StateOpenMid StageExhaustVent_1State = "open-mid"
+)
+
+type StageExit struct {
+	Conditions StageConditions_1 `json:"conditions,omitempty"`
+	UserAction *bool             `json:"userAction,omitempty"`
+}
+
+type StageFan struct {
+	Speed float64 `json:"speed"`
+}
+
+type StageFan_1 struct {
+	Speed float64 `json:"speed"`
+}
+
+type StageHeatingElements struct {
+	Bottom StageHeatingElementsBottom `json:"bottom"`
+	Rear   StageHeatingElementsRear   `json:"rear"`
+	Top    StageHeatingElementsTop    `json:"top"`
+}
+
+type StageHeatingElementsBottom struct {
+	On bool `json:"on"`
+}
+
+type StageHeatingElementsRear struct {
+	On bool `json:"on"`
+}
+
+type StageHeatingElementsTop struct {
+	On bool `json:"on"`
+}
+
+type StageHeatingElements_1 struct {
+	Bottom StageHeatingElements_1Bottom `json:"bottom"`
+	Rear   StageHeatingElements_1Rear   `json:"rear"`
+	Top    StageHeatingElements_1Top    `json:"top"`
+}
+
+type StageHeatingElements_1Bottom struct {
+	On bool `json:"on"`
+}
+
+type StageHeatingElements_1Rear struct {
+	On bool `json:"on"`
+}
+
+type StageHeatingElements_1Top struct {
+	On bool `json:"on"`
+}
+
+type StageRackPosition = float64
+
+type StageRelativeHumiditySteamGenerator struct {
+	Mode             StageRelativeHumiditySteamGeneratorMode `json:"mode"`
+	RelativeHumidity StageSteamGeneratorsRelativeHumidity    `json:"relativeHumidity"`
+}
+
+type StageRelativeHumiditySteamGeneratorMode string
+
+const (
+	StageRelativeHumiditySteamGeneratorModeRelativeHumidity StageRelativeHumiditySteamGeneratorMode = "relative-humidity"
+)
+
+type StageRelativeHumiditySteamGenerator_1 struct {
+	Mode             StageRelativeHumiditySteamGenerator_1Mode `json:"mode"`
+	RelativeHumidity StageSteamGenerators_1RelativeHumidity    `json:"relativeHumidity"`
+}
+
+type StageRelativeHumiditySteamGenerator_1Mode string
+
+const (
+	StageRelativeHumiditySteamGenerator_1ModeRelativeHumidity StageRelativeHumiditySteamGenerator_1Mode = "relative-humidity"
+)
+
+type StageSteamGenerators struct {
+	Mode             StageSteamGeneratorsMode              `json:"mode"`
+	RelativeHumidity *StageSteamGeneratorsRelativeHumidity `json:"relativeHumidity,omitempty"`
+	SteamPercentage  *StageSteamGeneratorsSteamPercentage  `json:"steamPercentage,omitempty"`
+}
+
+type StageSteamGeneratorsMode string
+
+const (
+	StageSteamGeneratorsModeRelativeHumidity StageSteamGeneratorsMode = "relative-humidity"
+	StageSteamGeneratorsModeSteamPercentage  StageSteamGeneratorsMode = "steam-percentage"
+)
+
+type StageSteamGeneratorsRelativeHumidity struct {
+	Setpoint float64 `json:"setpoint"`
+}
+
+type StageSteamGeneratorsSteamPercentage struct {
+	Setpoint float64 `json:"setpoint"`
+}
+
+type StageSteamGenerators_1 struct {
+	Mode             StageSteamGenerators_1Mode              `json:"mode"`
+	RelativeHumidity *StageSteamGenerators_1RelativeHumidity `json:"relativeHumidity,omitempty"`
+	SteamPercentage  *StageSteamGenerators_1SteamPercentage  `json:"steamPercentage,omitempty"`
+}
+
+type StageSteamGenerators_1Mode string
+
+const (
+	StageSteamGenerators_1ModeRelativeHumidity StageSteamGenerators_1Mode = "relative-humidity"
+	StageSteamGenerators_1ModeSteamPercentage  StageSteamGenerators_1Mode = "steam-percentage"
+)
+
+type StageSteamGenerators_1RelativeHumidity struct {
+	Setpoint float64 `json:"setpoint"`
+}
+
+type StageSteamGenerators_1SteamPercentage struct {
+	Setpoint float64 `json:"setpoint"`
+}
+
+type StageSteamPercentageSteamGenerator struct {
+	Mode            StageSteamPercentageSteamGeneratorMode `json:"mode"`
+	SteamPercentage StageSteamGeneratorsSteamPercentage    `json:"steamPercentage"`
+}
+
+type StageSteamPercentageSteamGeneratorMode string
+
+const (
+	StageSteamPercentageSteamGeneratorModeSteamPercentage StageSteamPercentageSteamGeneratorMode = "steam-percentage"
+)
+
+type StageSteamPercentageSteamGenerator_1 struct {
+	Mode            StageSteamPercentageSteamGenerator_1Mode `json:"mode"`
+	SteamPercentage StageSteamGenerators_1SteamPercentage    `json:"steamPercentage"`
+}
+
+type StageSteamPercentageSteamGenerator_1Mode string
+
+const (
+	StageSteamPercentageSteamGenerator_1ModeSteamPercentage StageSteamPercentageSteamGenerator_1Mode = "steam-percentage"
+)
+
+type StageTemperatureBulbs struct {
+	Dry  *StageTemperatureBulbsDry `json:"dry,omitempty"`
+	Mode StageTemperatureBulbsMode `json:"mode"`
+	Wet  *StageTemperatureBulbsWet `json:"wet,omitempty"`
+}
+
+type StageTemperatureBulbsDry struct {
+	Setpoint StageTemperatureBulbsDrySetpoint `json:"setpoint"`
+}
+
+type StageTemperatureBulbsDrySetpoint struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type StageTemperatureBulbsMode string
+
+const (
+	StageTemperatureBulbsModeDry StageTemperatureBulbsMode = "dry"
+	StageTemperatureBulbsModeWet StageTemperatureBulbsMode = "wet"
+)
+
+type StageTemperatureBulbsWet struct {
+	Setpoint StageTemperatureBulbsWetSetpoint `json:"setpoint"`
+}
+
+type StageTemperatureBulbsWetSetpoint struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type StageTemperatureBulbs_1 struct {
+	Dry  *StageTemperatureBulbs_1Dry `json:"dry,omitempty"`
+	Mode StageTemperatureBulbs_1Mode `json:"mode"`
+	Wet  *StageTemperatureBulbs_1Wet `json:"wet,omitempty"`
+}
+
+type StageTemperatureBulbs_1Dry struct {
+	Setpoint StageTemperatureBulbs_1DrySetpoint `json:"setpoint"`
+}
+
+type StageTemperatureBulbs_1DrySetpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type StageTemperatureBulbs_1Mode string
+
+const (
+	StageTemperatureBulbs_1ModeDry StageTemperatureBulbs_1Mode = "dry"
+	StageTemperatureBulbs_1ModeWet StageTemperatureBulbs_1Mode = "wet"
+)
+
+type StageTemperatureBulbs_1Wet struct {
+	Setpoint StageTemperatureBulbs_1WetSetpoint `json:"setpoint"`
+}
+
+type StageTemperatureBulbs_1WetSetpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type StageTemperatureProbe = StageTemperatureProbe_1
+
+type StageTemperatureProbe_1 struct {
+	Setpoint StageTemperatureProbe_1Setpoint `json:"setpoint"`
+}
+
+type StageTemperatureProbe_1Setpoint struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type StageTemperatureProbe_2 = StageTemperatureProbe_3
+
+type StageTemperatureProbe_3 struct {
+	Setpoint StageTemperatureProbe_3Setpoint `json:"setpoint"`
+}
+
+type StageTemperatureProbe_3Setpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type StageTimer = StageTimer_1
+
+type StageTimer_1 struct {
+	Initial float64 `json:"initial"`
+}
+
+type StageTimer_2 = StageTimer_3
+
+type StageTimer_3 struct {
+	Entry   *StageTimer_3Entry `json:"entry,omitempty"`
+	Initial float64            `json:"initial"`
+}
+
+type StageTimer_3Entry struct {
+	Conditions StageConditions `json:"conditions,omitempty"`
+}
+
+type StageType string
+
+const (
+	StageTypeCook    StageType = "cook"
+	StageTypePreheat StageType = "preheat"
+	StageTypeStop    StageType = "stop"
+)
+
+type StageType_1 string
+
+const (
+	StageType_1Cook StageType_1 = "cook"
+	StageType_1Stop StageType_1 = "stop"
+)
+
+type StageV2 struct {
+	Description                      *string     `json:"description,omitempty"`
+	Do                               StageDo     `json:"do"`
+	Entry                            StageEntry  `json:"entry"`
+	Exit                             StageExit   `json:"exit"`
+	ID                               string      `json:"id"`
+	PhotoURL                         *string     `json:"photoUrl,omitempty"`
+	Preset                           *string     `json:"preset,omitempty"`
+	RackPosition                     *float64    `json:"rackPosition,omitempty"`
+	StageTransitionPendingUserAction bool        `json:"stageTransitionPendingUserAction"`
+	Title                            interface{} `json:"title,omitempty"`
+}
+
+type StageVent struct {
+	Open bool `json:"open"`
+}
+
+type StageWetTemperatureBulb struct {
+	Mode StageWetTemperatureBulbMode `json:"mode"`
+	Wet  StageTemperatureBulbsWet    `json:"wet"`
+}
+
+type StageWetTemperatureBulbMode string
+
+const (
+	StageWetTemperatureBulbModeWet StageWetTemperatureBulbMode = "wet"
+)
+
+type StageWetTemperatureBulb_1 struct {
+	Mode StageWetTemperatureBulb_1Mode `json:"mode"`
+	Wet  StageTemperatureBulbs_1Wet    `json:"wet"`
+}
+
+type StageWetTemperatureBulb_1Mode string
+
+const (
+	StageWetTemperatureBulb_1ModeWet StageWetTemperatureBulb_1Mode = "wet"
+)
+
+type StartCookCommand struct {
+	ID      string                  `json:"id"`
+	Payload StartCookCommandPayload `json:"payload"`
+	Type    StartCookCommandType    `json:"type"`
+}
+
+type StartCookCommandPayload struct {
+	CookID       *string                             `json:"cookId,omitempty"`
+	CookableID   *string                             `json:"cookableId,omitempty"`
+	CookableType *OvenCommandPayloadCookableType     `json:"cookableType,omitempty"`
+	CookerID     *string                             `json:"cookerId,omitempty"`
+	OriginSource *OvenCommandPayloadOriginSource     `json:"originSource,omitempty"`
+	Stages       []StartCookCommandPayloadStagesItem `json:"stages"`
+	Type         *string                             `json:"type,omitempty"`
+}
+
+type StartCookCommandPayloadStagesItem struct {
+	Description                      *string                `json:"description,omitempty"`
+	Do                               *StageDo               `json:"do,omitempty"`
+	Entry                            *StageEntry            `json:"entry,omitempty"`
+	Exit                             *StageExit             `json:"exit,omitempty"`
+	Fan                              *StageFan              `json:"fan,omitempty"`
+	HeatingElements                  *StageHeatingElements  `json:"heatingElements,omitempty"`
+	ID                               string                 `json:"id"`
+	PhotoURL                         *string                `json:"photoUrl,omitempty"`
+	Preset                           *string                `json:"preset,omitempty"`
+	ProbeAdded                       *bool                  `json:"probeAdded,omitempty"`
+	RackPosition                     *float64               `json:"rackPosition,omitempty"`
+	StageTransitionPendingUserAction *bool                  `json:"stageTransitionPendingUserAction,omitempty"`
+	SteamGenerators                  *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs                 *StageTemperatureBulbs `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe                 *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer                            *StageTimer            `json:"timer,omitempty"`
+	Title                            interface{}            `json:"title,omitempty"`
+	Type                             *StageType             `json:"type,omitempty"`
+	UserActionRequired               *bool                  `json:"userActionRequired,omitempty"`
+	Vent                             *StageVent             `json:"vent,omitempty"`
+	VideoThumbnailURL                *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL                         *string                `json:"videoUrl,omitempty"`
+}
+
+type StartCookCommandPayloadV1 struct {
+	CookID *string                               `json:"cookId,omitempty"`
+	Stages []StartCookCommandPayloadV1StagesItem `json:"stages"`
+}
+
+type StartCookCommandPayloadV1StagesItem struct {
+	Description        *string                `json:"description,omitempty"`
+	Fan                *StageFan              `json:"fan,omitempty"`
+	HeatingElements    *StageHeatingElements  `json:"heatingElements,omitempty"`
+	ID                 string                 `json:"id"`
+	PhotoURL           *string                `json:"photoUrl,omitempty"`
+	ProbeAdded         *bool                  `json:"probeAdded,omitempty"`
+	RackPosition       *StageRackPosition     `json:"rackPosition,omitempty"`
+	SteamGenerators    *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs   *StageTemperatureBulbs `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe   *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer              *StageTimer            `json:"timer,omitempty"`
+	Title              interface{}            `json:"title,omitempty"`
+	Type               StageType              `json:"type"`
+	UserActionRequired bool                   `json:"userActionRequired"`
+	Vent               *StageVent             `json:"vent,omitempty"`
+	VideoThumbnailURL  *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL           *string                `json:"videoUrl,omitempty"`
+}
+
+type StartCookCommandPayloadV2 struct {
+	CookID       *string                        `json:"cookId,omitempty"`
+	CookableID   *string                        `json:"cookableId,omitempty"`
+	CookableType OvenCommandPayloadCookableType `json:"cookableType"`
+	CookerID     string                         `json:"cookerId"`
+	OriginSource OvenCommandPayloadOriginSource `json:"originSource"`
+	Stages       []StageV2                      `json:"stages"`
+	Type         string                         `json:"type"`
+}
+
+type StartCookCommandType string
+
+const (
+	StartCookCommandTypeCmdApoStart StartCookCommandType = "CMD_APO_START"
+	StartCookCommandTypeStartCook   StartCookCommandType = "startCook"
+)
+
+type StartDescaleCommand struct {
+	ID   string                  `json:"id"`
+	Type StartDescaleCommandType `json:"type"`
+}
+
+type StartDescaleCommandPayload struct{}
+
+type StartDescaleCommandType string
+
+const (
+	StartDescaleCommandTypeCmdApoStartDescale StartDescaleCommandType = "CMD_APO_START_DESCALE"
+	StartDescaleCommandTypeStartDescale       StartDescaleCommandType = "startDescale"
+)
+
+type StartFirmwareUpdateCommand struct {
+	ID      string                            `json:"id"`
+	Payload StartFirmwareUpdateCommandPayload `json:"payload"`
+	Type    StartFirmwareUpdateCommandType    `json:"type"`
+}
+
+type StartFirmwareUpdateCommandPayload struct {
+	DownloadLink string `json:"downloadLink"`
+}
+
+type StartFirmwareUpdateCommandType string
+
+const (
+	StartFirmwareUpdateCommandTypeCmdApoOta           StartFirmwareUpdateCommandType = "CMD_APO_OTA"
+	StartFirmwareUpdateCommandTypeStartFirmwareUpdate StartFirmwareUpdateCommandType = "startFirmwareUpdate"
+)
+
+type StartLiveStreamCommand struct {
+	ID      string                     `json:"id"`
+	Payload *CloudflareSrtPayload      `json:"payload,omitempty"`
+	Type    StartLiveStreamCommandType `json:"type"`
+}
+
+type StartLiveStreamCommandType string
+
+const (
+	StartLiveStreamCommandTypeCmdApoStartLiveStream StartLiveStreamCommandType = "CMD_APO_START_LIVE_STREAM"
+)
+
+type StartStageCommand struct {
+	ID      string                   `json:"id"`
+	Payload StartStageCommandPayload `json:"payload"`
+	Type    StartStageCommandType    `json:"type"`
+}
+
+type StartStageCommandPayload struct {
+	StageID string `json:"stageId"`
+}
+
+type StartStageCommandType string
+
+const (
+	StartStageCommandTypeCmdApoStartStage StartStageCommandType = "CMD_APO_START_STAGE"
+	StartStageCommandTypeStartStage       StartStageCommandType = "startStage"
+)
+
+type StateV1 struct {
+	Mode                StateV1Mode     `json:"mode"`
+	ProcessedCommandIds []string        `json:"processedCommandIds"`
+	TemperatureUnit     TemperatureUnit `json:"temperatureUnit"`
+}
+
+type StateV1Mode string
+
+const (
+	StateV1ModeCook    StateV1Mode = "cook"
+	StateV1ModeDescale StateV1Mode = "descale"
+	StateV1ModeIdle    StateV1Mode = "idle"
+)
+
+type StateV2 struct {
+	CavityOverheated    bool            `json:"cavityOverheated"`
+	Mode                StateV2Mode     `json:"mode"`
+	ProcessedCommandIds []string        `json:"processedCommandIds"`
+	TemperatureUnit     TemperatureUnit `json:"temperatureUnit"`
+}
+
+type StateV2Mode string
+
+const (
+	StateV2ModeCook    StateV2Mode = "cook"
+	StateV2ModeDescale StateV2Mode = "descale"
+	StateV2ModeIdle    StateV2Mode = "idle"
+)
+
+type SteamGeneratorsNode struct {
+	Boiler           BoilerSteamGeneratorNodeBoiler         `json:"boiler"`
+	Evaporator       EvaporatorSteamGeneratorNodeEvaporator `json:"evaporator"`
+	Mode             SteamGeneratorsNodeMode                `json:"mode"`
+	RelativeHumidity *SteamGeneratorsNodeRelativeHumidity   `json:"relativeHumidity,omitempty"`
+	SteamPercentage  *SteamGeneratorsNodeSteamPercentage    `json:"steamPercentage,omitempty"`
+}
+
+type SteamGeneratorsNodeMode string
+
+const (
+	SteamGeneratorsNodeModeRelativeHumidity SteamGeneratorsNodeMode = "relative-humidity"
+	SteamGeneratorsNodeModeSteamPercentage  SteamGeneratorsNodeMode = "steam-percentage"
+	SteamGeneratorsNodeModeIdle             SteamGeneratorsNodeMode = "idle"
+)
+
+type SteamGeneratorsNodeRelativeHumidity struct {
+	Current  float64 `json:"current"`
+	Setpoint float64 `json:"setpoint"`
+}
+
+type SteamGeneratorsNodeSteamPercentage struct {
+	Setpoint float64 `json:"setpoint"`
+}
+
+type SteamGeneratorsNodeV2 struct {
+	Boiler           BoilerSteamGeneratorNodeV2Boiler         `json:"boiler"`
+	Evaporator       EvaporatorSteamGeneratorNodeV2Evaporator `json:"evaporator"`
+	Mode             SteamGeneratorsNodeV2Mode                `json:"mode"`
+	RelativeHumidity *SteamGeneratorsNodeV2RelativeHumidity   `json:"relativeHumidity,omitempty"`
+	SteamPercentage  *SteamGeneratorsNodeV2SteamPercentage    `json:"steamPercentage,omitempty"`
+}
+
+type SteamGeneratorsNodeV2Mode string
+
+const (
+	SteamGeneratorsNodeV2ModeRelativeHumidity SteamGeneratorsNodeV2Mode = "relative-humidity"
+	SteamGeneratorsNodeV2ModeIdle             SteamGeneratorsNodeV2Mode = "idle"
+	SteamGeneratorsNodeV2ModeSteamPercentage  SteamGeneratorsNodeV2Mode = "steam-percentage"
+)
+
+type SteamGeneratorsNodeV2RelativeHumidity struct {
+	Current  float64 `json:"current"`
+	Setpoint float64 `json:"setpoint"`
+}
+
+type SteamGeneratorsNodeV2SteamPercentage struct {
+	Current  *float64 `json:"current,omitempty"`
+	Setpoint float64  `json:"setpoint"`
+}
+
+type SteamPercentageSteamGeneratorNode struct {
+	Mode            SteamPercentageSteamGeneratorNodeMode `json:"mode"`
+	SteamPercentage SteamGeneratorsNodeSteamPercentage    `json:"steamPercentage"`
+}
+
+type SteamPercentageSteamGeneratorNodeMode string
+
+const (
+	SteamPercentageSteamGeneratorNodeModeSteamPercentage SteamPercentageSteamGeneratorNodeMode = "steam-percentage"
+)
+
+type SteamPercentageSteamGeneratorNodeV2 struct {
+	Mode            SteamPercentageSteamGeneratorNodeV2Mode `json:"mode"`
+	SteamPercentage SteamGeneratorsNodeV2SteamPercentage    `json:"steamPercentage"`
+}
+
+type SteamPercentageSteamGeneratorNodeV2Mode string
+
+const (
+	SteamPercentageSteamGeneratorNodeV2ModeSteamPercentage SteamPercentageSteamGeneratorNodeV2Mode = "steam-percentage"
+)
+
+type StopCookCommand struct {
+	ID   string              `json:"id"`
+	Type StopCookCommandType `json:"type"`
+}
+
+type StopCookCommandPayload struct{}
+
+type StopCookCommandType string
+
+const (
+	StopCookCommandTypeCmdApoStop StopCookCommandType = "CMD_APO_STOP"
+	StopCookCommandTypeStopCook   StopCookCommandType = "stopCook"
+)
+
+type StopLiveStreamCommand struct {
+	ID   string                    `json:"id"`
+	Type StopLiveStreamCommandType `json:"type"`
+}
+
+type StopLiveStreamCommandPayload struct{}
+
+type StopLiveStreamCommandType string
+
+const (
+	StopLiveStreamCommandTypeCmdApoStopLiveStream StopLiveStreamCommandType = "CMD_APO_STOP_LIVE_STREAM"
+)
+
+type StopStage struct {
+	Description        *string     `json:"description,omitempty"`
+	ID                 string      `json:"id"`
+	Timer              *StageTimer `json:"timer,omitempty"`
+	Title              interface{} `json:"title,omitempty"`
+	Type               StageType   `json:"type"`
+	UserActionRequired bool        `json:"userActionRequired"`
+}
+
+type SystemInfoV1 struct {
+	BetaFeature               *string      `json:"betaFeature,omitempty"`
+	FirmwareUpdatedTimestamp  string       `json:"firmwareUpdatedTimestamp"`
+	FirmwareVersion           string       `json:"firmwareVersion"`
+	HardwareVersion           string       `json:"hardwareVersion"`
+	LastConnectedTimestamp    string       `json:"lastConnectedTimestamp"`
+	LastDisconnectedTimestamp string       `json:"lastDisconnectedTimestamp"`
+	Online                    bool         `json:"online"`
+	OtaUpdate                 *OtaUpdateV1 `json:"otaUpdate,omitempty"`
+	PowerHertz                float64      `json:"powerHertz"`
+	PowerMains                float64      `json:"powerMains"`
+	TriacsFailed              bool         `json:"triacsFailed"`
+	UiFirmwareVersion         *string      `json:"uiFirmwareVersion,omitempty"`
+	UiHardwareVersion         *string      `json:"uiHardwareVersion,omitempty"`
+}
+
+type SystemInfoV2 struct {
+	DeviceID                  string       `json:"deviceId"`
+	FirmwareUpdatedTimestamp  string       `json:"firmwareUpdatedTimestamp"`
+	FirmwareVersion           string       `json:"firmwareVersion"`
+	HardwareVersion           string       `json:"hardwareVersion"`
+	LastConnectedTimestamp    string       `json:"lastConnectedTimestamp"`
+	LastDisconnectedTimestamp string       `json:"lastDisconnectedTimestamp"`
+	Online                    bool         `json:"online"`
+	OtaUpdate                 *OtaUpdateV2 `json:"otaUpdate,omitempty"`
+	PowerHertz                float64      `json:"powerHertz"`
+	PowerMains                float64      `json:"powerMains"`
+	ReleaseTrack              string       `json:"releaseTrack"`
+	TriacsFailed              *bool        `json:"triacsFailed,omitempty"`
+}
+
+type TemperatureBulbsNodeV1 struct {
+	Dry       *TemperatureBulbsNodeV1Dry       `json:"dry,omitempty"`
+	DryBottom *TemperatureBulbsNodeV1DryBottom `json:"dryBottom,omitempty"`
+	DryTop    *TemperatureBulbsNodeV1DryTop    `json:"dryTop,omitempty"`
+	Mode      TemperatureBulbsNodeV1Mode       `json:"mode"`
+	Wet       *TemperatureBulbsNodeV1Wet       `json:"wet,omitempty"`
+}
+
+type TemperatureBulbsNodeV1Dry struct {
+	Current  TemperatureBulbsNodeV1DryCurrent     `json:"current"`
+	Setpoint *DryTemperatureBulbNodeV1DrySetpoint `json:"setpoint,omitempty"`
+}
+
+type TemperatureBulbsNodeV1DryBottom struct {
+	Current    TemperatureBulbsNodeV1DryBottomCurrent `json:"current"`
+	Overheated bool                                   `json:"overheated"`
+}
+
+type TemperatureBulbsNodeV1DryBottomCurrent struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type TemperatureBulbsNodeV1DryCurrent struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type TemperatureBulbsNodeV1DryTop struct {
+	Current    TemperatureBulbsNodeV1DryTopCurrent `json:"current"`
+	Overheated bool                                `json:"overheated"`
+}
+
+type TemperatureBulbsNodeV1DryTopCurrent struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type TemperatureBulbsNodeV1Mode string
+
+const (
+	TemperatureBulbsNodeV1ModeWet TemperatureBulbsNodeV1Mode = "wet"
+	TemperatureBulbsNodeV1ModeDry TemperatureBulbsNodeV1Mode = "dry"
+)
+
+type TemperatureBulbsNodeV1Wet struct {
+	Current    TemperatureBulbsNodeV1WetCurrent   `json:"current"`
+	DoseFailed bool                               `json:"doseFailed"`
+	Dosed      bool                               `json:"dosed"`
+	Setpoint   *TemperatureBulbsNodeV1WetSetpoint `json:"setpoint,omitempty"`
+}
+
+type TemperatureBulbsNodeV1WetCurrent struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type TemperatureBulbsNodeV1WetSetpoint struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type TemperatureBulbsNodeV2 struct {
+	Dry       *TemperatureBulbsNodeV2Dry       `json:"dry,omitempty"`
+	DryBottom *TemperatureBulbsNodeV2DryBottom `json:"dryBottom,omitempty"`
+	DryTop    *TemperatureBulbsNodeV2DryTop    `json:"dryTop,omitempty"`
+	Mode      TemperatureBulbsNodeV2Mode       `json:"mode"`
+	Wet       *TemperatureBulbsNodeV2Wet       `json:"wet,omitempty"`
+}
+
+type TemperatureBulbsNodeV2Dry struct {
+	Current  TemperatureBulbsNodeV2DryCurrent     `json:"current"`
+	Setpoint *DryTemperatureBulbNodeV2DrySetpoint `json:"setpoint,omitempty"`
+}
+
+type TemperatureBulbsNodeV2DryBottom struct {
+	Current      TemperatureBulbsNodeV2DryBottomCurrent `json:"current"`
+	NtcConnected bool                                   `json:"ntcConnected"`
+}
+
+type TemperatureBulbsNodeV2DryBottomCurrent struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type TemperatureBulbsNodeV2DryCurrent struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type TemperatureBulbsNodeV2DryTop struct {
+	Current      TemperatureBulbsNodeV2DryTopCurrent `json:"current"`
+	NtcConnected bool                                `json:"ntcConnected"`
+}
+
+type TemperatureBulbsNodeV2DryTopCurrent struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type TemperatureBulbsNodeV2Mode string
+
+const (
+	TemperatureBulbsNodeV2ModeWet TemperatureBulbsNodeV2Mode = "wet"
+	TemperatureBulbsNodeV2ModeDry TemperatureBulbsNodeV2Mode = "dry"
+)
+
+type TemperatureBulbsNodeV2Wet struct {
+	Current          TemperatureBulbsNodeV2WetCurrent   `json:"current"`
+	Dc12VInletStatus string                             `json:"dc12VInletStatus"`
+	Dosed            bool                               `json:"dosed"`
+	NtcConnected     bool                               `json:"ntcConnected"`
+	Setpoint         *TemperatureBulbsNodeV2WetSetpoint `json:"setpoint,omitempty"`
+}
+
+type TemperatureBulbsNodeV2WetCurrent struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type TemperatureBulbsNodeV2WetSetpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type TemperatureProbeNodeV1 struct {
+	Connected  bool                                    `json:"connected"`
+	Current    *ConnectedTemperatureProbeNodeV1Current `json:"current,omitempty"`
+	Overheated *bool                                   `json:"overheated,omitempty"`
+	Setpoint   *TemperatureProbeNodeV1Setpoint         `json:"setpoint,omitempty"`
+}
+
+type TemperatureProbeNodeV1Setpoint struct {
+	Celsius    float64 `json:"celsius"`
+	Fahrenheit float64 `json:"fahrenheit"`
+}
+
+type TemperatureProbeNodeV2 struct {
+	Connected    bool                                    `json:"connected"`
+	Current      *ConnectedTemperatureProbeNodeV2Current `json:"current,omitempty"`
+	NtcConnected bool                                    `json:"ntcConnected"`
+	Setpoint     *TemperatureProbeNodeV2Setpoint         `json:"setpoint,omitempty"`
+}
+
+type TemperatureProbeNodeV2Setpoint struct {
+	Celsius float64 `json:"celsius"`
+}
+
+type TemperatureUnit string
+
+const (
+	TemperatureUnitC TemperatureUnit = "C"
+	TemperatureUnitF TemperatureUnit = "F"
+)
+
+type TimerMode string
+
+const (
+	TimerModeComplete TimerMode = "complete"
+	TimerModeIdle     TimerMode = "idle"
+	TimerModePaused   TimerMode = "paused"
+	TimerModeRunning  TimerMode = "running"
+)
+
+type TimerNodeV1 struct {
+	Current float64         `json:"current"`
+	Initial float64         `json:"initial"`
+	Mode    TimerNodeV1Mode `json:"mode"`
+}
+
+type TimerNodeV1Mode string
+
+const (
+	TimerNodeV1ModeIdle    TimerNodeV1Mode = "idle"
+	TimerNodeV1ModePaused  TimerNodeV1Mode = "paused"
+	TimerNodeV1ModeRunning TimerNodeV1Mode = "running"
+)
+
+type TimerNodeV2 struct {
+	Initial            *float64  `json:"initial,omitempty"`
+	Mode               TimerMode `json:"mode"`
+	StartedAtTimestamp *string   `json:"startedAtTimestamp,omitempty"`
+}
+
+type UnpairAlexa struct {
+	ID      string                                 `json:"id"`
+	Payload UnpairAlexaPayload                     `json:"payload"`
+	Type    MultiUserCommandTypeCmdUserUnpairAlexa `json:"type"`
+}
+
+type UnpairAlexaPayload struct {
+	Code string `json:"code"`
+}
+
+type UpdateCookStageCommand struct {
+	ID      string                     `json:"id"`
+	Payload Stage                      `json:"payload"`
+	Type    UpdateCookStageCommandType `json:"type"`
+}
+
+type UpdateCookStageCommandType string
+
+const (
+	UpdateCookStageCommandTypeCmdApoUpdateCookStage UpdateCookStageCommandType = "CMD_APO_UPDATE_COOK_STAGE"
+	UpdateCookStageCommandTypeUpdateCookStage       UpdateCookStageCommandType = "updateCookStage"
+)
+
+type UpdateCookStagesCommand struct {
+	ID      string                         `json:"id"`
+	Payload UpdateCookStagesCommandPayload `json:"payload"`
+	Type    UpdateCookStagesCommandType    `json:"type"`
+}
+
+type UpdateCookStagesCommandPayload struct {
+	Stages []UpdateCookStagesCommandPayloadStagesItem `json:"stages"`
+}
+
+type UpdateCookStagesCommandPayloadStagesItem struct {
+	Description                      *string                `json:"description,omitempty"`
+	Do                               *StageDo               `json:"do,omitempty"`
+	Entry                            *StageEntry            `json:"entry,omitempty"`
+	Exit                             *StageExit             `json:"exit,omitempty"`
+	Fan                              *StageFan              `json:"fan,omitempty"`
+	HeatingElements                  *StageHeatingElements  `json:"heatingElements,omitempty"`
+	ID                               string                 `json:"id"`
+	PhotoURL                         *string                `json:"photoUrl,omitempty"`
+	Preset                           *string                `json:"preset,omitempty"`
+	ProbeAdded                       *bool                  `json:"probeAdded,omitempty"`
+	RackPosition                     *float64               `json:"rackPosition,omitempty"`
+	StageTransitionPendingUserAction *bool                  `json:"stageTransitionPendingUserAction,omitempty"`
+	SteamGenerators                  *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs                 *StageTemperatureBulbs `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe                 *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer                            *StageTimer            `json:"timer,omitempty"`
+	Title                            interface{}            `json:"title,omitempty"`
+	Type                             *StageType             `json:"type,omitempty"`
+	UserActionRequired               *bool                  `json:"userActionRequired,omitempty"`
+	Vent                             *StageVent             `json:"vent,omitempty"`
+	VideoThumbnailURL                *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL                         *string                `json:"videoUrl,omitempty"`
+}
+
+type UpdateCookStagesCommandType string
+
+const (
+	UpdateCookStagesCommandTypeCmdApoUpdateCookStages UpdateCookStagesCommandType = "CMD_APO_UPDATE_COOK_STAGES"
+	UpdateCookStagesCommandTypeUpdateCookStages       UpdateCookStagesCommandType = "updateCookStages"
+)
+
+type UpdateCookStagesV1Command struct {
+	ID      string                           `json:"id"`
+	Payload UpdateCookStagesV1CommandPayload `json:"payload"`
+	Type    UpdateCookStagesV1CommandType    `json:"type"`
+}
+
+type UpdateCookStagesV1CommandPayload struct {
+	Stages []UpdateCookStagesV1CommandPayloadStagesItem `json:"stages"`
+}
+
+type UpdateCookStagesV1CommandPayloadStagesItem struct {
+	Description        *string                `json:"description,omitempty"`
+	Fan                *StageFan              `json:"fan,omitempty"`
+	HeatingElements    *StageHeatingElements  `json:"heatingElements,omitempty"`
+	ID                 string                 `json:"id"`
+	PhotoURL           *string                `json:"photoUrl,omitempty"`
+	ProbeAdded         *bool                  `json:"probeAdded,omitempty"`
+	RackPosition       *StageRackPosition     `json:"rackPosition,omitempty"`
+	SteamGenerators    *StageSteamGenerators  `json:"steamGenerators,omitempty"`
+	TemperatureBulbs   *StageTemperatureBulbs `json:"temperatureBulbs,omitempty"`
+	TemperatureProbe   *StageTemperatureProbe `json:"temperatureProbe,omitempty"`
+	Timer              *StageTimer            `json:"timer,omitempty"`
+	Title              interface{}            `json:"title,omitempty"`
+	Type               StageType              `json:"type"`
+	UserActionRequired bool                   `json:"userActionRequired"`
+	Vent               *StageVent             `json:"vent,omitempty"`
+	VideoThumbnailURL  *string                `json:"videoThumbnailUrl,omitempty"`
+	VideoURL           *string                `json:"videoUrl,omitempty"`
+}
+
+type UpdateCookStagesV1CommandType string
+
+const (
+	UpdateCookStagesV1CommandTypeCmdApoUpdateCookStages UpdateCookStagesV1CommandType = "CMD_APO_UPDATE_COOK_STAGES"
+	UpdateCookStagesV1CommandTypeUpdateCookStages       UpdateCookStagesV1CommandType = "updateCookStages"
+)
+
+type UserInterfaceCircuitNode struct {
+	CommunicationFailed bool `json:"communicationFailed"`
+}
+
+type VentNode struct {
+	Open bool `json:"open"`
+}
+
+type WasteWaterTankNode struct {
+	Full    bool `json:"full"`
+	Removed bool `json:"removed"`
+}
+
+type WaterTankNodeV1 struct {
+	Empty bool `json:"empty"`
+}
+
+type WaterTankNodeV2 struct {
+	Empty   bool `json:"empty"`
+	Low     bool `json:"low"`
+	Removed bool `json:"removed"`
+}
+
+type WetTemperatureBulbNodeV1 struct {
+	Dry       WetTemperatureBulbNodeV1Dry     `json:"dry"`
+	DryBottom TemperatureBulbsNodeV1DryBottom `json:"dryBottom"`
+	DryTop    TemperatureBulbsNodeV1DryTop    `json:"dryTop"`
+	Mode      WetTemperatureBulbNodeV1Mode    `json:"mode"`
+	Wet       WetTemperatureBulbNodeV1Wet     `json:"wet"`
+}
+
+type WetTemperatureBulbNodeV1Dry struct {
+	Current TemperatureBulbsNodeV1DryCurrent `json:"current"`
+}
+
+type WetTemperatureBulbNodeV1Mode string
+
+const (
+	WetTemperatureBulbNodeV1ModeWet WetTemperatureBulbNodeV1Mode = "wet"
+)
+
+type WetTemperatureBulbNodeV1Wet struct {
+	Current    TemperatureBulbsNodeV1WetCurrent  `json:"current"`
+	DoseFailed bool                              `json:"doseFailed"`
+	Dosed      bool                              `json:"dosed"`
+	Setpoint   TemperatureBulbsNodeV1WetSetpoint `json:"setpoint"`
+}
+
+type WetTemperatureBulbNodeV2 struct {
+	Dry       WetTemperatureBulbNodeV2Dry     `json:"dry"`
+	DryBottom TemperatureBulbsNodeV2DryBottom `json:"dryBottom"`
+	DryTop    TemperatureBulbsNodeV2DryTop    `json:"dryTop"`
+	Mode      WetTemperatureBulbNodeV2Mode    `json:"mode"`
+	Wet       WetTemperatureBulbNodeV2Wet     `json:"wet"`
+}
+
+type WetTemperatureBulbNodeV2Dry struct {
+	Current TemperatureBulbsNodeV2DryCurrent `json:"current"`
+}
+
+type WetTemperatureBulbNodeV2Mode string
+
+const (
+	WetTemperatureBulbNodeV2ModeWet WetTemperatureBulbNodeV2Mode = "wet"
+)
+
+type WetTemperatureBulbNodeV2Wet struct {
+	Current          TemperatureBulbsNodeV2WetCurrent   `json:"current"`
+	Dc12VInletStatus string                             `json:"dc12VInletStatus"`
+	Dosed            bool                               `json:"dosed"`
+	NtcConnected     bool                               `json:"ntcConnected"`
+	Setpoint         *TemperatureBulbsNodeV2WetSetpoint `json:"setpoint,omitempty"`
+}
@@ -0,0 +1,131 @@
+package dto
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// UnknownFields lists the fields of the JSON document data that would be
+// ignored when decoding it into a value of type t, as dotted paths, e.g.
+// "state.nodes.newNode". Array elements are denoted by "[]". Types with their
+// own UnmarshalJSON and interface{} values accept any field.
+func UnknownFields(data []byte, t reflect.Type) ([]string, error) {
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	collectUnknownFields(value, t, "", seen)
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func collectUnknownFields(value interface{}, t reflect.Type, path string, unknown map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for key, fieldValue := range object {
+			field, exists := lookupJSONField(fields, key)
+			if !exists {
+				unknown[joinFieldPath(path, key)] = true
+				continue
+			}
+			collectUnknownFields(fieldValue, field.Type, joinFieldPath(path, key), unknown)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, element := range object {
+			collectUnknownFields(element, t.Elem(), joinFieldPath(path, key), unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		array, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for _, element := range array {
+			collectUnknownFields(element, t.Elem(), path+"[]", unknown)
+		}
+	}
+}
+
+// jsonFields maps the JSON names of a struct's fields to the fields, including
+// those promoted from embedded structs, as encoding/json does.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for embeddedName, embeddedField := range jsonFields(fieldType) {
+				if _, exists := fields[embeddedName]; !exists {
+					fields[embeddedName] = embeddedField
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// lookupJSONField prefers an exact match, but falls back to a case-insensitive
+// one like encoding/json.
+func lookupJSONField(fields map[string]reflect.StructField, key string) (reflect.StructField, bool) {
+	if field, exists := fields[key]; exists {
+		return field, true
+	}
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnknownFields(t *testing.T) {
+	payload := `{
+		"cookerId": "0123456789abcdef",
+		"type": "oven_v2",
+		"newTopLevel": 1,
+		"state": {
+			"version": 1,
+			"updatedTimestamp": "2024-03-01T12:00:00Z",
+			"nodes": {
+				"temperatureBulbs": {"mode": "dry", "dry": {"current": {"celsius": 20, "kelvin": 293}}},
+				"newNode": {"on": true}
+			},
+			"cook": {"cookId": "1", "stages": [{"id": "a", "newStageField": 1}, {"id": "b", "newStageField": 2}]}
+		}
+	}`
+
+	fields, err := UnknownFields([]byte(payload), reflect.TypeOf(&ApoStateEvent{}))
+	if err != nil {
+		t.Fatalf("failed to find unknown fields: %+v", err)
+	}
+
+	expected := []string{
+		"newTopLevel",
+		"state.cook.stages[].newStageField",
+		"state.nodes.newNode",
+		"state.nodes.temperatureBulbs.dry.current.kelvin",
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+}
+
+func TestUnknownFields_CustomUnmarshaler(t *testing.T) {
+	fields, err := UnknownFields([]byte(`{"cookerId":"0123456789abcdef","anything":true}`), reflect.TypeOf(&WifiFirmwareUpdateEvent{}))
+	if err != nil || len(fields) != 0 {
+		t.Errorf("expected types with UnmarshalJSON to accept any field, got %v (err: %+v)", fields, err)
+	}
+}
@@ -8,15 +8,32 @@ type Event interface {
 	isEvent()
 }
 
+// EventHandler consumes events read from a Service.
+type EventHandler interface {
+	HandleEvent(event Event)
+}
+
 // OvenAdded indicates that a new oven has been loaded by the service. This event
 // is sent the first time an oven is found by the service, which can happen
 // initially at startup, or when an oven is newly paired to the account.
 type OvenAdded struct {
 	Oven *Oven
+	// State of the oven when the event was sent. Unlike Oven.State, this is safe
+	// to read from any goroutine.
+	State *dto.OvenStateV1
 }
 
 func (OvenAdded) isEvent() {}
 
+// OvenListUpdated indicates that the service received the list of ovens paired
+// with the account. OvenAdded is still sent separately for each new oven once
+// its state has been received.
+type OvenListUpdated struct {
+	CookerIDs []CookerID
+}
+
+func (OvenListUpdated) isEvent() {}
+
 // OvenRenamed indicates that a known oven has a new name.
 type OvenRenamed struct {
 	Oven    *Oven
@@ -29,11 +46,33 @@ func (OvenRenamed) isEvent() {}
 // only sent if the oven's state changes after the initial OvenAdded event.
 type OvenUpdated struct {
 	Oven          *Oven
+	State         *dto.OvenStateV1
 	PreviousState *dto.OvenStateV1
 }
 
 func (OvenUpdated) isEvent() {}
 
+// DescaleRequired indicates that the oven has started asking to be descaled.
+// This is sent once when the flag is first seen, either on a state update or
+// right after the OvenAdded event.
+type DescaleRequired struct {
+	Oven *Oven
+}
+
+func (DescaleRequired) isEvent() {}
+
+// FirmwareUpdateProgress indicates that an over-the-air firmware update has
+// changed mode or progressed. It is derived from both firmware update events
+// and the "otaUpdate" field of the oven's state.
+type FirmwareUpdateProgress struct {
+	Oven *Oven
+	Mode dto.OtaUpdateMode
+	// Percentage complete, if reported
+	Progress *float64
+}
+
+func (FirmwareUpdateProgress) isEvent() {}
+
 // ServiceStopped indicates that the client connection has been closed and no
 // more events can be processed.
 type ServiceStopped struct{}
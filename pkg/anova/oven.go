@@ -2,6 +2,8 @@ package anova
 
 import (
 	"apocli/pkg/anova/dto"
+	"errors"
+	"math"
 	"time"
 )
 
@@ -20,7 +22,8 @@ type Oven struct {
 	// state updates with no changes.
 	LastUpdate time.Time
 
-	client *Client
+	client   *Client
+	progress progressTracker
 }
 
 func (oven *Oven) SetName(name string) error {
@@ -48,7 +51,7 @@ func (oven *Oven) ListAccounts() (accountIDs []string, err error) {
 }
 
 func (oven *Oven) StartCook(cookID string, stages CookStages) error {
-	return oven.client.StartCook(oven.CookerID, cookID, stages.ToDto())
+	return oven.client.StartCook(oven.CookerID, cookID, stages.toOvenStages())
 }
 
 func (oven *Oven) UpdateCookStage(stage *CookStage) error {
@@ -67,6 +70,104 @@ func (oven *Oven) UpdateCookStages(stages CookStages) error {
 	return oven.client.UpdateCookStages(oven.CookerID, stages.ToDto())
 }
 
+// SetTemperatureSetpoint changes the running stage's dry or wet bulb setpoint
+// without restarting the stage.
+func (oven *Oven) SetTemperatureSetpoint(setpoint TemperatureSetpoint) error {
+	if err := setpoint.Validate(); err != nil {
+		return err
+	}
+	return oven.client.SetTemperatureBulbs(oven.CookerID, dto.TemperatureBulbsMode(setpoint.Mode), setpoint.TemperatureCelsius)
+}
+
+// SetSteamPercentage changes the running stage's steam: the relative humidity
+// in wet mode, or the steam percentage in dry mode.
+func (oven *Oven) SetSteamPercentage(mode TemperatureMode, percentage SteamPercentage) error {
+	if err := mode.Validate(); err != nil {
+		return err
+	}
+	if err := percentage.Validate(); err != nil {
+		return err
+	}
+	generatorMode := dto.SteamGeneratorModeSteamPercentage
+	if mode == TemperatureModeWet {
+		generatorMode = dto.SteamGeneratorModeRelativeHumidity
+	}
+	return oven.client.SetSteamGenerators(oven.CookerID, generatorMode, int(math.Round(float64(percentage))))
+}
+
 func (oven *Oven) StopCook() error {
 	return oven.client.StopCook(oven.CookerID)
 }
+
+func (oven *Oven) StartDescale() error {
+	return oven.client.StartDescale(oven.CookerID)
+}
+
+func (oven *Oven) AbortDescale() error {
+	return oven.client.AbortDescale(oven.CookerID)
+}
+
+func (oven *Oven) StartFirmwareUpdate(downloadLink string) error {
+	return oven.client.StartFirmwareUpdate(oven.CookerID, downloadLink)
+}
+
+// SetTimeZone sets the oven's time zone to the location as it is currently in
+// effect. The offset is not updated automatically when daylight saving time
+// starts or ends; see TimeZoneSync.
+func (oven *Oven) SetTimeZone(location *time.Location) error {
+	timeZone, err := newTimeZone(location, time.Now())
+	if err != nil {
+		return err
+	}
+	return oven.client.SetTimeZone(oven.CookerID, timeZone)
+}
+
+func (oven *Oven) SetMetadata(metadata map[string]interface{}) error {
+	return oven.client.SetMetadata(oven.CookerID, metadata)
+}
+
+func (oven *Oven) HealthCheck() error {
+	return oven.client.HealthCheck(oven.CookerID)
+}
+
+func (oven *Oven) RequestDiagnostics(command string) (interface{}, error) {
+	return oven.client.RequestDiagnostics(oven.CookerID, command)
+}
+
+func (oven *Oven) GetConfiguration() (interface{}, error) {
+	return oven.client.GetConfiguration(oven.CookerID)
+}
+
+// SetReportStateRate changes how often the oven sends its state while cooking
+// and while idle. By default, this is every 2 and 30 seconds respectively. The
+// oven only accepts whole seconds.
+func (oven *Oven) SetReportStateRate(cooking time.Duration, idle time.Duration) error {
+	if cooking < time.Second || idle < time.Second || cooking%time.Second != 0 || idle%time.Second != 0 {
+		return ErrInvalidReportStateRate{}
+	}
+	return oven.client.SetReportStateRate(oven.CookerID, cooking, idle)
+}
+
+func (oven *Oven) SetReportStateRateDefault() error {
+	return oven.client.SetReportStateRateDefault(oven.CookerID)
+}
+
+// WithReportStateRate changes the state reporting rate for the duration of fn,
+// then restores the default rate, even if fn fails.
+func (oven *Oven) WithReportStateRate(cooking time.Duration, idle time.Duration, fn func() error) (err error) {
+	err = oven.SetReportStateRate(cooking, idle)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, oven.SetReportStateRateDefault())
+	}()
+
+	return fn()
+}
+
+type ErrInvalidReportStateRate struct{}
+
+func (err ErrInvalidReportStateRate) Error() string {
+	return "report state rate must be a whole number of seconds, and at least one"
+}
@@ -3,6 +3,7 @@ package anova
 import (
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestProbe_Validate(t *testing.T) {
@@ -77,3 +78,21 @@ func TestRackPosition_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestOven_SetReportStateRate_Invalid(t *testing.T) {
+	oven := &Oven{CookerID: "0123456789abcdef"}
+	for _, rate := range []struct {
+		cooking time.Duration
+		idle    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{2 * time.Second, 500 * time.Millisecond},
+		// The oven only accepts whole seconds
+		{1900 * time.Millisecond, 30 * time.Second},
+	} {
+		err := oven.SetReportStateRate(rate.cooking, rate.idle)
+		if !errors.Is(err, ErrInvalidReportStateRate{}) {
+			t.Errorf("expected failure for %s and %s, got %+v", rate.cooking, rate.idle, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package anova
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PairingCode is a decoded pairing code, as returned by GeneratePairingCode.
+// Pairing codes are JWTs that expire 24 hours after being generated. The
+// signature is not verified, since only the backend can do that.
+type PairingCode struct {
+	// The original, encoded JWT to pass to AddUserWithPairingCode
+	Token string
+
+	// Oven that the code grants access to. Empty if the token does not name the
+	// oven.
+	CookerID  CookerID
+	Issuer    string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+
+	// All claims from the JWT payload, including the ones decoded above
+	Claims map[string]interface{}
+}
+
+// ParsePairingCode decodes the claims of a pairing code without contacting the
+// backend.
+func ParsePairingCode(token string) (*PairingCode, error) {
+	token = strings.TrimSpace(token)
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidPairingCode{Reason: fmt.Sprintf("expected 3 JWT segments, got %d", len(parts))}
+	}
+
+	rawClaims, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, ErrInvalidPairingCode{Reason: fmt.Sprintf("payload is not base64: %+v", err)}
+	}
+
+	var claims map[string]interface{}
+	err = json.Unmarshal(rawClaims, &claims)
+	if err != nil {
+		return nil, ErrInvalidPairingCode{Reason: fmt.Sprintf("payload is not JSON: %+v", err)}
+	}
+
+	code := &PairingCode{
+		Token:  token,
+		Claims: claims,
+	}
+
+	if issuer, ok := claims["iss"].(string); ok {
+		code.Issuer = issuer
+	}
+	if issuedAt, ok := claims["iat"].(float64); ok {
+		code.IssuedAt = time.Unix(int64(issuedAt), 0)
+	}
+	if expiresAt, ok := claims["exp"].(float64); ok {
+		code.ExpiresAt = time.Unix(int64(expiresAt), 0)
+	}
+	// The name of this claim isn't documented anywhere, so accept both casings
+	// used elsewhere in the API
+	for _, key := range []string{"cookerId", "cooker_id"} {
+		if cookerID, ok := claims[key].(string); ok {
+			code.CookerID = CookerID(cookerID)
+			break
+		}
+	}
+
+	return code, nil
+}
+
+// Validate checks that the pairing code can still be used.
+func (code *PairingCode) Validate() error {
+	if code.ExpiresAt.IsZero() {
+		return ErrInvalidPairingCode{Reason: "missing expiry"}
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return ErrPairingCodeExpired{ExpiresAt: code.ExpiresAt}
+	}
+	return nil
+}
+
+type ErrInvalidPairingCode struct {
+	Reason string
+}
+
+func (err ErrInvalidPairingCode) Error() string {
+	return fmt.Sprintf("invalid pairing code: %s", err.Reason)
+}
+
+type ErrPairingCodeExpired struct {
+	ExpiresAt time.Time
+}
+
+func (err ErrPairingCodeExpired) Error() string {
+	return fmt.Sprintf("pairing code expired at %s", err.ExpiresAt.Format(time.DateTime))
+}
@@ -0,0 +1,55 @@
+package anova
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func encodePairingCode(claims string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims))
+	return header + "." + payload + ".c2lnbmF0dXJl"
+}
+
+func TestParsePairingCode(t *testing.T) {
+	expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	token := encodePairingCode(fmt.Sprintf(`{"cookerId":"0123456789abcdef","iss":"anova","iat":%d,"exp":%d}`,
+		expiresAt.Add(-24*time.Hour).Unix(), expiresAt.Unix()))
+
+	code, err := ParsePairingCode(token)
+	if err != nil {
+		t.Fatalf("expected success parsing %s: %+v", token, err)
+	}
+	if code.CookerID != "0123456789abcdef" || code.Issuer != "anova" || !code.ExpiresAt.Equal(expiresAt) {
+		t.Fatalf("unexpected claims %+v", code)
+	}
+	if err := code.Validate(); err != nil {
+		t.Fatalf("expected unexpired code to be valid: %+v", err)
+	}
+}
+
+func TestPairingCode_Validate(t *testing.T) {
+	expiredToken := encodePairingCode(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(-time.Minute).Unix()))
+	code, err := ParsePairingCode(expiredToken)
+	if err != nil {
+		t.Fatalf("expected success parsing %s: %+v", expiredToken, err)
+	}
+	if err := code.Validate(); !errors.As(err, &ErrPairingCodeExpired{}) {
+		t.Fatalf("expected expired code to be invalid, got %+v", err)
+	}
+
+	for _, token := range []string{
+		"",
+		"not-a-jwt",
+		"a.!!!.c",
+		"a." + base64.RawURLEncoding.EncodeToString([]byte("[]")) + ".c",
+	} {
+		_, err := ParsePairingCode(token)
+		if !errors.As(err, &ErrInvalidPairingCode{}) {
+			t.Fatalf("expected failure for malformed token \"%s\", got %+v", token, err)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	// Bulb temperatures older than this are ignored when estimating how fast
+	// the oven heats up
+	heatingRateWindow = 5 * time.Minute
+	// Bulb temperatures must span at least this long to estimate a heating rate
+	minHeatingRateSpan = 30 * time.Second
+)
+
+type StageStatus string
+
+const (
+	StageStatusPending    StageStatus = "pending"
+	StageStatusPreheating StageStatus = "preheating"
+	StageStatusRunning    StageStatus = "running"
+	// The stage is waiting for the user to start its timer, or to move on to
+	// the next stage
+	StageStatusAwaitingUser StageStatus = "awaiting_user"
+	StageStatusDone         StageStatus = "done"
+)
+
+// StageProgress is the progress of a stage of the cook. Stages that continue
+// a long timer (see ContinuesTimer) are part of the stage they continue, which
+// keeps the ID of the first of them.
+type StageProgress struct {
+	ID     string
+	Type   dto.StageType
+	Status StageStatus
+	// Length of the stage's timer, or 0 if it has none
+	Timer time.Duration
+	// Estimated time until the stage ends, or nil if it can't be estimated,
+	// e.g. because it ends when the probe reaches its target
+	Remaining *time.Duration
+}
+
+// CookProgress interprets the cook an oven is running.
+type CookProgress struct {
+	CookID string
+	// Index of the active stage in Stages. This is lower than the oven's
+	// ActiveStageIndex once a long timer has been continued.
+	ActiveStageIndex int
+	Stages           []StageProgress
+	Elapsed          time.Duration
+
+	// Estimated time until the active stage and the whole cook end, or nil if
+	// they can't be estimated. Time spent waiting for the user isn't included.
+	StageRemaining *time.Duration
+	Remaining      *time.Duration
+	// When the active preheat stage is expected to reach its setpoint, if it is
+	// preheating
+	PreheatETA *time.Time
+	// How fast the oven has been observed to heat up while preheating, in °C
+	// per minute, or 0 if it hasn't been yet
+	HeatingRate float64
+}
+
+// Progress returns the progress of the oven's cook, or nil if it isn't
+// cooking. Preheat times are estimated from how fast the oven was observed
+// heating up, so they are only available once it has been preheating for a
+// little while. Unlike Oven.State, this is safe to call from any goroutine.
+func (oven *Oven) Progress() *CookProgress {
+	return oven.progress.progress()
+}
+
+type heatingSample struct {
+	at      time.Time
+	celsius float64
+}
+
+// progressTracker follows an oven's states to estimate its progress.
+type progressTracker struct {
+	mutex sync.Mutex
+	state *dto.OvenStateV1
+	// Bulb temperatures of the active preheat stage
+	stageID string
+	samples []heatingSample
+	// Last estimated heating rate, kept for later preheat stages
+	celsiusPerMinute float64
+}
+
+// observe is called with every state update of the oven.
+func (tracker *progressTracker) observe(state *dto.OvenStateV1) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.state = state
+
+	stage := activeStage(state)
+	if stage == nil || stage.Type != dto.StageTypePreheat {
+		tracker.stageID = ""
+		tracker.samples = nil
+		return
+	}
+	if stage.ID != tracker.stageID {
+		tracker.stageID = stage.ID
+		tracker.samples = nil
+	}
+
+	at := stateTime(state)
+	tracker.samples = append(tracker.samples, heatingSample{at: at, celsius: bulbCelsius(state)})
+	for len(tracker.samples) > 0 && at.Sub(tracker.samples[0].at) > heatingRateWindow {
+		tracker.samples = tracker.samples[1:]
+	}
+
+	first, last := tracker.samples[0], tracker.samples[len(tracker.samples)-1]
+	span := last.at.Sub(first.at)
+	if span >= minHeatingRateSpan && last.celsius > first.celsius {
+		tracker.celsiusPerMinute = (last.celsius - first.celsius) / span.Minutes()
+	}
+}
+
+func (tracker *progressTracker) progress() *CookProgress {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	return newCookProgress(tracker.state, tracker.celsiusPerMinute)
+}
+
+// newCookProgress returns nil if the oven isn't cooking, or reports an active
+// stage the cook doesn't have.
+func newCookProgress(state *dto.OvenStateV1, celsiusPerMinute float64) *CookProgress {
+	if state == nil || state.Cook == nil || state.State.Mode != dto.StateModeCook {
+		return nil
+	}
+	cook := state.Cook
+	if cook.ActiveStageIndex < 0 || cook.ActiveStageIndex >= len(cook.Stages) {
+		return nil
+	}
+	progress := &CookProgress{
+		CookID:      cook.CookID,
+		Elapsed:     time.Duration(cook.SecondsElapsed) * time.Second,
+		HeatingRate: celsiusPerMinute,
+	}
+
+	total := time.Duration(0)
+	totalKnown := true
+	// Temperature the oven is at when each stage starts
+	fromCelsius := bulbCelsius(state)
+	for i, stage := range cook.Stages {
+		stageProgress := StageProgress{ID: stage.ID, Type: stage.Type}
+		// Preheat stages carry the timer of the stage they preheat for
+		if stage.Timer != nil && stage.Type != dto.StageTypePreheat {
+			stageProgress.Timer = time.Duration(stage.Timer.Initial) * time.Second
+		}
+
+		var remaining *time.Duration
+		switch {
+		case i < cook.ActiveStageIndex:
+			stageProgress.Status = StageStatusDone
+		case i > cook.ActiveStageIndex:
+			stageProgress.Status = StageStatusPending
+			if stage.Type == dto.StageTypePreheat {
+				remaining = preheatRemaining(fromCelsius, stageSetpointCelsius(stage), celsiusPerMinute)
+			} else if stageProgress.Timer > 0 {
+				timer := stageProgress.Timer
+				remaining = &timer
+			}
+		default:
+			stageProgress.Status, remaining = activeStageProgress(state, stage, stageProgress.Timer, celsiusPerMinute)
+			if stageProgress.Status == StageStatusPreheating && remaining != nil {
+				eta := stateTime(state).Add(*remaining)
+				progress.PreheatETA = &eta
+			}
+		}
+		if setpoint := stageSetpointCelsius(stage); setpoint > 0 {
+			fromCelsius = setpoint
+		}
+
+		stageProgress.Remaining = remaining
+		if i > 0 && ContinuesTimer(cook.Stages[i-1], stage) {
+			continueStage(&progress.Stages[len(progress.Stages)-1], stageProgress)
+		} else {
+			progress.Stages = append(progress.Stages, stageProgress)
+		}
+		if i == cook.ActiveStageIndex {
+			progress.ActiveStageIndex = len(progress.Stages) - 1
+		}
+		if stageProgress.Status == StageStatusDone {
+			continue
+		}
+		if remaining == nil {
+			totalKnown = false
+		} else {
+			total += *remaining
+		}
+	}
+	if totalKnown {
+		progress.Remaining = &total
+	}
+	progress.StageRemaining = progress.Stages[progress.ActiveStageIndex].Remaining
+	return progress
+}
+
+// continueStage adds the progress of a stage continuing a long timer to the
+// stage it continues.
+func continueStage(stage *StageProgress, continuation StageProgress) {
+	stage.Timer += continuation.Timer
+	switch {
+	case stage.Status == StageStatusDone:
+		stage.Status = continuation.Status
+		stage.Remaining = continuation.Remaining
+	case stage.Remaining == nil || continuation.Remaining == nil:
+		stage.Remaining = nil
+	default:
+		remaining := *stage.Remaining + *continuation.Remaining
+		stage.Remaining = &remaining
+	}
+}
+
+// ContinuesTimer reports whether a stage continues the timer of the stage
+// before it. Long cooks (see NewLongCook) run timers longer than the oven
+// allows as a chain of otherwise identical cook stages, each starting as soon
+// as the previous one has run for the longest timer the oven allows.
+func ContinuesTimer(previous dto.Stage, stage dto.Stage) bool {
+	if previous.Type != dto.StageTypeCook || stage.Type != dto.StageTypeCook ||
+		previous.Timer == nil || previous.Timer.Initial != maxTimerSeconds ||
+		stage.Timer == nil || stage.UserActionRequired {
+		return false
+	}
+	previous.ID, stage.ID = "", ""
+	previous.Timer, stage.Timer = nil, nil
+	previous.UserActionRequired = false
+	return reflect.DeepEqual(previous, stage)
+}
+
+func activeStageProgress(state *dto.OvenStateV1, stage dto.Stage, timer time.Duration, celsiusPerMinute float64) (StageStatus, *time.Duration) {
+	if state.Cook.StageTransitionPendingUserAction {
+		none := time.Duration(0)
+		return StageStatusAwaitingUser, &none
+	}
+	if stage.Type == dto.StageTypePreheat {
+		return StageStatusPreheating, preheatRemaining(bulbCelsius(state), stageSetpointCelsius(stage), celsiusPerMinute)
+	}
+	if timer == 0 {
+		return StageStatusRunning, nil
+	}
+
+	status := StageStatusRunning
+	if state.Nodes.Timer.Mode == string(dto.TimerModeIdle) && stage.UserActionRequired {
+		status = StageStatusAwaitingUser
+	}
+	// The timer counts up
+	remaining := max(timer-time.Duration(state.Nodes.Timer.Current)*time.Second, 0)
+	return status, &remaining
+}
+
+// preheatRemaining estimates how long it takes to heat up from one temperature
+// to another. Cooling down is not estimated, and assumed to be immediate.
+func preheatRemaining(fromCelsius float64, toCelsius float64, celsiusPerMinute float64) *time.Duration {
+	remaining := time.Duration(0)
+	if toCelsius <= fromCelsius {
+		return &remaining
+	}
+	if celsiusPerMinute <= 0 {
+		return nil
+	}
+	remaining = time.Duration((toCelsius - fromCelsius) / celsiusPerMinute * float64(time.Minute)).Round(time.Second)
+	return &remaining
+}
+
+// stateTime returns when the state was reported, falling back to now for states
+// without a timestamp.
+func stateTime(state *dto.OvenStateV1) time.Time {
+	if state.UpdatedTimestamp.IsZero() {
+		return time.Now()
+	}
+	return state.UpdatedTimestamp
+}
+
+func activeStage(state *dto.OvenStateV1) *dto.Stage {
+	if state == nil || state.Cook == nil || state.State.Mode != dto.StateModeCook {
+		return nil
+	}
+	index := state.Cook.ActiveStageIndex
+	if index < 0 || index >= len(state.Cook.Stages) {
+		return nil
+	}
+	return &state.Cook.Stages[index]
+}
+
+// bulbCelsius returns the current temperature of the bulb the oven is
+// controlling.
+func bulbCelsius(state *dto.OvenStateV1) float64 {
+	bulbs := state.Nodes.TemperatureBulbs
+	if bulbs.Mode == dto.TemperatureBulbsModeWet {
+		return bulbs.Wet.Current.Celsius
+	}
+	return bulbs.Dry.Current.Celsius
+}
+
+// stageSetpointCelsius returns the stage's bulb setpoint, or 0 if it has none.
+func stageSetpointCelsius(stage dto.Stage) float64 {
+	bulbs := stage.TemperatureBulbs
+	switch {
+	case bulbs == nil:
+		return 0
+	case bulbs.Mode == dto.TemperatureBulbsModeWet && bulbs.Wet != nil:
+		return bulbs.Wet.Setpoint.Celsius
+	case bulbs.Dry != nil:
+		return bulbs.Dry.Setpoint.Celsius
+	}
+	return 0
+}
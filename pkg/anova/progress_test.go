@@ -0,0 +1,156 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"testing"
+	"time"
+)
+
+// progressStages returns the stages of a cook at 60 °C for an hour, then at
+// 200 °C until the probe reaches 55 °C, each with a preheat stage.
+func progressStages(t *testing.T) []dto.Stage {
+	t.Helper()
+	cook, err := NewCook(
+		NewCookStage(RackPositionMiddle, FanSpeedHigh, NewSetpoint(60, TemperatureModeDry), RearElementOnly, NoSteam,
+			NewTimer(time.Hour, TimerTriggerWhenPreheated)),
+		NewCookStage(RackPositionMiddle, FanSpeedHigh, NewSetpoint(200, TemperatureModeDry), RearElementOnly, NoSteam,
+			NewProbeCelsius(55)),
+	)
+	if err != nil {
+		t.Fatalf("expected valid cook: %+v", err)
+	}
+
+	var stages []dto.Stage
+	for _, stage := range CookStages(cook.Stages).ToDto() {
+		stages = append(stages, dto.Stage{
+			ID:                 stage.ID,
+			Type:               stage.Type,
+			UserActionRequired: stage.UserActionRequired,
+			Timer:              stage.Timer,
+			TemperatureBulbs:   stage.TemperatureBulbs,
+		})
+	}
+	return stages
+}
+
+func progressState(stages []dto.Stage, activeStageIndex int, bulbCelsius float64, at time.Time) *dto.OvenStateV1 {
+	state := &dto.OvenStateV1{UpdatedTimestamp: at}
+	state.State.Mode = dto.StateModeCook
+	state.Cook = &dto.CookV1{CookID: "cook", ActiveStageIndex: activeStageIndex, Stages: stages}
+	state.Nodes.TemperatureBulbs.Mode = dto.TemperatureBulbsModeDry
+	state.Nodes.TemperatureBulbs.Dry.Current = dto.NewTemperatureFromCelsius(bulbCelsius)
+	return state
+}
+
+func TestOven_Progress(t *testing.T) {
+	oven := &Oven{}
+	if oven.Progress() != nil {
+		t.Fatalf("expected no progress without a state")
+	}
+
+	stages := progressStages(t)
+	start := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	oven.progress.observe(progressState(stages, 0, 20, start))
+	progress := oven.Progress()
+	if progress == nil || progress.Stages[0].Status != StageStatusPreheating || progress.Stages[1].Status != StageStatusPending ||
+		progress.StageRemaining != nil || progress.PreheatETA != nil {
+		t.Fatalf("expected preheat without an estimate at first, got %+v", progress)
+	}
+
+	// Heating at 10 °C per minute
+	oven.progress.observe(progressState(stages, 0, 30, start.Add(time.Minute)))
+	progress = oven.Progress()
+	if progress.HeatingRate != 10 || progress.StageRemaining == nil || *progress.StageRemaining != 3*time.Minute ||
+		progress.PreheatETA == nil || !progress.PreheatETA.Equal(start.Add(4*time.Minute)) {
+		t.Fatalf("unexpected preheat estimate %+v", progress)
+	}
+	// The probe stage's end can't be estimated
+	if progress.Remaining != nil || progress.Stages[2].Remaining == nil || *progress.Stages[2].Remaining != 14*time.Minute {
+		t.Errorf("unexpected estimates %+v", progress.Stages)
+	}
+
+	running := progressState(stages, 1, 60, start.Add(5*time.Minute))
+	running.Nodes.Timer = dto.TimerNodeV1{Mode: string(dto.TimerModeRunning), Initial: 3600, Current: 600}
+	oven.progress.observe(running)
+	progress = oven.Progress()
+	if progress.Stages[0].Status != StageStatusDone || progress.Stages[1].Status != StageStatusRunning ||
+		progress.StageRemaining == nil || *progress.StageRemaining != 50*time.Minute || progress.PreheatETA != nil {
+		t.Errorf("unexpected running stage %+v", progress)
+	}
+
+	idle := &dto.OvenStateV1{}
+	idle.State.Mode = dto.StateModeIdle
+	oven.progress.observe(idle)
+	if oven.Progress() != nil {
+		t.Errorf("expected no progress while idle")
+	}
+}
+
+func TestOven_Progress_Remaining(t *testing.T) {
+	state := progressState(progressStages(t), 1, 60, time.Now())
+	// Without the probe stage, the cook ends with the timer
+	state.Cook.Stages = state.Cook.Stages[:2]
+	state.Nodes.Timer = dto.TimerNodeV1{Mode: string(dto.TimerModeRunning), Initial: 3600, Current: 3000}
+	progress := newCookProgress(state, 0)
+	if progress.Remaining == nil || *progress.Remaining != 10*time.Minute {
+		t.Errorf("unexpected remaining time %+v", progress)
+	}
+
+	state.Cook.StageTransitionPendingUserAction = true
+	progress = newCookProgress(state, 0)
+	if progress.Stages[1].Status != StageStatusAwaitingUser {
+		t.Errorf("expected stage to await the user, got %+v", progress.Stages[1])
+	}
+
+	for _, index := range []int{-1, 2} {
+		state.Cook.ActiveStageIndex = index
+		if progress := newCookProgress(state, 0); progress != nil {
+			t.Errorf("expected no progress for active stage %d, got %+v", index, progress)
+		}
+	}
+}
+
+func TestOven_Progress_LongCook(t *testing.T) {
+	cook, err := NewLongCook(
+		NewCookStage(RackPositionMiddle, FanSpeedHigh, NewSetpoint(60, TemperatureModeDry), RearElementOnly, NoSteam,
+			NewTimer(240*time.Hour, TimerTriggerWhenPreheated)),
+		NewCookStage(RackPositionMiddle, FanSpeedHigh, NewSetpoint(200, TemperatureModeDry), RearElementOnly, NoSteam,
+			NewProbeCelsius(55)),
+	)
+	if err != nil {
+		t.Fatalf("expected valid long cook: %+v", err)
+	}
+	var stages []dto.Stage
+	for _, stage := range CookStages(cook.Stages).ToDto() {
+		stages = append(stages, dto.Stage{
+			ID:                 stage.ID,
+			Type:               stage.Type,
+			UserActionRequired: stage.UserActionRequired,
+			Timer:              stage.Timer,
+			TemperatureBulbs:   stage.TemperatureBulbs,
+		})
+	}
+
+	// The oven runs the 240 hour timer as three stages, the second of which has
+	// run for an hour
+	state := progressState(stages, 2, 60, time.Now())
+	state.Nodes.Timer = dto.TimerNodeV1{Mode: string(dto.TimerModeRunning), Initial: maxTimerSeconds, Current: 3600}
+	progress := newCookProgress(state, 0)
+	remaining := 240*time.Hour - (maxTimerSeconds+3600)*time.Second
+	if len(progress.Stages) != 4 || progress.ActiveStageIndex != 1 {
+		t.Fatalf("expected the chain to be a single stage, got %+v", progress)
+	}
+	if stage := progress.Stages[1]; stage.ID != stages[1].ID || stage.Status != StageStatusRunning ||
+		stage.Timer != 240*time.Hour || stage.Remaining == nil || *stage.Remaining != remaining {
+		t.Errorf("unexpected long stage %+v", stage)
+	}
+	if progress.StageRemaining == nil || *progress.StageRemaining != remaining {
+		t.Errorf("unexpected remaining time of the stage %+v", progress.StageRemaining)
+	}
+
+	// The preheat stage after the chain
+	progress = newCookProgress(progressState(stages, 4, 60, time.Now()), 0)
+	if progress.ActiveStageIndex != 2 || progress.Stages[1].Status != StageStatusDone {
+		t.Errorf("expected the chain to be done, got %+v", progress)
+	}
+}
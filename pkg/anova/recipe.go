@@ -0,0 +1,270 @@
+package anova
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Recipe is a JSON-friendly description of a cook, for use by integrations
+// that receive cooks from outside of Go, e.g. over MQTT or HTTP.
+type Recipe struct {
+	// Optional; see Cook.Name
+	Name   string        `json:"name,omitempty"`
+	Stages []RecipeStage `json:"stages"`
+	// Allow timers longer than 99 hours 59 minutes; see NewLongCook
+	Long bool `json:"long,omitempty"`
+	// Optional; see Cook.End
+	End *RecipeEnd `json:"end,omitempty"`
+}
+
+// RecipeStage is a JSON-friendly description of a CookStage. Optional fields
+// fall back to the same defaults as the URL handler.
+type RecipeStage struct {
+	// Either "dry" or "wet" (sous vide)
+	Mode               TemperatureMode `json:"mode"`
+	TemperatureCelsius float64         `json:"temperatureCelsius"`
+	// Instead of TemperatureCelsius, in either unit, e.g. "135F" or "57.5C"
+	Temperature *Temperature `json:"temperature,omitempty"`
+	// Any of "top", "rear" and "bottom"
+	HeatingElements []string `json:"heatingElements"`
+
+	// Relative humidity in wet mode, which defaults to 100% as in the app.
+	// Defaults to no steam in dry mode.
+	SteamPercentage *float64 `json:"steamPercentage,omitempty"`
+	// Defaults to FanSpeedHigh
+	FanSpeed *int `json:"fanSpeed,omitempty"`
+	// Defaults to RackPositionMiddle
+	RackPosition *int `json:"rackPosition,omitempty"`
+
+	// At most one of Timer and Probe may be set. Without either, the stage runs
+	// until stopped.
+	Timer *RecipeTimer `json:"timer,omitempty"`
+	Probe *RecipeProbe `json:"probe,omitempty"`
+
+	// Defaults to closed
+	VentOpen bool `json:"ventOpen,omitempty"`
+
+	// Optional text and media shown on the oven and in the app
+	Title             string `json:"title,omitempty"`
+	Description       string `json:"description,omitempty"`
+	PhotoURL          string `json:"photoUrl,omitempty"`
+	VideoURL          string `json:"videoUrl,omitempty"`
+	VideoThumbnailURL string `json:"videoThumbnailUrl,omitempty"`
+}
+
+type RecipeTimer struct {
+	// Go duration string, e.g. "1h30m"
+	Duration string       `json:"duration"`
+	Trigger  TimerTrigger `json:"trigger,omitempty"`
+}
+
+type RecipeProbe struct {
+	TemperatureCelsius float64 `json:"temperatureCelsius"`
+	// Instead of TemperatureCelsius, in either unit
+	Temperature *Temperature `json:"temperature,omitempty"`
+}
+
+// RecipeEnd describes the stage ending a cook. Exactly one of Stop and KeepWarm
+// must be set.
+type RecipeEnd struct {
+	Stop     *RecipeStop     `json:"stop,omitempty"`
+	KeepWarm *RecipeKeepWarm `json:"keepWarm,omitempty"`
+}
+
+type RecipeStop struct {
+	// Optional Go duration string, e.g. "10m"
+	Timer string `json:"timer,omitempty"`
+}
+
+type RecipeKeepWarm struct {
+	// Defaults to "dry"
+	Mode               TemperatureMode `json:"mode,omitempty"`
+	TemperatureCelsius float64         `json:"temperatureCelsius"`
+	// Instead of TemperatureCelsius, in either unit
+	Temperature *Temperature `json:"temperature,omitempty"`
+	// Defaults to the rear heating element
+	HeatingElements []string `json:"heatingElements,omitempty"`
+}
+
+// NewCook validates the recipe and converts it into a cook that can be started.
+// Recipes that can't be converted fail with ErrInvalidRecipe, and cooks that
+// break the oven's rules with ErrValidation.
+func (recipe Recipe) NewCook() (*Cook, error) {
+	if len(recipe.Stages) == 0 {
+		return nil, ErrInvalidRecipe{Reason: "recipe has no stages"}
+	}
+
+	stages := make([]*CookStage, 0, len(recipe.Stages))
+	for i, recipeStage := range recipe.Stages {
+		stage, err := recipeStage.NewCookStage()
+		if err != nil {
+			return nil, ErrInvalidRecipe{Reason: fmt.Sprintf("stage %d: %s", i+1, err)}
+		}
+		stages = append(stages, stage)
+	}
+
+	cook := &Cook{
+		Stages: stages,
+		Name:   recipe.Name,
+
+		id:              generateRandomCookUuid(),
+		maxTimerSeconds: maxTimerSeconds,
+	}
+	if recipe.Long {
+		cook.maxTimerSeconds = maxLongCookTimerSeconds
+	}
+	if recipe.End != nil {
+		end, err := recipe.End.NewCookEnd()
+		if err != nil {
+			return nil, ErrInvalidRecipe{Reason: fmt.Sprintf("end: %s", err)}
+		}
+		cook.End = end
+	}
+
+	// Report every broken rule at once, rather than stage by stage
+	if err := cook.Validate(); err != nil {
+		return nil, err
+	}
+	return cook, nil
+}
+
+// NewCookEnd converts the recipe's end into a cook end. The returned end still
+// needs to be validated, which NewCook does.
+func (recipeEnd RecipeEnd) NewCookEnd() (CookEnd, error) {
+	switch {
+	case (recipeEnd.Stop == nil) == (recipeEnd.KeepWarm == nil):
+		return nil, fmt.Errorf("exactly one of stop and keepWarm must be set")
+	case recipeEnd.Stop != nil:
+		timer := time.Duration(0)
+		if recipeEnd.Stop.Timer != "" {
+			var err error
+			timer, err = time.ParseDuration(recipeEnd.Stop.Timer)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timer duration \"%s\"", recipeEnd.Stop.Timer)
+			}
+		}
+		return NewStop(timer), nil
+	}
+
+	keepWarm := recipeEnd.KeepWarm
+	mode := keepWarm.Mode
+	if mode == "" {
+		mode = TemperatureModeDry
+	}
+	temperature, err := recipeTemperature(keepWarm.TemperatureCelsius, keepWarm.Temperature)
+	if err != nil {
+		return nil, err
+	}
+	heatingElements := RearElementOnly
+	if len(keepWarm.HeatingElements) > 0 {
+		heatingElements, err = parseHeatingElements(keepWarm.HeatingElements)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewKeepWarm(NewSetpointTemperature(temperature, mode), heatingElements), nil
+}
+
+// NewCookStage converts the recipe stage into a cook stage. The returned stage
+// still needs to be validated, which NewCook does.
+func (recipeStage RecipeStage) NewCookStage() (*CookStage, error) {
+	temperature, err := recipeTemperature(recipeStage.TemperatureCelsius, recipeStage.Temperature)
+	if err != nil {
+		return nil, err
+	}
+	heatingElements, err := parseHeatingElements(recipeStage.HeatingElements)
+	if err != nil {
+		return nil, err
+	}
+
+	steamPercentage := NoSteam
+	if recipeStage.SteamPercentage != nil && (recipeStage.Mode == TemperatureModeWet || *recipeStage.SteamPercentage != 0) {
+		steamPercentage = NewSteamPercentage(*recipeStage.SteamPercentage)
+	}
+
+	fanSpeed := FanSpeedHigh
+	if recipeStage.FanSpeed != nil {
+		fanSpeed = NewFanSpeed(*recipeStage.FanSpeed)
+	}
+
+	rackPosition := RackPositionMiddle
+	if recipeStage.RackPosition != nil {
+		rackPosition = RackPosition(*recipeStage.RackPosition)
+	}
+
+	var terminator StageEndCondition
+	switch {
+	case recipeStage.Timer != nil && recipeStage.Probe != nil:
+		return nil, fmt.Errorf("a stage cannot have both a timer and a probe")
+	case recipeStage.Timer != nil:
+		duration, err := time.ParseDuration(recipeStage.Timer.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timer duration \"%s\"", recipeStage.Timer.Duration)
+		}
+		trigger := recipeStage.Timer.Trigger
+		if trigger == "" {
+			trigger = TimerTriggerWhenPreheated
+		}
+		terminator = NewTimer(duration, trigger)
+	case recipeStage.Probe != nil:
+		probeTemperature, err := recipeTemperature(recipeStage.Probe.TemperatureCelsius, recipeStage.Probe.Temperature)
+		if err != nil {
+			return nil, fmt.Errorf("probe: %s", err)
+		}
+		terminator = NewProbe(probeTemperature)
+	}
+
+	stage := NewCookStage(
+		rackPosition,
+		fanSpeed,
+		NewSetpointTemperature(temperature, recipeStage.Mode),
+		heatingElements,
+		steamPercentage,
+		terminator)
+	stage.Title = recipeStage.Title
+	stage.Description = recipeStage.Description
+	stage.PhotoURL = recipeStage.PhotoURL
+	stage.VideoURL = recipeStage.VideoURL
+	stage.VideoThumbnailURL = recipeStage.VideoThumbnailURL
+	stage.VentOpen = recipeStage.VentOpen
+	return stage, nil
+}
+
+// recipeTemperature returns the temperature given either in Celsius or as a
+// Temperature.
+func recipeTemperature(celsius float64, temperature *Temperature) (Temperature, error) {
+	if temperature == nil {
+		return NewTemperatureCelsius(celsius), nil
+	}
+	if celsius != 0 {
+		return Temperature{}, fmt.Errorf("only one of temperature and temperatureCelsius can be set")
+	}
+	return *temperature, nil
+}
+
+// parseHeatingElements parses any of "top", "rear" and "bottom".
+func parseHeatingElements(elements []string) (HeatingElements, error) {
+	var heatingElements HeatingElements
+	for _, element := range elements {
+		switch strings.ToLower(element) {
+		case "top":
+			heatingElements.Top = true
+		case "rear":
+			heatingElements.Rear = true
+		case "bottom":
+			heatingElements.Bottom = true
+		default:
+			return HeatingElements{}, fmt.Errorf("invalid heating element \"%s\"", element)
+		}
+	}
+	return heatingElements, nil
+}
+
+type ErrInvalidRecipe struct {
+	Reason string
+}
+
+func (err ErrInvalidRecipe) Error() string {
+	return fmt.Sprintf("invalid recipe: %s", err.Reason)
+}
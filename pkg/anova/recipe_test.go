@@ -0,0 +1,245 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecipe_NewCook(t *testing.T) {
+	var recipe Recipe
+	err := json.Unmarshal([]byte(`{"stages":[
+		{"mode":"wet","temperatureCelsius":60,"heatingElements":["rear"],"steamPercentage":100,"timer":{"duration":"1h30m"}},
+		{"mode":"dry","temperatureCelsius":220,"heatingElements":["top","bottom"],"fanSpeed":33,"probe":{"temperatureCelsius":57}}
+	]}`), &recipe)
+	if err != nil {
+		t.Fatalf("unmarshal failed: %+v", err)
+	}
+
+	cook, err := recipe.NewCook()
+	if err != nil {
+		t.Fatalf("expected valid recipe: %+v", err)
+	}
+	if len(cook.Stages) != 2 {
+		t.Fatalf("expected 2 stages, got %d", len(cook.Stages))
+	}
+
+	sousVide := cook.Stages[0]
+	if sousVide.TemperatureSetpoint != NewSousVideSetpointCelsius(60) ||
+		*sousVide.SteamPercentage != 100 || sousVide.FanSpeed != FanSpeedHigh || sousVide.RackPosition != RackPositionMiddle {
+		t.Errorf("unexpected first stage %+v", sousVide)
+	}
+	if timer, ok := sousVide.Terminator.(*Timer); !ok || *timer != *NewTimer(90*time.Minute, TimerTriggerWhenPreheated) {
+		t.Errorf("unexpected first stage terminator %+v", sousVide.Terminator)
+	}
+
+	sear := cook.Stages[1]
+	if sear.HeatingElements != NewHeatingElements(true, false, true) || sear.SteamPercentage != NoSteam {
+		t.Errorf("unexpected second stage %+v", sear)
+	}
+	if probe, ok := sear.Terminator.(*Probe); !ok || probe.TemperatureCelsius != 57 {
+		t.Errorf("unexpected second stage terminator %+v", sear.Terminator)
+	}
+}
+
+func TestRecipe_NewCook_Invalid(t *testing.T) {
+	for _, recipe := range []string{
+		`{"stages":[]}`,
+		`{"stages":[{"mode":"dry","temperatureCelsius":200,"heatingElements":["side"]}]}`,
+		`{"stages":[{"mode":"dry","temperatureCelsius":200,"heatingElements":["rear"],"timer":{"duration":"soon"}}]}`,
+		`{"stages":[{"mode":"dry","temperatureCelsius":200,"heatingElements":["rear"],"timer":{"duration":"1h"},"probe":{"temperatureCelsius":60}}]}`,
+	} {
+		var decoded Recipe
+		err := json.Unmarshal([]byte(recipe), &decoded)
+		if err != nil {
+			t.Fatalf("unmarshal failed: %+v", err)
+		}
+
+		_, err = decoded.NewCook()
+		if !errors.As(err, &ErrInvalidRecipe{}) {
+			t.Errorf("expected invalid recipe error for %s, got %+v", recipe, err)
+		}
+	}
+
+	// Broken rules are reported as validation errors
+	tooHot := Recipe{Stages: []RecipeStage{{Mode: TemperatureModeDry, TemperatureCelsius: 300, HeatingElements: []string{"rear"}}}}
+	if _, err := tooHot.NewCook(); !errors.As(err, &ErrValidation{}) {
+		t.Errorf("expected validation error for %+v, got %+v", tooHot, err)
+	}
+}
+
+func TestRecipe_NewCook_Metadata(t *testing.T) {
+	recipe := Recipe{Name: "Sourdough", Stages: []RecipeStage{
+		{
+			Mode:               TemperatureModeWet,
+			TemperatureCelsius: 100,
+			HeatingElements:    []string{"top", "rear"},
+			SteamPercentage:    func() *float64 { steam := 100.0; return &steam }(),
+			Timer:              &RecipeTimer{Duration: "20m"},
+			Title:              "Bake with steam",
+			Description:        "Score the loaf before it goes in.",
+			PhotoURL:           "https://example.com/loaf.jpg",
+		},
+		{Mode: TemperatureModeDry, TemperatureCelsius: 230, HeatingElements: []string{"top", "rear"}, Timer: &RecipeTimer{Duration: "25m"}},
+	}}
+	cook, err := recipe.NewCook()
+	if err != nil {
+		t.Fatalf("expected valid recipe: %+v", err)
+	}
+	// As done by Cook.Start
+	for _, stage := range cook.Stages {
+		stage.cook = cook
+	}
+
+	dtoStages := CookStages(cook.Stages).ToDto()
+	if len(dtoStages) != 4 {
+		t.Fatalf("expected preheat and cook stages, got %+v", dtoStages)
+	}
+	for _, stage := range dtoStages[:2] {
+		if stage.Title.String != "Bake with steam" || *stage.Description != "Score the loaf before it goes in." ||
+			*stage.PhotoUrl != "https://example.com/loaf.jpg" || stage.VideoUrl != nil {
+			t.Errorf("unexpected metadata of first stage %+v", stage)
+		}
+	}
+	// Stages without a title show the cook's name
+	for _, stage := range dtoStages[2:] {
+		if stage.Title.String != "Sourdough" || stage.Description != nil || stage.PhotoUrl != nil {
+			t.Errorf("unexpected metadata of second stage %+v", stage)
+		}
+	}
+}
+
+func TestRecipe_NewCook_End(t *testing.T) {
+	var recipe Recipe
+	err := json.Unmarshal([]byte(`{
+		"stages":[{"mode":"dry","temperatureCelsius":220,"heatingElements":["top","rear"],"ventOpen":true,"timer":{"duration":"10m"}}],
+		"end":{"keepWarm":{"temperatureCelsius":65}}
+	}`), &recipe)
+	if err != nil {
+		t.Fatalf("unmarshal failed: %+v", err)
+	}
+	cook, err := recipe.NewCook()
+	if err != nil {
+		t.Fatalf("expected valid recipe: %+v", err)
+	}
+
+	// Preheat, cook, then keep warm straight away
+	dtoStages := cook.toDto()
+	if len(dtoStages) != 3 {
+		t.Fatalf("expected 3 stages, got %+v", dtoStages)
+	}
+	if stage := dtoStages[1].(dto.CookingStage); !stage.Vent.Open {
+		t.Errorf("expected vent to be open, got %+v", stage)
+	}
+	keepWarm, ok := dtoStages[2].(dto.CookingStage)
+	if !ok || keepWarm.Type != dto.StageTypeCook || keepWarm.Vent.Open || keepWarm.Timer != nil ||
+		keepWarm.TemperatureBulbs.Dry.Setpoint.Celsius != 65 || !keepWarm.HeatingElements.Rear.On {
+		t.Errorf("unexpected keep warm stage %+v", dtoStages[2])
+	}
+	if again := cook.toDto(); again[2].(dto.CookingStage).ID != keepWarm.ID {
+		t.Errorf("expected keep warm stage to keep its ID")
+	}
+
+	recipe.End = &RecipeEnd{Stop: &RecipeStop{Timer: "10m"}}
+	cook, err = recipe.NewCook()
+	if err != nil {
+		t.Fatalf("expected valid recipe: %+v", err)
+	}
+	dtoStages = cook.toDto()
+	if stop, ok := dtoStages[len(dtoStages)-1].(dto.StopStage); !ok || stop.Type != dto.StageTypeStop || stop.Timer.Initial != 600 {
+		t.Errorf("unexpected stop stage %+v", dtoStages[len(dtoStages)-1])
+	}
+
+	for _, end := range []RecipeEnd{
+		{},
+		{Stop: &RecipeStop{}, KeepWarm: &RecipeKeepWarm{TemperatureCelsius: 65}},
+		{Stop: &RecipeStop{Timer: "later"}},
+	} {
+		recipe.End = &end
+		if _, err := recipe.NewCook(); !errors.As(err, &ErrInvalidRecipe{}) {
+			t.Errorf("expected invalid recipe error for end %+v, got %+v", end, err)
+		}
+	}
+	for _, end := range []RecipeEnd{
+		{Stop: &RecipeStop{Timer: "100h"}},
+		{KeepWarm: &RecipeKeepWarm{TemperatureCelsius: 300}},
+		{KeepWarm: &RecipeKeepWarm{TemperatureCelsius: 65, HeatingElements: []string{"rear", "bottom"}}},
+	} {
+		recipe.End = &end
+		if _, err := recipe.NewCook(); !errors.As(err, &ErrValidation{}) {
+			t.Errorf("expected validation error for end %+v, got %+v", end, err)
+		}
+	}
+}
+
+func TestRecipe_NewCook_Long(t *testing.T) {
+	recipe := Recipe{Stages: []RecipeStage{{
+		Mode:               TemperatureModeWet,
+		TemperatureCelsius: 60,
+		HeatingElements:    []string{"rear"},
+		Timer:              &RecipeTimer{Duration: "240h"},
+	}}}
+	if _, err := recipe.NewCook(); !errors.As(err, &ErrValidation{}) {
+		t.Fatalf("expected timer to be too long for a normal cook, got %+v", err)
+	}
+
+	recipe.Long = true
+	cook, err := recipe.NewCook()
+	if err != nil {
+		t.Fatalf("expected valid long cook: %+v", err)
+	}
+
+	// Preheat, then the timer split across three stages
+	dtoStages := CookStages(cook.Stages).ToDto()
+	if len(dtoStages) != 4 || dtoStages[0].Type != dto.StageTypePreheat {
+		t.Fatalf("unexpected stages %+v", dtoStages)
+	}
+	total := 0
+	for _, stage := range dtoStages[1:] {
+		if stage.Type != dto.StageTypeCook || stage.Timer.Initial > maxTimerSeconds {
+			t.Errorf("unexpected chained stage %+v", stage)
+		}
+		total += stage.Timer.Initial
+	}
+	if total != 240*60*60 {
+		t.Errorf("expected chained timers to add up to 240h, got %ds", total)
+	}
+
+	// The chain keeps its IDs, so it can be updated and followed
+	ids := cook.Stages[0].StageIDs()
+	if len(ids) != 4 || ids[3] != dtoStages[3].ID {
+		t.Errorf("unexpected stage IDs %v", ids)
+	}
+}
+
+func TestRecipe_NewCook_Temperature(t *testing.T) {
+	var recipe Recipe
+	err := json.Unmarshal([]byte(`{"stages":[
+		{"mode":"wet","temperature":"135F","heatingElements":["rear"],"probe":{"temperature":"131f"}}
+	], "end":{"keepWarm":{"temperature":"57.5C"}}}`), &recipe)
+	if err != nil {
+		t.Fatalf("unmarshal failed: %+v", err)
+	}
+	cook, err := recipe.NewCook()
+	if err != nil {
+		t.Fatalf("expected valid recipe: %+v", err)
+	}
+
+	stages := cook.toDto()
+	if setpoint := stages[0].(dto.CookingStage).TemperatureBulbs.Wet.Setpoint; setpoint != (dto.Temperature{Celsius: 57.2, Fahrenheit: 135}) {
+		t.Errorf("unexpected setpoint %+v", setpoint)
+	}
+	if probe := stages[0].(dto.CookingStage).TemperatureProbe.Setpoint; probe != (dto.Temperature{Celsius: 55, Fahrenheit: 131}) {
+		t.Errorf("unexpected probe %+v", probe)
+	}
+	if keepWarm := cook.End.(*KeepWarm); keepWarm.Setpoint != NewNonSousVideSetpointCelsius(57.5) {
+		t.Errorf("unexpected keep warm %+v", keepWarm)
+	}
+
+	recipe.Stages[0].TemperatureCelsius = 57
+	if _, err := recipe.NewCook(); !errors.As(err, &ErrInvalidRecipe{}) {
+		t.Errorf("expected both temperatures to be rejected, got %+v", err)
+	}
+}
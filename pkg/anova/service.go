@@ -61,7 +61,7 @@ func (service *Service) receiveMessages() {
 				return
 			}
 
-			slog.Error("failed to read message from client", err)
+			slog.Error("failed to read message from client", slog.Any("err", err))
 			continue
 		}
 
@@ -85,8 +85,10 @@ func (service *Service) receiveMessages() {
 
 		// List of devices paired with the account
 		case *dto.WifiListEvent:
+			cookerIDs := make([]CookerID, 0, len(*payload))
 			for _, oven := range *payload {
 				cookerID := CookerID(oven.CookerID)
+				cookerIDs = append(cookerIDs, cookerID)
 				if existingOven, exists := service.Ovens[cookerID]; exists {
 					if oven.Name != existingOven.Name {
 						oldName := existingOven.Name
@@ -112,6 +114,8 @@ func (service *Service) receiveMessages() {
 				}
 			}
 
+			service.writeEvent(OvenListUpdated{CookerIDs: cookerIDs})
+
 		// State of a paired deice
 		case *dto.ApoStateEvent:
 			cookerID := CookerID(payload.CookerID)
@@ -133,6 +137,7 @@ func (service *Service) receiveMessages() {
 			previousState := oven.State
 			oven.State = &state
 			oven.LastUpdate = state.UpdatedTimestamp
+			oven.progress.observe(&state)
 
 			if previousState != nil {
 				diffFilter := diff.Filter(func(path []string, parent reflect.Type, field reflect.StructField) bool {
@@ -141,7 +146,7 @@ func (service *Service) receiveMessages() {
 				})
 				changelog, err := diff.Diff(*previousState, state, diffFilter)
 				if err != nil {
-					slog.Error("diffing oven states failed", err)
+					slog.Error("diffing oven states failed", slog.Any("err", err))
 				} else {
 					slog.Debug(fmt.Sprintf("found %d differences in oven state", len(changelog)))
 					for i, change := range changelog {
@@ -159,12 +164,33 @@ func (service *Service) receiveMessages() {
 				}
 
 				if len(changelog) > 0 {
-					service.events <- OvenUpdated{Oven: oven, PreviousState: previousState}
+					service.events <- OvenUpdated{Oven: oven, State: &state, PreviousState: previousState}
 				}
 			} else {
-				service.events <- OvenAdded{Oven: oven}
+				service.events <- OvenAdded{Oven: oven, State: &state}
+			}
+
+			descaleRequired := state.Nodes.SteamGenerators.Boiler.DescaleRequired
+			if descaleRequired && (previousState == nil || !previousState.Nodes.SteamGenerators.Boiler.DescaleRequired) {
+				service.writeEvent(DescaleRequired{Oven: oven})
+			}
+
+			otaUpdate := state.SystemInfo.OtaUpdate
+			if otaUpdate != nil && (previousState == nil || !reflect.DeepEqual(otaUpdate, previousState.SystemInfo.OtaUpdate)) {
+				service.writeEvent(FirmwareUpdateProgress{Oven: oven, Mode: otaUpdate.Mode, Progress: otaUpdate.Progress})
 			}
 
+		// Progress of an over-the-air update
+		case *dto.WifiFirmwareUpdateEvent:
+			oven, exists := service.Ovens[CookerID(payload.CookerID)]
+			if !exists {
+				slog.Warn("received firmware update for unknown oven",
+					slog.Any("fields", payload.Fields))
+				continue
+			}
+
+			service.writeEvent(FirmwareUpdateProgress{Oven: oven, Mode: payload.Mode, Progress: payload.Progress})
+
 		default:
 			slog.Warn("skipping message",
 				slog.Any("requestID", message.RequestID),
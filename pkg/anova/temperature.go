@@ -0,0 +1,158 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The oven is sent temperatures to a tenth of a degree Celsius, which keeps half
+// degrees Celsius and whole degrees Fahrenheit intact.
+const temperatureStepsPerDegreeCelsius = 10
+
+var temperatureRegex = regexp.MustCompile(`^(-?[0-9]+(?:\.[0-9]*)?)\s*°?([cfCF])$`)
+
+// Temperature is a temperature in the unit it was given in. It converts between
+// units exactly, and is only rounded to what the oven accepts when it's sent to
+// the oven, so that e.g. 135 °F isn't cooked at 57 °C.
+//
+// Temperatures are marshalled as text such as "135F" or "57.5C". Bare numbers
+// are unmarshalled as degrees Celsius.
+type Temperature struct {
+	value float64
+	unit  dto.TemperatureUnit
+}
+
+func NewTemperatureCelsius(celsius float64) Temperature {
+	return Temperature{value: celsius, unit: dto.TemperatureUnitCelsius}
+}
+
+func NewTemperatureFahrenheit(fahrenheit float64) Temperature {
+	return Temperature{value: fahrenheit, unit: dto.TemperatureUnitFahrenheit}
+}
+
+// ParseTemperature parses a temperature with its unit, e.g. "212f", "100c",
+// "57.5C" or "135 °F".
+func ParseTemperature(text string) (Temperature, error) {
+	groups := temperatureRegex.FindStringSubmatch(strings.TrimSpace(text))
+	if groups == nil {
+		return Temperature{}, ErrInvalidTemperature{Text: text}
+	}
+	value, err := strconv.ParseFloat(groups[1], 64)
+	if err != nil {
+		return Temperature{}, ErrInvalidTemperature{Text: text}
+	}
+	if strings.EqualFold(groups[2], "f") {
+		return NewTemperatureFahrenheit(value), nil
+	}
+	return NewTemperatureCelsius(value), nil
+}
+
+// Unit returns the unit the temperature was given in.
+func (temperature Temperature) Unit() dto.TemperatureUnit {
+	if temperature.unit == dto.TemperatureUnitFahrenheit {
+		return dto.TemperatureUnitFahrenheit
+	}
+	return dto.TemperatureUnitCelsius
+}
+
+func (temperature Temperature) Celsius() float64 {
+	if temperature.Unit() == dto.TemperatureUnitFahrenheit {
+		return (temperature.value - 32) * 5 / 9
+	}
+	return temperature.value
+}
+
+func (temperature Temperature) Fahrenheit() float64 {
+	if temperature.Unit() == dto.TemperatureUnitFahrenheit {
+		return temperature.value
+	}
+	return temperature.value*9/5 + 32
+}
+
+// In returns the temperature in the given unit. Unknown units fall back to
+// Celsius.
+func (temperature Temperature) In(unit dto.TemperatureUnit) float64 {
+	if unit == dto.TemperatureUnitFahrenheit {
+		return temperature.Fahrenheit()
+	}
+	return temperature.Celsius()
+}
+
+// Rounded returns the temperature the oven will actually use, in Celsius.
+func (temperature Temperature) Rounded() Temperature {
+	return NewTemperatureCelsius(math.Round(temperature.Celsius()*temperatureStepsPerDegreeCelsius) / temperatureStepsPerDegreeCelsius)
+}
+
+// Format formats the temperature in the given unit to a tenth of a degree, e.g.
+// "57.5 °C" or "135 °F". Unknown units fall back to Celsius.
+func (temperature Temperature) Format(unit dto.TemperatureUnit) string {
+	if unit != dto.TemperatureUnitFahrenheit {
+		unit = dto.TemperatureUnitCelsius
+	}
+	value := strconv.FormatFloat(temperature.In(unit), 'f', 1, 64)
+	value = strings.TrimSuffix(value, ".0")
+	if value == "-0" {
+		value = "0"
+	}
+	return fmt.Sprintf("%s °%s", value, unit)
+}
+
+// String formats the temperature in the unit it was given in.
+func (temperature Temperature) String() string {
+	return temperature.Format(temperature.Unit())
+}
+
+func (temperature Temperature) MarshalText() ([]byte, error) {
+	return []byte(strconv.FormatFloat(temperature.value, 'f', -1, 64) + string(temperature.Unit())), nil
+}
+
+// UnmarshalText parses the temperature like ParseTemperature, but also accepts
+// bare numbers as degrees Celsius.
+func (temperature *Temperature) UnmarshalText(text []byte) error {
+	if celsius, err := strconv.ParseFloat(string(text), 64); err == nil {
+		*temperature = NewTemperatureCelsius(celsius)
+		return nil
+	}
+	parsed, err := ParseTemperature(string(text))
+	if err != nil {
+		return err
+	}
+	*temperature = parsed
+	return nil
+}
+
+// UnmarshalJSON accepts strings, e.g. "135F", as well as numbers in degrees
+// Celsius.
+func (temperature *Temperature) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		var celsius float64
+		if err := json.Unmarshal(data, &celsius); err != nil {
+			return ErrInvalidTemperature{Text: string(data)}
+		}
+		text = strconv.FormatFloat(celsius, 'f', -1, 64)
+	}
+	return temperature.UnmarshalText([]byte(text))
+}
+
+// toDto rounds the temperature to what the oven accepts. The Fahrenheit value
+// is rounded from the exact temperature, so that it matches what was asked for.
+func (temperature Temperature) toDto() dto.Temperature {
+	return dto.Temperature{
+		Celsius:    temperature.Rounded().Celsius(),
+		Fahrenheit: math.Round(temperature.Fahrenheit()),
+	}
+}
+
+type ErrInvalidTemperature struct {
+	Text string
+}
+
+func (err ErrInvalidTemperature) Error() string {
+	return fmt.Sprintf("invalid temperature \"%s\"; expected e.g. 100C or 212F", err.Text)
+}
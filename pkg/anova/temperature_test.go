@@ -0,0 +1,98 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseTemperature(t *testing.T) {
+	for _, test := range []struct {
+		text     string
+		expected Temperature
+	}{
+		{"212f", NewTemperatureFahrenheit(212)},
+		{"100c", NewTemperatureCelsius(100)},
+		{"57.5C", NewTemperatureCelsius(57.5)},
+		{"135 °F", NewTemperatureFahrenheit(135)},
+	} {
+		temperature, err := ParseTemperature(test.text)
+		if err != nil || temperature != test.expected {
+			t.Errorf("expected %q to parse as %v, got %v (%+v)", test.text, test.expected, temperature, err)
+		}
+	}
+
+	for _, text := range []string{"", "100", "100k", "f", "1.2.3c"} {
+		if _, err := ParseTemperature(text); !errors.Is(err, ErrInvalidTemperature{Text: text}) {
+			t.Errorf("expected %q to be invalid, got %+v", text, err)
+		}
+	}
+}
+
+func TestTemperature_Conversion(t *testing.T) {
+	// Whole degrees Fahrenheit survive the trip to the oven and back
+	for fahrenheit := 32.0; fahrenheit <= 482; fahrenheit++ {
+		rounded := NewTemperatureFahrenheit(fahrenheit).Rounded()
+		if math.Round(rounded.Fahrenheit()) != fahrenheit {
+			t.Fatalf("expected %g °F to round to itself, got %g °F", fahrenheit, rounded.Fahrenheit())
+		}
+	}
+
+	temperature := NewTemperatureFahrenheit(135)
+	if celsius := temperature.Celsius(); math.Abs(celsius-57.2222) > 0.0001 {
+		t.Errorf("expected 135 °F to be 57.2222 °C, got %g", celsius)
+	}
+	if rounded := temperature.Rounded(); rounded != NewTemperatureCelsius(57.2) {
+		t.Errorf("expected 135 °F to round to 57.2 °C, got %v", rounded)
+	}
+	if dtoTemperature := temperature.toDto(); dtoTemperature != (dto.Temperature{Celsius: 57.2, Fahrenheit: 135}) {
+		t.Errorf("unexpected dto temperature %+v", dtoTemperature)
+	}
+	if NewTemperatureCelsius(57.5).Rounded() != NewTemperatureCelsius(57.5) {
+		t.Error("expected half degrees Celsius to be kept")
+	}
+	if probe := NewProbeFahrenheit(135); math.Abs(probe.TemperatureCelsius-57.2222) > 0.0001 {
+		t.Errorf("expected probe to keep the exact temperature, got %g", probe.TemperatureCelsius)
+	}
+}
+
+func TestTemperature_Format(t *testing.T) {
+	for _, test := range []struct {
+		temperature Temperature
+		unit        dto.TemperatureUnit
+		expected    string
+	}{
+		{NewTemperatureFahrenheit(135), dto.TemperatureUnitCelsius, "57.2 °C"},
+		{NewTemperatureFahrenheit(135), dto.TemperatureUnitFahrenheit, "135 °F"},
+		{NewTemperatureCelsius(57.5), dto.TemperatureUnitFahrenheit, "135.5 °F"},
+		{NewTemperatureCelsius(100), "", "100 °C"},
+	} {
+		if formatted := test.temperature.Format(test.unit); formatted != test.expected {
+			t.Errorf("expected %v in %q to format as %q, got %q", test.temperature, test.unit, test.expected, formatted)
+		}
+	}
+}
+
+func TestTemperature_JSON(t *testing.T) {
+	var temperatures []Temperature
+	if err := json.Unmarshal([]byte(`["135F", "57.5c", 60]`), &temperatures); err != nil {
+		t.Fatalf("unmarshal failed: %+v", err)
+	}
+	expected := []Temperature{NewTemperatureFahrenheit(135), NewTemperatureCelsius(57.5), NewTemperatureCelsius(60)}
+	for i := range expected {
+		if temperatures[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], temperatures[i])
+		}
+	}
+
+	data, err := json.Marshal(temperatures)
+	if err != nil || string(data) != `["135F","57.5C","60C"]` {
+		t.Errorf("unexpected JSON %s (%+v)", data, err)
+	}
+
+	if err := json.Unmarshal([]byte(`"hot"`), &temperatures[0]); !errors.Is(err, ErrInvalidTemperature{Text: "hot"}) {
+		t.Errorf("expected invalid temperature, got %+v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeZoneSyncDelay is how long to wait after a UTC offset change before
+// updating the ovens, so that the new offset is definitely in effect
+const timeZoneSyncDelay = 5 * time.Second
+
+// newTimeZone describes a location as it is in effect at the given time.
+func newTimeZone(location *time.Location, at time.Time) (dto.TimeZone, error) {
+	name, err := locationName(location)
+	if err != nil {
+		return dto.TimeZone{}, ErrUnknownTimeZone{Location: location, Err: err}
+	}
+
+	code, offsetSeconds := at.In(location).Zone()
+	return dto.TimeZone{
+		ID:        name,
+		Code:      code,
+		GMTOffset: offsetSeconds,
+	}, nil
+}
+
+// locationName returns the IANA name of a location. time.Local is always named
+// "Local", so its name is taken from $TZ or /etc/localtime instead, but only if
+// that zone matches the one time.Local was actually loaded with.
+func locationName(location *time.Location) (string, error) {
+	name := location.String()
+	if name != "Local" {
+		return name, nil
+	}
+	return matchLocation(location, localLocationNames())
+}
+
+// localLocationNames lists the names the local time zone may have been loaded
+// from, in the order time.Local looks for it.
+func localLocationNames() []string {
+	var names []string
+	if tz, set := os.LookupEnv("TZ"); set {
+		tz = strings.TrimPrefix(tz, ":")
+		if _, zone, found := strings.Cut(tz, "zoneinfo/"); found {
+			tz = zone
+		}
+		if tz != "" {
+			names = append(names, tz)
+		}
+	}
+
+	target, err := filepath.EvalSymlinks("/etc/localtime")
+	if err == nil {
+		if _, zone, found := strings.Cut(target, "zoneinfo/"); found {
+			names = append(names, zone)
+		}
+	}
+
+	// time.Local falls back to UTC when neither is usable
+	return append(names, "UTC")
+}
+
+// matchLocation returns the first of the names whose zone has the same UTC
+// offsets and abbreviations as the location throughout this year.
+func matchLocation(location *time.Location, names []string) (string, error) {
+	year := time.Now().Year()
+	for _, name := range names {
+		candidate, err := time.LoadLocation(name)
+		if err != nil {
+			continue
+		}
+
+		matches := true
+		for month := time.January; month <= time.December && matches; month++ {
+			at := time.Date(year, month, 1, 12, 0, 0, 0, time.UTC)
+			code, offset := at.In(location).Zone()
+			candidateCode, candidateOffset := at.In(candidate).Zone()
+			matches = code == candidateCode && offset == candidateOffset
+		}
+		if matches {
+			return name, nil
+		}
+	}
+
+	return "", errors.New("unable to determine the name of the local time zone")
+}
+
+// TimeZoneSync keeps the time zone of every oven in sync with a location. Ovens
+// are updated as they are added, and again after every change to the
+// location's UTC offset, e.g. daylight saving time transitions. Ovens it has
+// already set to the current offset are left alone, e.g. when they reconnect.
+type TimeZoneSync struct {
+	location *time.Location
+	// Sends the time zone to the oven; replaced in tests
+	setTimeZone func(oven *Oven, timeZone dto.TimeZone) error
+
+	mutex sync.Mutex
+	ovens map[CookerID]*Oven
+	// Time zone last set on each oven. Ovens don't report theirs.
+	synced map[CookerID]dto.TimeZone
+}
+
+func NewTimeZoneSync(location *time.Location) *TimeZoneSync {
+	return &TimeZoneSync{
+		location: location,
+		setTimeZone: func(oven *Oven, timeZone dto.TimeZone) error {
+			return oven.client.SetTimeZone(oven.CookerID, timeZone)
+		},
+
+		ovens:  make(map[CookerID]*Oven),
+		synced: make(map[CookerID]dto.TimeZone),
+	}
+}
+
+func (timeZoneSync *TimeZoneSync) HandleEvent(event Event) {
+	switch event := event.(type) {
+	case OvenAdded:
+		timeZoneSync.mutex.Lock()
+		timeZoneSync.ovens[event.Oven.CookerID] = event.Oven
+		timeZoneSync.mutex.Unlock()
+
+		timeZoneSync.syncOven(event.Oven)
+	}
+}
+
+// Run blocks until stop is closed, updating every known oven each time the
+// location's UTC offset changes.
+func (timeZoneSync *TimeZoneSync) Run(stop <-chan struct{}) {
+	for {
+		_, nextChange := time.Now().In(timeZoneSync.location).ZoneBounds()
+		if nextChange.IsZero() {
+			// The location never changes offset again
+			<-stop
+			return
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(time.Until(nextChange) + timeZoneSyncDelay):
+		}
+
+		slog.Info("time zone offset changed; updating ovens",
+			slog.String("location", timeZoneSync.location.String()))
+
+		timeZoneSync.mutex.Lock()
+		ovens := make([]*Oven, 0, len(timeZoneSync.ovens))
+		for _, oven := range timeZoneSync.ovens {
+			ovens = append(ovens, oven)
+		}
+		timeZoneSync.mutex.Unlock()
+
+		for _, oven := range ovens {
+			timeZoneSync.syncOven(oven)
+		}
+	}
+}
+
+func (timeZoneSync *TimeZoneSync) syncOven(oven *Oven) {
+	timeZone, err := newTimeZone(timeZoneSync.location, time.Now())
+	if err == nil {
+		timeZoneSync.mutex.Lock()
+		synced, exists := timeZoneSync.synced[oven.CookerID]
+		timeZoneSync.mutex.Unlock()
+		if exists && synced == timeZone {
+			return
+		}
+		err = timeZoneSync.setTimeZone(oven, timeZone)
+	}
+	if err != nil {
+		slog.Error("failed to set oven time zone",
+			slog.String("cookerID", string(oven.CookerID)),
+			slog.Any("err", err))
+		return
+	}
+
+	timeZoneSync.mutex.Lock()
+	timeZoneSync.synced[oven.CookerID] = timeZone
+	timeZoneSync.mutex.Unlock()
+}
+
+type ErrUnknownTimeZone struct {
+	Location *time.Location
+	Err      error
+}
+
+func (err ErrUnknownTimeZone) Error() string {
+	return fmt.Sprintf("unknown time zone \"%s\": %s", err.Location, err.Err)
+}
@@ -0,0 +1,78 @@
+package anova
+
+import (
+	"apocli/pkg/anova/dto"
+	"testing"
+	"time"
+)
+
+func TestNewTimeZone(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %+v", err)
+	}
+
+	for _, testCase := range []struct {
+		at       time.Time
+		expected dto.TimeZone
+	}{
+		{
+			time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC),
+			dto.TimeZone{ID: "America/New_York", Code: "EST", GMTOffset: -5 * 60 * 60},
+		},
+		{
+			time.Date(2024, time.July, 15, 12, 0, 0, 0, time.UTC),
+			dto.TimeZone{ID: "America/New_York", Code: "EDT", GMTOffset: -4 * 60 * 60},
+		},
+	} {
+		timeZone, err := newTimeZone(location, testCase.at)
+		if err != nil {
+			t.Fatalf("unexpected error: %+v", err)
+		}
+		if timeZone != testCase.expected {
+			t.Errorf("unexpected time zone at %s. expected %+v, got %+v", testCase.at, testCase.expected, timeZone)
+		}
+	}
+}
+
+func TestMatchLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %+v", err)
+	}
+
+	name, err := matchLocation(berlin, []string{"America/New_York", "Not/AZone", "Europe/Paris", "Europe/Berlin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	// Paris has had the same offsets as Berlin for decades, which is all the
+	// oven is told
+	if name != "Europe/Paris" {
+		t.Errorf("expected the first matching zone, got \"%s\"", name)
+	}
+
+	// A stale $TZ must not be trusted
+	_, err = matchLocation(berlin, []string{"America/New_York", "UTC"})
+	if err == nil {
+		t.Errorf("expected an error when no zone matches")
+	}
+}
+
+func TestTimeZoneSync_SkipsSyncedOvens(t *testing.T) {
+	timeZoneSync := NewTimeZoneSync(time.UTC)
+	var sent []CookerID
+	timeZoneSync.setTimeZone = func(oven *Oven, timeZone dto.TimeZone) error {
+		sent = append(sent, oven.CookerID)
+		return nil
+	}
+
+	kitchen := &Oven{CookerID: "kitchen"}
+	timeZoneSync.HandleEvent(OvenAdded{Oven: kitchen})
+	timeZoneSync.HandleEvent(OvenAdded{Oven: &Oven{CookerID: "garage"}})
+	// The oven reconnecting doesn't need its unchanged time zone again
+	timeZoneSync.HandleEvent(OvenAdded{Oven: kitchen})
+
+	if len(sent) != 2 || sent[0] != "kitchen" || sent[1] != "garage" {
+		t.Errorf("expected each oven to be set once, got %v", sent)
+	}
+}
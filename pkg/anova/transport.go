@@ -0,0 +1,112 @@
+package anova
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"net"
+	"sync"
+	"time"
+)
+
+// maxSocketFrameSize bounds a single frame read from a daemon socket. Oven
+// state messages are a few kilobytes.
+const maxSocketFrameSize = 4 * 1024 * 1024
+
+// errTransportClosed is returned by transport.ReadMessage after the transport
+// has been closed locally.
+var errTransportClosed = errors.New("transport closed")
+
+// transport carries JSON-encoded dto.Message frames between a Client and
+// either the Anova backend or a daemon.
+type transport interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(frame []byte) error
+	Close() error
+}
+
+type websocketTransport struct {
+	conn *websocket.Conn
+}
+
+func (transport *websocketTransport) ReadMessage() ([]byte, error) {
+	_, frame, err := transport.conn.ReadMessage()
+	if errors.Is(err, websocket.ErrCloseSent) {
+		return nil, errTransportClosed
+	}
+	return frame, err
+}
+
+func (transport *websocketTransport) WriteMessage(frame []byte) error {
+	return transport.conn.WriteMessage(websocket.TextMessage, frame)
+}
+
+func (transport *websocketTransport) Close() error {
+	err := transport.conn.WriteMessage(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	if err != nil {
+		return err
+	}
+
+	// Give the backend a chance to acknowledge the close
+	<-time.After(time.Second)
+	return nil
+}
+
+// socketTransport sends newline-delimited frames over a stream connection, e.g.
+// the daemon's Unix socket.
+type socketTransport struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	writeMutex sync.Mutex
+}
+
+func newSocketTransport(conn net.Conn) *socketTransport {
+	return &socketTransport{
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, 64*1024),
+	}
+}
+
+func (transport *socketTransport) ReadMessage() ([]byte, error) {
+	var frame []byte
+	for {
+		chunk, isPrefix, err := transport.reader.ReadLine()
+		if errors.Is(err, net.ErrClosed) {
+			return nil, errTransportClosed
+		}
+		if err != nil {
+			return nil, err
+		}
+		frame = append(frame, chunk...)
+		if len(frame) > maxSocketFrameSize {
+			return nil, errors.New("frame too large")
+		}
+		if !isPrefix {
+			return frame, nil
+		}
+	}
+}
+
+func (transport *socketTransport) WriteMessage(frame []byte) error {
+	// Frames are delimited by newlines, so they can't contain any
+	var buffer bytes.Buffer
+	err := json.Compact(&buffer, frame)
+	if err != nil {
+		return err
+	}
+	buffer.WriteByte('\n')
+
+	transport.writeMutex.Lock()
+	defer transport.writeMutex.Unlock()
+
+	_, err = transport.conn.Write(buffer.Bytes())
+	return err
+}
+
+func (transport *socketTransport) Close() error {
+	return transport.conn.Close()
+}
@@ -0,0 +1,108 @@
+package anova
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation is a validation rule broken by a cook, with what broke it and what
+// the rule allows.
+type Violation struct {
+	// Path of the offending field, e.g. "stages[1].fanSpeed". Field names
+	// follow Recipe, and stage indexes start at 0.
+	Field string
+	// The offending value
+	Value interface{}
+	// What the rule allows, e.g. "25 to 180 °C with only the bottom heating
+	// element on"
+	Allowed string
+	// The rule's error, e.g. ErrInvalidFanSpeed{}, for use with errors.Is
+	Err error
+}
+
+func (violation Violation) Error() string {
+	return fmt.Sprintf("%s: %v is not allowed (%s); allowed: %s", violation.Field, violation.Value, violation.Err, violation.Allowed)
+}
+
+func (violation Violation) Unwrap() error {
+	return violation.Err
+}
+
+// ErrValidation reports every rule broken by a cook, a stage or a setting,
+// rather than just the first.
+type ErrValidation struct {
+	Violations []Violation
+
+	invalidStages []ErrInvalidCookStage
+}
+
+func (err ErrValidation) Error() string {
+	if len(err.Violations) == 1 {
+		return err.Violations[0].Error()
+	}
+	lines := make([]string, 0, len(err.Violations))
+	for _, violation := range err.Violations {
+		lines = append(lines, violation.Error())
+	}
+	return fmt.Sprintf("%d validation errors:\n  %s", len(err.Violations), strings.Join(lines, "\n  "))
+}
+
+func (err ErrValidation) Unwrap() []error {
+	errs := make([]error, 0, len(err.Violations))
+	for _, violation := range err.Violations {
+		errs = append(errs, violation)
+	}
+	for _, invalidStage := range err.invalidStages {
+		errs = append(errs, invalidStage)
+	}
+	return errs
+}
+
+// validator collects the violations of the rules checked against it.
+type validator struct {
+	violations    []Violation
+	invalidStages []ErrInvalidCookStage
+}
+
+// check records a violation of the rule unless ok.
+func (validator *validator) check(ok bool, field string, value interface{}, allowed string, err error) {
+	if !ok {
+		validator.violations = append(validator.violations, Violation{
+			Field:   field,
+			Value:   value,
+			Allowed: allowed,
+			Err:     err,
+		})
+	}
+}
+
+// checkStage checks a cook's stage, also recording it as an
+// ErrInvalidCookStage if it breaks any rule.
+func (validator *validator) checkStage(stage *CookStage, path string, maxTimerSeconds int) {
+	if stageErr, invalid := stage.validateAt(path, maxTimerSeconds).(ErrValidation); invalid {
+		validator.violations = append(validator.violations, stageErr.Violations...)
+		validator.invalidStages = append(validator.invalidStages, ErrInvalidCookStage{Stage: stage, StageError: stageErr})
+	}
+}
+
+func (validator *validator) err() error {
+	if len(validator.violations) == 0 {
+		return nil
+	}
+	return ErrValidation{Violations: validator.violations, invalidStages: validator.invalidStages}
+}
+
+// validate runs checks against a new validator.
+func validate(checks func(validator *validator)) error {
+	var validator validator
+	checks(&validator)
+	return validator.err()
+}
+
+// fieldPath joins a path and a field name, e.g. "stages[0]" and "fanSpeed".
+func fieldPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
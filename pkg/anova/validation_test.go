@@ -0,0 +1,121 @@
+package anova
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func violationFields(t *testing.T, err error) []string {
+	t.Helper()
+	var validationErr ErrValidation
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, got %+v", err)
+	}
+	var fields []string
+	for _, violation := range validationErr.Violations {
+		fields = append(fields, violation.Field)
+	}
+	return fields
+}
+
+func TestCook_Validate_AllViolations(t *testing.T) {
+	valid := NewCookStage(RackPositionMiddle, FanSpeedHigh, NewNonSousVideSetpointCelsius(200), RearElementOnly, NoSteam, nil)
+	invalid := NewCookStage(RackPosition(7), FanSpeedLow, NewNonSousVideSetpointCelsius(300), NewHeatingElements(false, true, true),
+		NewSteamPercentage(120), NewTimer(200*time.Hour, TimerTrigger("later")))
+
+	_, err := NewCook(valid, invalid)
+	expected := []string{
+		"stages[1].rackPosition",
+		"stages[1].temperatureCelsius",
+		"stages[1].heatingElements",
+		"stages[1].steamPercentage",
+		"stages[1].timer.trigger",
+		"stages[1].timer.duration",
+		"stages[1].fanSpeed",
+	}
+	if fields := violationFields(t, err); strings.Join(fields, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected violations of %v, got %v", expected, fields)
+	}
+	// The rules' errors can still be matched
+	if !errors.Is(err, ErrInvalidRackPosition{}) || !errors.Is(err, ErrInvalidFanSpeed{}) || errors.Is(err, ErrInvalidProbeTemperature{}) {
+		t.Errorf("unexpected errors in %+v", err)
+	}
+}
+
+func TestCookStage_Validate_Rules(t *testing.T) {
+	bottomOnly := NewHeatingElements(false, false, true)
+	for _, test := range []struct {
+		stage    *CookStage
+		field    string
+		allowed  string
+		expected error
+	}{
+		{
+			NewCookStage(RackPositionMiddle, FanSpeedHigh, NewNonSousVideSetpointCelsius(200), bottomOnly, NoSteam, nil),
+			"temperatureCelsius", "25 to 180 °C in dry mode with only the bottom heating element on", ErrInvalidTemperatureSetpoint{},
+		},
+		{
+			NewCookStage(RackPositionMiddle, FanSpeedHigh, NewSousVideSetpointCelsius(120), RearElementOnly, NewSteamPercentage(100), nil),
+			"temperatureCelsius", "25 to 100 °C in wet mode", ErrInvalidTemperatureSetpoint{},
+		},
+		{
+			NewCookStage(RackPositionMiddle, FanSpeedMedium, NewNonSousVideSetpointCelsius(200), NewHeatingElements(true, false, false), NewSteamPercentage(20), nil),
+			"fanSpeed", "100 with the rear heating element or steam on", ErrInvalidFanSpeed{},
+		},
+	} {
+		var validationErr ErrValidation
+		if !errors.As(test.stage.Validate(), &validationErr) || len(validationErr.Violations) != 1 {
+			t.Errorf("expected one violation for %+v, got %+v", test.stage, test.stage.Validate())
+			continue
+		}
+		violation := validationErr.Violations[0]
+		if violation.Field != test.field || violation.Allowed != test.allowed || violation.Err != test.expected {
+			t.Errorf("unexpected violation %+v", violation)
+		}
+	}
+
+	// 180 °C is fine with only the bottom heating element on
+	stage := NewCookStage(RackPositionMiddle, FanSpeedHigh, NewNonSousVideSetpointCelsius(180), bottomOnly, NoSteam, nil)
+	if err := stage.Validate(); err != nil {
+		t.Errorf("expected valid stage, got %+v", err)
+	}
+
+	// Wet mode without steam is fine, and uses the app's 100% relative humidity
+	stage = NewCookStage(RackPositionMiddle, FanSpeedHigh, NewSousVideSetpointCelsius(60), RearElementOnly, NoSteam, nil)
+	if err := stage.Validate(); err != nil {
+		t.Errorf("expected valid stage, got %+v", err)
+	}
+	for _, dtoStage := range stage.toDto() {
+		if dtoStage.SteamGenerators == nil || dtoStage.SteamGenerators.RelativeHumidity == nil ||
+			dtoStage.SteamGenerators.RelativeHumidity.Setpoint != 100 {
+			t.Errorf("expected 100%% relative humidity, got %+v", dtoStage.SteamGenerators)
+		}
+	}
+}
+
+func TestErrValidation_Error(t *testing.T) {
+	err := NewCookStage(RackPosition(0), FanSpeedHigh, NewNonSousVideSetpointCelsius(200), RearElementOnly, NoSteam, nil).Validate()
+	expected := "rackPosition: 0 is not allowed (invalid rack position); allowed: 1 to 5"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+
+	_, err = NewCook(NewCookStage(RackPosition(0), FanSpeed(-1), NewNonSousVideSetpointCelsius(200), RearElementOnly, NoSteam, nil))
+	if err == nil || !strings.HasPrefix(err.Error(), "3 validation errors:\n  stages[0].rackPosition: ") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestErrValidation_InvalidCookStage(t *testing.T) {
+	valid := NewCookStage(RackPositionMiddle, FanSpeedHigh, NewNonSousVideSetpointCelsius(200), RearElementOnly, NoSteam, nil)
+	invalid := NewCookStage(RackPosition(0), FanSpeedHigh, NewNonSousVideSetpointCelsius(200), RearElementOnly, NoSteam, nil)
+	_, err := NewCook(valid, invalid)
+
+	// Callers matching the deprecated error still find the offending stage
+	var stageErr ErrInvalidCookStage
+	if !errors.As(err, &stageErr) || stageErr.Stage != invalid || !errors.Is(stageErr, ErrInvalidRackPosition{}) {
+		t.Errorf("expected ErrInvalidCookStage for the second stage, got %+v", err)
+	}
+}
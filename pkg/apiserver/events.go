@@ -0,0 +1,152 @@
+package apiserver
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	subscriberBufferSize = 32
+	keepAliveInterval    = 30 * time.Second
+)
+
+type serverEvent struct {
+	name string
+	data []byte
+}
+
+// eventData is the JSON sent for every event. Only the fields relevant to the
+// event are set.
+type eventData struct {
+	CookerID  anova.CookerID    `json:"cookerId,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	OldName   string            `json:"oldName,omitempty"`
+	State     *dto.OvenStateV1  `json:"state,omitempty"`
+	CookerIDs []anova.CookerID  `json:"cookerIds,omitempty"`
+	Mode      dto.OtaUpdateMode `json:"mode,omitempty"`
+	Progress  *float64          `json:"progress,omitempty"`
+}
+
+func newServerEvent(event anova.Event) (serverEvent, bool) {
+	var name string
+	var data eventData
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		name = "ovenAdded"
+		data = eventData{CookerID: event.Oven.CookerID, Name: event.Oven.Name, State: event.State}
+	case anova.OvenUpdated:
+		name = "ovenUpdated"
+		data = eventData{CookerID: event.Oven.CookerID, State: event.State}
+	case anova.OvenRenamed:
+		name = "ovenRenamed"
+		data = eventData{CookerID: event.Oven.CookerID, Name: event.Oven.Name, OldName: event.OldName}
+	case anova.OvenListUpdated:
+		name = "ovenListUpdated"
+		data = eventData{CookerIDs: event.CookerIDs}
+	case anova.DescaleRequired:
+		name = "descaleRequired"
+		data = eventData{CookerID: event.Oven.CookerID}
+	case anova.FirmwareUpdateProgress:
+		name = "firmwareUpdateProgress"
+		data = eventData{CookerID: event.Oven.CookerID, Mode: event.Mode, Progress: event.Progress}
+	case anova.ServiceStopped:
+		name = "serviceStopped"
+	default:
+		return serverEvent{}, false
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		slog.Error("failed to marshal event", slog.String("event", name), slog.Any("err", err))
+		return serverEvent{}, false
+	}
+	return serverEvent{name: name, data: encoded}, true
+}
+
+// broadcast sends the event to every subscriber without blocking. Subscribers
+// that fall behind miss events rather than holding up the service.
+func (server *Server) broadcast(event anova.Event) {
+	serverEvent, ok := newServerEvent(event)
+	if !ok {
+		return
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	for subscriber := range server.subscribers {
+		select {
+		case subscriber <- serverEvent:
+		default:
+			slog.Warn("dropped event for slow subscriber", slog.String("event", serverEvent.name))
+		}
+	}
+}
+
+func (server *Server) subscribe() (events chan serverEvent, current []serverEvent) {
+	events = make(chan serverEvent, subscriberBufferSize)
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.subscribers[events] = struct{}{}
+	// New subscribers start with the current state of every oven
+	for _, entry := range server.ovens {
+		event, ok := newServerEvent(anova.OvenAdded{Oven: &anova.Oven{CookerID: entry.oven.CookerID, Name: entry.name}, State: entry.state})
+		if ok {
+			current = append(current, event)
+		}
+	}
+	return events, current
+}
+
+func (server *Server) unsubscribe(events chan serverEvent) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	delete(server.subscribers, events)
+}
+
+func (server *Server) streamEvents(writer http.ResponseWriter, request *http.Request) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		writeError(writer, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	events, current := server.subscribe()
+	defer server.unsubscribe(events)
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+
+	for _, event := range current {
+		writeServerEvent(writer, event)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case event := <-events:
+			writeServerEvent(writer, event)
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(writer, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+func writeServerEvent(writer http.ResponseWriter, event serverEvent) {
+	_, _ = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event.name, event.data)
+}
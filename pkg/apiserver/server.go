@@ -0,0 +1,368 @@
+// Package apiserver exposes the ovens of a Service over a local REST API, with
+// Server-Sent Events for service events.
+package apiserver
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// Server tracks the ovens of a Service through HandleEvent, and serves them
+// through Handler.
+type Server struct {
+	token     string
+	observers []func(recipe anova.Recipe, cook *anova.Cook, oven *anova.Oven)
+
+	mutex       sync.Mutex
+	ovens       map[anova.CookerID]*ovenEntry
+	subscribers map[chan serverEvent]struct{}
+}
+
+type ovenEntry struct {
+	oven *anova.Oven
+	// Copied from events, as Oven.Name and Oven.State aren't safe to read from
+	// request handlers
+	name  string
+	state *dto.OvenStateV1
+}
+
+// NewServer creates a server that requires every request, apart from the
+// OpenAPI document, to present token as a bearer token. The observers are
+// called with every cook started through the API.
+func NewServer(token string, observers ...func(recipe anova.Recipe, cook *anova.Cook, oven *anova.Oven)) *Server {
+	return &Server{
+		token:     token,
+		observers: observers,
+
+		ovens:       make(map[anova.CookerID]*ovenEntry),
+		subscribers: make(map[chan serverEvent]struct{}),
+	}
+}
+
+// GenerateToken returns a random token suitable for NewServer.
+func GenerateToken() (string, error) {
+	buffer := make([]byte, 24)
+	_, err := rand.Read(buffer)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API token: %+v", err)
+	}
+	return hex.EncodeToString(buffer), nil
+}
+
+func (server *Server) HandleEvent(event anova.Event) {
+	server.mutex.Lock()
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		server.ovens[event.Oven.CookerID] = &ovenEntry{
+			oven:  event.Oven,
+			name:  event.Oven.Name,
+			state: event.State,
+		}
+	case anova.OvenUpdated:
+		if entry, exists := server.ovens[event.Oven.CookerID]; exists {
+			entry.state = event.State
+		}
+	case anova.OvenRenamed:
+		if entry, exists := server.ovens[event.Oven.CookerID]; exists {
+			entry.name = event.Oven.Name
+		}
+	}
+	server.mutex.Unlock()
+
+	server.broadcast(event)
+}
+
+func (server *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", server.getOpenAPIDocument)
+	mux.Handle("GET /ovens", server.authenticated(server.listOvens))
+	mux.Handle("GET /ovens/{id}/state", server.authenticated(server.getOvenState))
+	mux.Handle("GET /ovens/{id}/progress", server.authenticated(server.getCookProgress))
+	mux.Handle("POST /ovens/{id}/cook", server.authenticated(server.startCook))
+	mux.Handle("DELETE /ovens/{id}/cook", server.authenticated(server.stopCook))
+	mux.Handle("PUT /ovens/{id}/lamp", server.authenticated(server.setLamp))
+	mux.Handle("GET /events", server.authenticated(server.streamEvents))
+	return mux
+}
+
+// authenticated accepts the token either as a bearer token, or in the "token"
+// query parameter for clients that can't set headers, e.g. EventSource.
+func (server *Server) authenticated(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		token, found := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			token = request.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(server.token)) != 1 {
+			writer.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(writer, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		handler(writer, request)
+	})
+}
+
+func (server *Server) getOpenAPIDocument(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	_, _ = writer.Write(openAPIDocument)
+}
+
+type ovenResponse struct {
+	CookerID anova.CookerID `json:"cookerId"`
+	Name     string         `json:"name"`
+	Type     dto.CookerType `json:"type"`
+	Online   bool           `json:"online"`
+	Mode     dto.StateMode  `json:"mode"`
+}
+
+func (server *Server) listOvens(writer http.ResponseWriter, _ *http.Request) {
+	server.mutex.Lock()
+	ovens := make([]ovenResponse, 0, len(server.ovens))
+	for cookerID, entry := range server.ovens {
+		oven := ovenResponse{
+			CookerID: cookerID,
+			Name:     entry.name,
+			Type:     entry.oven.Type,
+		}
+		if entry.state != nil {
+			oven.Online = entry.state.SystemInfo.Online
+			oven.Mode = entry.state.State.Mode
+		}
+		ovens = append(ovens, oven)
+	}
+	server.mutex.Unlock()
+
+	writeJSON(writer, http.StatusOK, ovens)
+}
+
+func (server *Server) getOvenState(writer http.ResponseWriter, request *http.Request) {
+	entry, err := server.findOven(request)
+	if err != nil {
+		writeError(writer, http.StatusNotFound, err)
+		return
+	}
+
+	server.mutex.Lock()
+	state := entry.state
+	server.mutex.Unlock()
+
+	writeJSON(writer, http.StatusOK, state)
+}
+
+type stageProgressResponse struct {
+	ID               string            `json:"id"`
+	Type             dto.StageType     `json:"type"`
+	Status           anova.StageStatus `json:"status"`
+	TimerSeconds     int               `json:"timerSeconds,omitempty"`
+	RemainingSeconds *int              `json:"remainingSeconds"`
+}
+
+type cookProgressResponse struct {
+	CookID                      string                  `json:"cookId"`
+	ActiveStageIndex            int                     `json:"activeStageIndex"`
+	Stages                      []stageProgressResponse `json:"stages"`
+	ElapsedSeconds              int                     `json:"elapsedSeconds"`
+	StageRemainingSeconds       *int                    `json:"stageRemainingSeconds"`
+	RemainingSeconds            *int                    `json:"remainingSeconds"`
+	PreheatETA                  *time.Time              `json:"preheatEta,omitempty"`
+	HeatingRateCelsiusPerMinute float64                 `json:"heatingRateCelsiusPerMinute,omitempty"`
+}
+
+func newCookProgressResponse(progress *anova.CookProgress) cookProgressResponse {
+	response := cookProgressResponse{
+		CookID:                      progress.CookID,
+		ActiveStageIndex:            progress.ActiveStageIndex,
+		Stages:                      make([]stageProgressResponse, 0, len(progress.Stages)),
+		ElapsedSeconds:              int(progress.Elapsed.Seconds()),
+		StageRemainingSeconds:       seconds(progress.StageRemaining),
+		RemainingSeconds:            seconds(progress.Remaining),
+		PreheatETA:                  progress.PreheatETA,
+		HeatingRateCelsiusPerMinute: math.Round(progress.HeatingRate*100) / 100,
+	}
+	for _, stage := range progress.Stages {
+		response.Stages = append(response.Stages, stageProgressResponse{
+			ID:               stage.ID,
+			Type:             stage.Type,
+			Status:           stage.Status,
+			TimerSeconds:     int(stage.Timer.Seconds()),
+			RemainingSeconds: seconds(stage.Remaining),
+		})
+	}
+	return response
+}
+
+func seconds(duration *time.Duration) *int {
+	if duration == nil {
+		return nil
+	}
+	seconds := int(duration.Seconds())
+	return &seconds
+}
+
+func (server *Server) getCookProgress(writer http.ResponseWriter, request *http.Request) {
+	entry, err := server.findOven(request)
+	if err != nil {
+		writeError(writer, http.StatusNotFound, err)
+		return
+	}
+
+	progress := entry.oven.Progress()
+	if progress == nil {
+		writeError(writer, http.StatusConflict, errors.New("the oven is not cooking"))
+		return
+	}
+	writeJSON(writer, http.StatusOK, newCookProgressResponse(progress))
+}
+
+func (server *Server) startCook(writer http.ResponseWriter, request *http.Request) {
+	entry, err := server.findOven(request)
+	if err != nil {
+		writeError(writer, http.StatusNotFound, err)
+		return
+	}
+
+	var recipe anova.Recipe
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&recipe)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid recipe: %+v", err))
+		return
+	}
+	cook, err := recipe.NewCook()
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	err = cook.Start(entry.oven)
+	if err != nil {
+		writeCommandError(writer, err)
+		return
+	}
+	for _, observer := range server.observers {
+		observer(recipe, cook, entry.oven)
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) stopCook(writer http.ResponseWriter, request *http.Request) {
+	entry, err := server.findOven(request)
+	if err != nil {
+		writeError(writer, http.StatusNotFound, err)
+		return
+	}
+
+	err = entry.oven.StopCook()
+	if err != nil {
+		writeCommandError(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+type lampRequest struct {
+	On *bool `json:"on"`
+}
+
+func (server *Server) setLamp(writer http.ResponseWriter, request *http.Request) {
+	entry, err := server.findOven(request)
+	if err != nil {
+		writeError(writer, http.StatusNotFound, err)
+		return
+	}
+
+	var lamp lampRequest
+	err = json.NewDecoder(request.Body).Decode(&lamp)
+	if err == nil && lamp.On == nil {
+		err = errors.New("missing \"on\"")
+	}
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, fmt.Errorf("invalid lamp request: %+v", err))
+		return
+	}
+
+	err = entry.oven.TurnOnLamp(*lamp.On)
+	if err != nil {
+		writeCommandError(writer, err)
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) findOven(request *http.Request) (*ovenEntry, error) {
+	cookerID := anova.CookerID(request.PathValue("id"))
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	entry, exists := server.ovens[cookerID]
+	if !exists {
+		return nil, fmt.Errorf("unknown oven \"%s\"", cookerID)
+	}
+	return entry, nil
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	// Every rule a recipe breaks, for validation errors
+	Violations []violationResponse `json:"violations,omitempty"`
+}
+
+type violationResponse struct {
+	Field   string `json:"field"`
+	Value   string `json:"value"`
+	Allowed string `json:"allowed"`
+	Error   string `json:"error"`
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	err := json.NewEncoder(writer).Encode(value)
+	if err != nil {
+		slog.Error("failed to write API response", slog.Any("err", err))
+	}
+}
+
+func writeError(writer http.ResponseWriter, status int, err error) {
+	response := errorResponse{Error: err.Error()}
+	var validationErr anova.ErrValidation
+	if errors.As(err, &validationErr) {
+		for _, violation := range validationErr.Violations {
+			response.Violations = append(response.Violations, violationResponse{
+				Field:   violation.Field,
+				Value:   fmt.Sprint(violation.Value),
+				Allowed: violation.Allowed,
+				Error:   violation.Err.Error(),
+			})
+		}
+	}
+	writeJSON(writer, status, response)
+}
+
+// writeCommandError reports errors from oven commands, which are the oven's or
+// the Anova backend's fault rather than the API client's.
+func writeCommandError(writer http.ResponseWriter, err error) {
+	status := http.StatusBadGateway
+	if errors.As(err, &anova.ErrRequestNotAcknowledged{}) {
+		status = http.StatusGatewayTimeout
+	}
+	writeError(writer, status, err)
+}
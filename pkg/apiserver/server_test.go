@@ -0,0 +1,194 @@
+package apiserver
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testToken = "secret"
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	server := NewServer(testToken)
+	state := &dto.OvenStateV1{}
+	state.SystemInfo.Online = true
+	state.State.Mode = dto.StateModeIdle
+	server.HandleEvent(anova.OvenAdded{Oven: &anova.Oven{CookerID: "0123456789abcdef", Name: "Kitchen"}, State: state})
+
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+	return server, httpServer
+}
+
+func doRequest(t *testing.T, method string, url string, token string, body string) *http.Response {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %+v", err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("request failed: %+v", err)
+	}
+	t.Cleanup(func() { response.Body.Close() })
+	return response
+}
+
+func TestServer_Authentication(t *testing.T) {
+	_, httpServer := newTestServer(t)
+
+	for _, testCase := range []struct {
+		url      string
+		token    string
+		expected int
+	}{
+		{"/ovens", "", http.StatusUnauthorized},
+		{"/ovens", "wrong", http.StatusUnauthorized},
+		{"/ovens", testToken, http.StatusOK},
+		{"/ovens?token=" + testToken, "", http.StatusOK},
+		{"/openapi.json", "", http.StatusOK},
+	} {
+		response := doRequest(t, http.MethodGet, httpServer.URL+testCase.url, testCase.token, "")
+		if response.StatusCode != testCase.expected {
+			t.Errorf("expected %d for %s with token \"%s\", got %d", testCase.expected, testCase.url, testCase.token, response.StatusCode)
+		}
+	}
+}
+
+func TestServer_Ovens(t *testing.T) {
+	server, httpServer := newTestServer(t)
+	server.HandleEvent(anova.OvenRenamed{Oven: &anova.Oven{CookerID: "0123456789abcdef", Name: "Garage"}, OldName: "Kitchen"})
+
+	response := doRequest(t, http.MethodGet, httpServer.URL+"/ovens", testToken, "")
+	var ovens []ovenResponse
+	err := json.NewDecoder(response.Body).Decode(&ovens)
+	if err != nil {
+		t.Fatalf("invalid response: %+v", err)
+	}
+	if len(ovens) != 1 || ovens[0].CookerID != "0123456789abcdef" || ovens[0].Name != "Garage" || !ovens[0].Online {
+		t.Errorf("unexpected ovens %+v", ovens)
+	}
+
+	response = doRequest(t, http.MethodGet, httpServer.URL+"/ovens/0123456789abcdef/state", testToken, "")
+	var state dto.OvenStateV1
+	err = json.NewDecoder(response.Body).Decode(&state)
+	if err != nil || state.State.Mode != dto.StateModeIdle {
+		t.Errorf("unexpected state %+v (err: %+v)", state, err)
+	}
+
+	response = doRequest(t, http.MethodGet, httpServer.URL+"/ovens/fedcba9876543210/state", testToken, "")
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown oven, got %d", response.StatusCode)
+	}
+
+	response = doRequest(t, http.MethodGet, httpServer.URL+"/ovens/0123456789abcdef/progress", testToken, "")
+	if response.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for the progress of an idle oven, got %d", response.StatusCode)
+	}
+}
+
+func TestServer_InvalidRequests(t *testing.T) {
+	_, httpServer := newTestServer(t)
+
+	for _, testCase := range []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/ovens/0123456789abcdef/cook", "{"},
+		{http.MethodPost, "/ovens/0123456789abcdef/cook", `{"stages":[]}`},
+		{http.MethodPost, "/ovens/0123456789abcdef/cook", `{"stages":[{"mode":"dry","temperatureCelsius":200,"heatingElements":["rear"]}],"extra":true}`},
+		{http.MethodPut, "/ovens/0123456789abcdef/lamp", `{}`},
+		{http.MethodPut, "/ovens/0123456789abcdef/lamp", `{"on":"yes"}`},
+	} {
+		response := doRequest(t, testCase.method, httpServer.URL+testCase.path, testToken, testCase.body)
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected 400 for %s %s with %s, got %d", testCase.method, testCase.path, testCase.body, response.StatusCode)
+		}
+	}
+	// Every broken rule is reported
+	response := doRequest(t, http.MethodPost, httpServer.URL+"/ovens/0123456789abcdef/cook", testToken,
+		`{"stages":[{"mode":"dry","temperatureCelsius":200,"heatingElements":["bottom"],"rackPosition":9}]}`)
+	var body errorResponse
+	err := json.NewDecoder(response.Body).Decode(&body)
+	if err != nil || response.StatusCode != http.StatusBadRequest || len(body.Violations) != 2 ||
+		body.Violations[0] != (violationResponse{Field: "stages[0].rackPosition", Value: "9", Allowed: "1 to 5", Error: "invalid rack position"}) {
+		t.Errorf("unexpected response %d %+v (err: %+v)", response.StatusCode, body, err)
+	}
+}
+
+func TestServer_Events(t *testing.T) {
+	server, httpServer := newTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/events?token="+testToken, nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("request failed: %+v", err)
+	}
+	defer response.Body.Close()
+
+	reader := bufio.NewReader(response.Body)
+	readEvent := func() string {
+		var name string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read event: %+v", err)
+			}
+			if value, found := strings.CutPrefix(line, "event: "); found {
+				name = strings.TrimSpace(value)
+			}
+			if line == "\n" && name != "" {
+				return name
+			}
+		}
+	}
+
+	// The current ovens are sent first
+	if name := readEvent(); name != "ovenAdded" {
+		t.Fatalf("expected initial ovenAdded event, got %s", name)
+	}
+
+	server.HandleEvent(anova.DescaleRequired{Oven: &anova.Oven{CookerID: "0123456789abcdef"}})
+	if name := readEvent(); name != "descaleRequired" {
+		t.Errorf("expected descaleRequired event, got %s", name)
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	var document struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	err := json.Unmarshal(openAPIDocument, &document)
+	if err != nil {
+		t.Fatalf("invalid OpenAPI document: %+v", err)
+	}
+
+	// Keep in sync with Handler
+	for _, route := range []string{
+		"GET /openapi.json",
+		"GET /ovens",
+		"GET /ovens/{id}/state",
+		"GET /ovens/{id}/progress",
+		"POST /ovens/{id}/cook",
+		"DELETE /ovens/{id}/cook",
+		"PUT /ovens/{id}/lamp",
+		"GET /events",
+	} {
+		method, path, _ := strings.Cut(route, " ")
+		if _, documented := document.Paths[path][strings.ToLower(method)]; !documented {
+			t.Errorf("route %s is not documented", route)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package apocli
 
 import (
 	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
 	"fmt"
 	"github.com/BurntSushi/toml"
 	"github.com/kirsle/configdir"
@@ -12,12 +13,24 @@ import (
 const (
 	ConfigFolderName = "apocli"
 	ConfigFileName   = "config.toml"
+
+	HistoryDatabaseFileName = "history.db"
+	ScheduleFileName        = "schedule.json"
+	NotificationsFileName   = "notifications.toml"
+	LongCooksFileName       = "longcooks.json"
 )
 
 type Config struct {
 	FirebaseRefreshToken string
 
 	DefaultCookerID anova.CookerID
+
+	// Bearer token required by `apocli serve`. Generated on first use.
+	APIToken string
+
+	// Unit the CLI shows temperatures in, C or F. Defaults to the oven's own
+	// unit, or Celsius where there's no oven to ask.
+	TemperatureUnit dto.TemperatureUnit
 }
 
 func DefaultConfig() *Config {
@@ -28,13 +41,39 @@ func DefaultConfig() *Config {
 }
 
 func ConfigFilePath() (string, error) {
+	return configFolderPath(ConfigFileName)
+}
+
+// HistoryDatabasePath is the default location of the cook history database.
+func HistoryDatabasePath() (string, error) {
+	return configFolderPath(HistoryDatabaseFileName)
+}
+
+// ScheduleFilePath is the default location of the scheduled cooks.
+func ScheduleFilePath() (string, error) {
+	return configFolderPath(ScheduleFileName)
+}
+
+// NotificationsConfigPath is the default location of the notifications config.
+func NotificationsConfigPath() (string, error) {
+	return configFolderPath(NotificationsFileName)
+}
+
+// LongCooksFilePath is the default location of the long cook records.
+func LongCooksFilePath() (string, error) {
+	return configFolderPath(LongCooksFileName)
+}
+
+// configFolderPath returns the path of a file in the config folder, creating
+// the folder if needed.
+func configFolderPath(fileName string) (string, error) {
 	configPath := configdir.LocalConfig(ConfigFolderName)
 	err := configdir.MakePath(configPath)
 	if err != nil {
 		return "", fmt.Errorf("unable to create config path \"%s\"", configPath)
 	}
 
-	return filepath.Join(configPath, ConfigFileName), nil
+	return filepath.Join(configPath, fileName), nil
 }
 
 func (config *Config) Save() error {
@@ -0,0 +1,32 @@
+package apocli
+
+import (
+	"apocli/pkg/anova"
+	"log/slog"
+	"os"
+)
+
+const DaemonSocketFileName = "daemon.sock"
+
+// DaemonSocketPath is where `apocli daemon` listens for local clients.
+func DaemonSocketPath() (string, error) {
+	return configFolderPath(DaemonSocketFileName)
+}
+
+// NewClient connects through the daemon if one is running, and directly to the
+// backend otherwise.
+func (config *Config) NewClient(options ...func(*anova.Client) error) (*anova.Client, error) {
+	socketPath, err := DaemonSocketPath()
+	if err == nil {
+		if _, err = os.Stat(socketPath); err == nil {
+			client, err := anova.NewClient(config.FirebaseRefreshToken, append(options, anova.OptionDaemonSocket(socketPath))...)
+			if err == nil {
+				slog.Debug("connected through daemon", slog.String("socket", socketPath))
+				return client, nil
+			}
+			slog.Debug("daemon is not running; connecting directly", slog.Any("err", err))
+		}
+	}
+
+	return anova.NewClient(config.FirebaseRefreshToken, options...)
+}
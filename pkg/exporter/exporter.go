@@ -0,0 +1,257 @@
+// Package exporter publishes oven telemetry as Prometheus metrics.
+package exporter
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const namespace = "apocli"
+
+// Labels attached to every oven metric
+var ovenLabels = []string{"cooker_id", "name"}
+
+// Exporter keeps a set of Prometheus metrics up to date from Service events.
+type Exporter struct {
+	registry *prometheus.Registry
+
+	temperature         *prometheus.GaugeVec
+	temperatureSetpoint *prometheus.GaugeVec
+	relativeHumidity    *prometheus.GaugeVec
+	humiditySetpoint    *prometheus.GaugeVec
+	heatingElementOn    *prometheus.GaugeVec
+	heatingElementWatts *prometheus.GaugeVec
+	fanSpeed            *prometheus.GaugeVec
+	boilerTemperature   *prometheus.GaugeVec
+	evaporatorTemp      *prometheus.GaugeVec
+	doorClosed          *prometheus.GaugeVec
+	lampOn              *prometheus.GaugeVec
+	ventOpen            *prometheus.GaugeVec
+	waterTankEmpty      *prometheus.GaugeVec
+	probeConnected      *prometheus.GaugeVec
+	timerInitial        *prometheus.GaugeVec
+	timerCurrent        *prometheus.GaugeVec
+	timerRunning        *prometheus.GaugeVec
+	cooking             *prometheus.GaugeVec
+	cookStageIndex      *prometheus.GaugeVec
+	online              *prometheus.GaugeVec
+	lastUpdate          *prometheus.GaugeVec
+
+	commandDuration *prometheus.HistogramVec
+	commands        *prometheus.CounterVec
+	unknownFields   *prometheus.CounterVec
+
+	ovenVecs []*prometheus.GaugeVec
+
+	mutex sync.Mutex
+	// Last known name and state of each oven, used to relabel series on renames
+	names  map[anova.CookerID]string
+	states map[anova.CookerID]*dto.OvenStateV1
+}
+
+func NewExporter() *Exporter {
+	ovenGauge := func(name string, help string, extraLabels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "oven",
+			Name:      name,
+			Help:      help,
+		}, append(append([]string{}, ovenLabels...), extraLabels...))
+	}
+
+	exporter := &Exporter{
+		registry: prometheus.NewRegistry(),
+
+		temperature:         ovenGauge("temperature_celsius", "Current temperature of a bulb (dry, wet, dry_top, dry_bottom)", "bulb"),
+		temperatureSetpoint: ovenGauge("temperature_setpoint_celsius", "Temperature setpoint of the active bulb (dry or wet)", "bulb"),
+		relativeHumidity:    ovenGauge("relative_humidity_percent", "Current relative humidity, only reported in wet mode"),
+		humiditySetpoint:    ovenGauge("steam_setpoint_percent", "Steam setpoint, as relative humidity or steam percentage", "mode"),
+		heatingElementOn:    ovenGauge("heating_element_on", "Whether a heating element is on", "element"),
+		heatingElementWatts: ovenGauge("heating_element_watts", "Power drawn by a heating element", "element"),
+		fanSpeed:            ovenGauge("fan_speed_percent", "Convection fan speed"),
+		boilerTemperature:   ovenGauge("boiler_temperature_celsius", "Temperature of the steam boiler"),
+		evaporatorTemp:      ovenGauge("evaporator_temperature_celsius", "Temperature of the steam evaporator"),
+		doorClosed:          ovenGauge("door_closed", "Whether the door is closed"),
+		lampOn:              ovenGauge("lamp_on", "Whether the cavity lamp is on"),
+		ventOpen:            ovenGauge("vent_open", "Whether the vent is open"),
+		waterTankEmpty:      ovenGauge("water_tank_empty", "Whether the water tank is empty"),
+		probeConnected:      ovenGauge("probe_connected", "Whether the temperature probe is plugged in"),
+		timerInitial:        ovenGauge("timer_initial_seconds", "Duration the timer was set to"),
+		timerCurrent:        ovenGauge("timer_current_seconds", "Time elapsed on the timer"),
+		timerRunning:        ovenGauge("timer_running", "Whether the timer is running"),
+		cooking:             ovenGauge("cooking", "Whether a cook is in progress"),
+		cookStageIndex:      ovenGauge("cook_stage_index", "Index of the active stage of the current cook, including preheat stages"),
+		online:              ovenGauge("online", "Whether the oven is connected to the backend"),
+		lastUpdate:          ovenGauge("last_update_timestamp_seconds", "Time of the last state update reported by the oven"),
+
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "command_duration_seconds",
+			Help:      "Time from sending a command until it was acknowledged",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 8),
+		}, []string{"command"}),
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "commands_total",
+			Help:      "Commands sent, by result (ok, failed, not_acknowledged, error)",
+		}, []string{"command", "result"}),
+		unknownFields: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "unknown_fields_total",
+			Help:      "Received fields that apocli doesn't know about, by message and field path",
+		}, []string{"command", "field"}),
+
+		names:  make(map[anova.CookerID]string),
+		states: make(map[anova.CookerID]*dto.OvenStateV1),
+	}
+
+	exporter.ovenVecs = []*prometheus.GaugeVec{
+		exporter.temperature, exporter.temperatureSetpoint,
+		exporter.relativeHumidity, exporter.humiditySetpoint,
+		exporter.heatingElementOn, exporter.heatingElementWatts,
+		exporter.fanSpeed, exporter.boilerTemperature, exporter.evaporatorTemp,
+		exporter.doorClosed, exporter.lampOn, exporter.ventOpen, exporter.waterTankEmpty,
+		exporter.probeConnected, exporter.timerInitial, exporter.timerCurrent, exporter.timerRunning,
+		exporter.cooking, exporter.cookStageIndex, exporter.online, exporter.lastUpdate,
+	}
+	for _, vec := range exporter.ovenVecs {
+		exporter.registry.MustRegister(vec)
+	}
+	exporter.registry.MustRegister(exporter.commandDuration, exporter.commands, exporter.unknownFields)
+
+	return exporter
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (exporter *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(exporter.registry, promhttp.HandlerOpts{})
+}
+
+func (exporter *Exporter) HandleEvent(event anova.Event) {
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		exporter.update(event.Oven.CookerID, event.Oven.Name, event.State)
+	case anova.OvenUpdated:
+		exporter.update(event.Oven.CookerID, exporter.names[event.Oven.CookerID], event.State)
+	case anova.OvenRenamed:
+		state := exporter.states[event.Oven.CookerID]
+		exporter.deleteOven(event.Oven.CookerID)
+		if state != nil {
+			exporter.update(event.Oven.CookerID, event.Oven.Name, state)
+		}
+	}
+}
+
+// ObserveCommand records client-side command metrics. It can be passed to
+// anova.OptionCommandObserver.
+func (exporter *Exporter) ObserveCommand(messageType dto.MessageType, duration time.Duration, err error) {
+	result := "ok"
+	switch {
+	case err == nil:
+		exporter.commandDuration.WithLabelValues(string(messageType)).Observe(duration.Seconds())
+	case errors.As(err, &anova.ErrRequestNotAcknowledged{}):
+		result = "not_acknowledged"
+	case errors.As(err, &anova.ErrRequestFailed{}):
+		result = "failed"
+	default:
+		result = "error"
+	}
+	exporter.commands.WithLabelValues(string(messageType), result).Inc()
+}
+
+// ObserveUnknownFields counts fields received from the backend that the DTOs
+// don't cover. It can be passed to anova.OptionUnknownFieldsObserver.
+func (exporter *Exporter) ObserveUnknownFields(report anova.UnknownFieldsReport) {
+	for _, field := range report.Fields {
+		exporter.unknownFields.WithLabelValues(string(report.Command), field).Inc()
+	}
+}
+
+func (exporter *Exporter) deleteOven(cookerID anova.CookerID) {
+	for _, vec := range exporter.ovenVecs {
+		vec.DeletePartialMatch(prometheus.Labels{"cooker_id": string(cookerID)})
+	}
+	delete(exporter.names, cookerID)
+	delete(exporter.states, cookerID)
+}
+
+func (exporter *Exporter) update(cookerID anova.CookerID, name string, state *dto.OvenStateV1) {
+	if state == nil {
+		return
+	}
+	exporter.names[cookerID] = name
+	exporter.states[cookerID] = state
+
+	id := string(cookerID)
+	nodes := state.Nodes
+
+	bulbs := nodes.TemperatureBulbs
+	exporter.temperature.WithLabelValues(id, name, "dry").Set(bulbs.Dry.Current.Celsius)
+	exporter.temperature.WithLabelValues(id, name, "wet").Set(bulbs.Wet.Current.Celsius)
+	exporter.temperature.WithLabelValues(id, name, "dry_top").Set(bulbs.DryTop.Current.Celsius)
+	exporter.temperature.WithLabelValues(id, name, "dry_bottom").Set(bulbs.DryBottom.Current.Celsius)
+	setOrDelete(exporter.temperatureSetpoint, bulbs.Dry.Setpoint != nil, func() float64 { return bulbs.Dry.Setpoint.Celsius }, id, name, "dry")
+	setOrDelete(exporter.temperatureSetpoint, bulbs.Wet.Setpoint != nil, func() float64 { return bulbs.Wet.Setpoint.Celsius }, id, name, "wet")
+
+	steam := nodes.SteamGenerators
+	setOrDelete(exporter.relativeHumidity, steam.RelativeHumidity != nil, func() float64 { return float64(steam.RelativeHumidity.Current) }, id, name)
+	setOrDelete(exporter.humiditySetpoint, steam.RelativeHumidity != nil, func() float64 { return float64(steam.RelativeHumidity.Setpoint) }, id, name, string(dto.SteamGeneratorModeRelativeHumidity))
+	setOrDelete(exporter.humiditySetpoint, steam.SteamPercentage != nil, func() float64 { return float64(steam.SteamPercentage.Setpoint) }, id, name, string(dto.SteamGeneratorModeSteamPercentage))
+	exporter.boilerTemperature.WithLabelValues(id, name).Set(steam.Boiler.Celsius)
+	exporter.evaporatorTemp.WithLabelValues(id, name).Set(steam.Evaporator.Celsius)
+
+	for element, node := range map[string]dto.HeatingElement{
+		"top":    nodes.HeatingElements.Top,
+		"bottom": nodes.HeatingElements.Bottom,
+		"rear":   nodes.HeatingElements.Rear,
+	} {
+		exporter.heatingElementOn.WithLabelValues(id, name, element).Set(boolToFloat(node.On))
+		exporter.heatingElementWatts.WithLabelValues(id, name, element).Set(float64(node.Watts))
+	}
+
+	exporter.fanSpeed.WithLabelValues(id, name).Set(float64(nodes.Fan.Speed))
+	exporter.doorClosed.WithLabelValues(id, name).Set(boolToFloat(nodes.Door.Closed))
+	exporter.lampOn.WithLabelValues(id, name).Set(boolToFloat(nodes.Lamp.On))
+	exporter.ventOpen.WithLabelValues(id, name).Set(boolToFloat(nodes.Vent.Open))
+	exporter.waterTankEmpty.WithLabelValues(id, name).Set(boolToFloat(nodes.WaterTank.Empty))
+	exporter.probeConnected.WithLabelValues(id, name).Set(boolToFloat(nodes.TemperatureProbe.Connected))
+
+	exporter.timerInitial.WithLabelValues(id, name).Set(float64(nodes.Timer.Initial))
+	exporter.timerCurrent.WithLabelValues(id, name).Set(float64(nodes.Timer.Current))
+	exporter.timerRunning.WithLabelValues(id, name).Set(boolToFloat(nodes.Timer.Mode == string(dto.TimerModeRunning)))
+
+	exporter.cooking.WithLabelValues(id, name).Set(boolToFloat(state.State.Mode == dto.StateModeCook))
+	setOrDelete(exporter.cookStageIndex, state.Cook != nil, func() float64 { return float64(state.Cook.ActiveStageIndex) }, id, name)
+
+	exporter.online.WithLabelValues(id, name).Set(boolToFloat(state.SystemInfo.Online))
+	exporter.lastUpdate.WithLabelValues(id, name).Set(float64(state.UpdatedTimestamp.Unix()))
+}
+
+// setOrDelete sets a gauge if the value is present, and otherwise removes the
+// series so that stale values aren't scraped.
+func setOrDelete(vec *prometheus.GaugeVec, present bool, value func() float64, labelValues ...string) {
+	if present {
+		vec.WithLabelValues(labelValues...).Set(value())
+	} else {
+		vec.DeleteLabelValues(labelValues...)
+	}
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
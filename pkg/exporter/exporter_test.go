@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"testing"
+)
+
+func TestExporter_HandleEvent(t *testing.T) {
+	exporter := NewExporter()
+	oven := &anova.Oven{CookerID: "0123456789abcdef", Name: "Kitchen"}
+
+	state := &dto.OvenStateV1{}
+	state.State.Mode = dto.StateModeCook
+	state.Nodes.TemperatureBulbs.Dry.Current = dto.NewTemperatureFromCelsius(150)
+	setpoint := dto.NewTemperatureFromCelsius(200)
+	state.Nodes.TemperatureBulbs.Dry.Setpoint = &setpoint
+	state.Nodes.HeatingElements.Rear = dto.HeatingElement{On: true, Watts: 1600}
+	state.Cook = &dto.CookV1{ActiveStageIndex: 1}
+
+	exporter.HandleEvent(anova.OvenAdded{Oven: oven, State: state})
+
+	for _, testCase := range []struct {
+		value    float64
+		expected float64
+	}{
+		{testutil.ToFloat64(exporter.temperature.WithLabelValues("0123456789abcdef", "Kitchen", "dry")), 150},
+		{testutil.ToFloat64(exporter.temperatureSetpoint.WithLabelValues("0123456789abcdef", "Kitchen", "dry")), 200},
+		{testutil.ToFloat64(exporter.heatingElementWatts.WithLabelValues("0123456789abcdef", "Kitchen", "rear")), 1600},
+		{testutil.ToFloat64(exporter.cooking.WithLabelValues("0123456789abcdef", "Kitchen")), 1},
+		{testutil.ToFloat64(exporter.cookStageIndex.WithLabelValues("0123456789abcdef", "Kitchen")), 1},
+	} {
+		if testCase.value != testCase.expected {
+			t.Errorf("expected %f, got %f", testCase.expected, testCase.value)
+		}
+	}
+
+	// Renaming the oven should move every series to the new name
+	oven.Name = "Garage"
+	exporter.HandleEvent(anova.OvenRenamed{Oven: oven, OldName: "Kitchen"})
+	if count := testutil.CollectAndCount(exporter.fanSpeed); count != 1 {
+		t.Errorf("expected 1 fan speed series after rename, got %d", count)
+	}
+	if value := testutil.ToFloat64(exporter.temperature.WithLabelValues("0123456789abcdef", "Garage", "dry")); value != 150 {
+		t.Errorf("expected renamed series to keep its value, got %f", value)
+	}
+
+	// Setpoints disappear when the cook ends
+	idleState := *state
+	idleState.Cook = nil
+	idleState.Nodes.TemperatureBulbs.Dry.Setpoint = nil
+	exporter.HandleEvent(anova.OvenUpdated{Oven: oven, State: &idleState, PreviousState: state})
+	if count := testutil.CollectAndCount(exporter.temperatureSetpoint); count != 0 {
+		t.Errorf("expected no setpoint series while idle, got %d", count)
+	}
+	if count := testutil.CollectAndCount(exporter.cookStageIndex); count != 0 {
+		t.Errorf("expected no stage index series while idle, got %d", count)
+	}
+}
+
+func TestExporter_ObserveCommand(t *testing.T) {
+	exporter := NewExporter()
+	exporter.ObserveCommand("CMD_APO_SET_LAMP", 0, nil)
+	exporter.ObserveCommand("CMD_APO_SET_LAMP", 0, anova.ErrRequestNotAcknowledged{RequestID: "foo"})
+
+	for result, expected := range map[string]float64{"ok": 1, "not_acknowledged": 1, "failed": 0} {
+		value := testutil.ToFloat64(exporter.commands.WithLabelValues("CMD_APO_SET_LAMP", result))
+		if value != expected {
+			t.Errorf("expected %f commands with result %s, got %f", expected, result, value)
+		}
+	}
+}
+
+func TestExporter_ObserveUnknownFields(t *testing.T) {
+	exporter := NewExporter()
+	report := anova.UnknownFieldsReport{Command: "EVENT_APO_STATE", Fields: []string{"payload.state.nodes.newNode"}}
+	exporter.ObserveUnknownFields(report)
+	exporter.ObserveUnknownFields(report)
+
+	value := testutil.ToFloat64(exporter.unknownFields.WithLabelValues("EVENT_APO_STATE", "payload.state.nodes.newNode"))
+	if value != 2 {
+		t.Errorf("expected 2 unknown fields, got %f", value)
+	}
+}
@@ -0,0 +1,196 @@
+package history
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// Recorder writes the states of every oven to a Store, and keeps track of the
+// cooks seen in them.
+type Recorder struct {
+	store *Store
+
+	// Last state recorded for each oven, used to end cooks. Only accessed from
+	// HandleEvent.
+	lastStates map[anova.CookerID]*dto.OvenStateV1
+	ovenNames  map[anova.CookerID]string
+}
+
+func NewRecorder(store *Store) *Recorder {
+	return &Recorder{
+		store: store,
+
+		lastStates: make(map[anova.CookerID]*dto.OvenStateV1),
+		ovenNames:  make(map[anova.CookerID]string),
+	}
+}
+
+func (recorder *Recorder) HandleEvent(event anova.Event) {
+	var err error
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		recorder.ovenNames[event.Oven.CookerID] = event.Oven.Name
+		err = recorder.endAbandonedCooks(event.Oven.CookerID, event.State)
+		if err == nil {
+			err = recorder.record(event.Oven.CookerID, event.State)
+		}
+	case anova.OvenUpdated:
+		err = recorder.record(event.Oven.CookerID, event.State)
+	case anova.OvenRenamed:
+		recorder.ovenNames[event.Oven.CookerID] = event.Oven.Name
+	}
+
+	if err != nil {
+		slog.Error("failed to record oven state", slog.Any("err", err))
+	}
+}
+
+// endAbandonedCooks ends any cooks left in progress by a previous run that the
+// oven is no longer running.
+func (recorder *Recorder) endAbandonedCooks(cookerID anova.CookerID, state *dto.OvenStateV1) error {
+	currentCookID := ""
+	if state != nil && state.Cook != nil {
+		currentCookID = state.Cook.CookID
+	}
+
+	// The last state recorded for the cook is the best guess for when it ended
+	_, err := recorder.store.db.Exec(`UPDATE cooks
+		SET outcome = ?,
+			ended_at = COALESCE((SELECT MAX(recorded_at) FROM states WHERE states.cook_id = cooks.cook_id), started_at)
+		WHERE cooker_id = ? AND ended_at IS NULL AND cook_id != ?`,
+		OutcomeUnknown, cookerID, currentCookID)
+	return err
+}
+
+func (recorder *Recorder) record(cookerID anova.CookerID, state *dto.OvenStateV1) error {
+	if state == nil {
+		return nil
+	}
+
+	recordedAt := state.UpdatedTimestamp
+	if recordedAt.IsZero() {
+		recordedAt = time.Now()
+	}
+
+	tx, err := recorder.store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	previous := recorder.lastStates[cookerID]
+	if previous != nil && previous.Cook != nil && (state.Cook == nil || state.Cook.CookID != previous.Cook.CookID) {
+		err = endCook(tx, previous, recordedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	cookID := sql.NullString{}
+	if state.Cook != nil {
+		cookID = sql.NullString{String: state.Cook.CookID, Valid: true}
+		err = recorder.updateCook(tx, cookerID, state, recordedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = insertState(tx, cookerID, cookID, state, recordedAt)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	recorder.lastStates[cookerID] = state
+	return nil
+}
+
+// updateCook creates the cook the first time it's seen, and then tracks its
+// progress and peak temperatures.
+func (recorder *Recorder) updateCook(tx *sql.Tx, cookerID anova.CookerID, state *dto.OvenStateV1, recordedAt time.Time) error {
+	cook := state.Cook
+	stages, err := json.Marshal(cook.Stages)
+	if err != nil {
+		return err
+	}
+
+	// Prefer the oven's own idea of when the cook started
+	startedAt := recordedAt.Add(-time.Duration(cook.SecondsElapsed) * time.Second)
+	_, err = tx.Exec(`INSERT INTO cooks (cook_id, cooker_id, oven_name, started_at, stages)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (cook_id) DO UPDATE SET stages = excluded.stages`,
+		cook.CookID, cookerID, recorder.ovenNames[cookerID], startedAt.UnixMilli(), string(stages))
+	if err != nil {
+		return err
+	}
+
+	bulbs := state.Nodes.TemperatureBulbs
+	_, err = tx.Exec(`UPDATE cooks SET
+			last_stage_index = MAX(last_stage_index, ?),
+			peak_dry_celsius = MAX(COALESCE(peak_dry_celsius, ?), ?),
+			peak_wet_celsius = MAX(COALESCE(peak_wet_celsius, ?), ?)
+		WHERE cook_id = ?`,
+		cook.ActiveStageIndex,
+		bulbs.Dry.Current.Celsius, bulbs.Dry.Current.Celsius,
+		bulbs.Wet.Current.Celsius, bulbs.Wet.Current.Celsius,
+		cook.CookID)
+	return err
+}
+
+func endCook(tx *sql.Tx, lastState *dto.OvenStateV1, endedAt time.Time) error {
+	_, err := tx.Exec(`UPDATE cooks SET ended_at = ?, outcome = ? WHERE cook_id = ? AND ended_at IS NULL`,
+		endedAt.UnixMilli(), cookOutcome(lastState), lastState.Cook.CookID)
+	return err
+}
+
+// cookOutcome guesses how a cook ended from the last state seen during it. The
+// oven doesn't report why a cook ended, but a cook whose final stage's timer ran
+// out almost certainly completed.
+func cookOutcome(lastState *dto.OvenStateV1) Outcome {
+	cook := lastState.Cook
+	timer := lastState.Nodes.Timer
+	onFinalStage := cook.ActiveStageIndex == len(cook.Stages)-1
+	timerFinished := timer.Initial > 0 && timer.Current >= timer.Initial
+	if onFinalStage && timerFinished {
+		return OutcomeCompleted
+	}
+	return OutcomeStopped
+}
+
+func insertState(tx *sql.Tx, cookerID anova.CookerID, cookID sql.NullString, state *dto.OvenStateV1, recordedAt time.Time) error {
+	nodes := state.Nodes
+
+	var stageIndex sql.NullInt64
+	if state.Cook != nil {
+		stageIndex = sql.NullInt64{Int64: int64(state.Cook.ActiveStageIndex), Valid: true}
+	}
+	var drySetpoint, wetSetpoint sql.NullFloat64
+	if setpoint := nodes.TemperatureBulbs.Dry.Setpoint; setpoint != nil {
+		drySetpoint = sql.NullFloat64{Float64: setpoint.Celsius, Valid: true}
+	}
+	if setpoint := nodes.TemperatureBulbs.Wet.Setpoint; setpoint != nil {
+		wetSetpoint = sql.NullFloat64{Float64: setpoint.Celsius, Valid: true}
+	}
+	var relativeHumidity sql.NullInt64
+	if humidity := nodes.SteamGenerators.RelativeHumidity; humidity != nil {
+		relativeHumidity = sql.NullInt64{Int64: int64(humidity.Current), Valid: true}
+	}
+
+	_, err := tx.Exec(`INSERT INTO states (cooker_id, cook_id, recorded_at, mode, stage_index,
+			dry_celsius, dry_setpoint_celsius, wet_celsius, wet_setpoint_celsius, relative_humidity,
+			fan_speed, door_closed, vent_open, probe_connected, timer_mode, timer_initial, timer_current)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		cookerID, cookID, recordedAt.UnixMilli(), state.State.Mode, stageIndex,
+		nodes.TemperatureBulbs.Dry.Current.Celsius, drySetpoint,
+		nodes.TemperatureBulbs.Wet.Current.Celsius, wetSetpoint, relativeHumidity,
+		nodes.Fan.Speed, nodes.Door.Closed, nodes.Vent.Open, nodes.TemperatureProbe.Connected,
+		nodes.Timer.Mode, nodes.Timer.Initial, nodes.Timer.Current)
+	return err
+}
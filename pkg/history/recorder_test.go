@@ -0,0 +1,101 @@
+package history
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestState(at time.Time, cook *dto.CookV1, dryCelsius float64) *dto.OvenStateV1 {
+	state := &dto.OvenStateV1{UpdatedTimestamp: at, Cook: cook}
+	state.State.Mode = dto.StateModeIdle
+	if cook != nil {
+		state.State.Mode = dto.StateModeCook
+	}
+	state.Nodes.TemperatureBulbs.Dry.Current = dto.NewTemperatureFromCelsius(dryCelsius)
+	state.Nodes.Timer.Mode = string(dto.TimerModeIdle)
+	return state
+}
+
+func TestRecorder(t *testing.T) {
+	databasePath := filepath.Join(t.TempDir(), "history.db")
+	store, err := Open(databasePath)
+	if err != nil {
+		t.Fatalf("failed to open store: %+v", err)
+	}
+	defer store.Close()
+
+	recorder := NewRecorder(store)
+	oven := &anova.Oven{CookerID: "0123456789abcdef", Name: "Kitchen"}
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cook := &dto.CookV1{CookID: "android-cook", Stages: []dto.Stage{{ID: "android-stage-1"}, {ID: "android-stage-2"}}}
+	recorder.HandleEvent(anova.OvenAdded{Oven: oven, State: newTestState(start, cook, 25)})
+
+	hot := newTestState(start.Add(10*time.Minute), &dto.CookV1{CookID: "android-cook", Stages: cook.Stages, ActiveStageIndex: 1}, 230)
+	hot.Nodes.Timer = dto.TimerNodeV1{Mode: string(dto.TimerModeRunning), Initial: 600, Current: 600}
+	recorder.HandleEvent(anova.OvenUpdated{Oven: oven, State: hot})
+	recorder.HandleEvent(anova.OvenUpdated{Oven: oven, State: newTestState(start.Add(11*time.Minute), nil, 210)})
+
+	cooks, err := store.ListCooks("", 10)
+	if err != nil || len(cooks) != 1 {
+		t.Fatalf("expected one cook, got %+v (err: %+v)", cooks, err)
+	}
+	recorded := cooks[0]
+	if recorded.CookID != "android-cook" || recorded.OvenName != "Kitchen" || len(recorded.Stages) != 2 {
+		t.Errorf("unexpected cook %+v", recorded)
+	}
+	if !recorded.StartedAt.Equal(start) || recorded.EndedAt == nil || !recorded.EndedAt.Equal(start.Add(11*time.Minute)) {
+		t.Errorf("unexpected cook times %s to %v", recorded.StartedAt, recorded.EndedAt)
+	}
+	if recorded.Outcome != OutcomeCompleted || recorded.LastStageIndex != 1 {
+		t.Errorf("expected completed cook on final stage, got %s at stage %d", recorded.Outcome, recorded.LastStageIndex)
+	}
+	if recorded.PeakDryCelsius == nil || *recorded.PeakDryCelsius != 230 {
+		t.Errorf("expected peak dry temperature 230, got %v", recorded.PeakDryCelsius)
+	}
+
+	states, err := store.ListStates("android-cook")
+	if err != nil || len(states) != 2 {
+		t.Fatalf("expected two states during the cook, got %d (err: %+v)", len(states), err)
+	}
+	if states[1].DryCelsius != 230 || *states[1].StageIndex != 1 || states[1].TimerCurrent != 600 {
+		t.Errorf("unexpected state %+v", states[1])
+	}
+	if all, _ := store.ListStates(""); len(all) != 3 {
+		t.Errorf("expected three states in total, got %d", len(all))
+	}
+
+	_, err = store.GetCook("android-missing")
+	if !errors.As(err, &ErrCookNotFound{}) {
+		t.Errorf("expected cook not found, got %+v", err)
+	}
+}
+
+func TestRecorder_AbandonedCook(t *testing.T) {
+	databasePath := filepath.Join(t.TempDir(), "history.db")
+	store, err := Open(databasePath)
+	if err != nil {
+		t.Fatalf("failed to open store: %+v", err)
+	}
+	defer store.Close()
+
+	oven := &anova.Oven{CookerID: "0123456789abcdef", Name: "Kitchen"}
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	cook := &dto.CookV1{CookID: "android-cook", Stages: []dto.Stage{{ID: "android-stage-1"}}}
+	NewRecorder(store).HandleEvent(anova.OvenAdded{Oven: oven, State: newTestState(start, cook, 100)})
+
+	// The recorder restarts after the cook has ended
+	NewRecorder(store).HandleEvent(anova.OvenAdded{Oven: oven, State: newTestState(start.Add(time.Hour), nil, 30)})
+
+	recorded, err := store.GetCook("android-cook")
+	if err != nil {
+		t.Fatalf("failed to get cook: %+v", err)
+	}
+	if recorded.Outcome != OutcomeUnknown || recorded.EndedAt == nil || !recorded.EndedAt.Equal(start) {
+		t.Errorf("expected abandoned cook to end at its last state, got %s at %v", recorded.Outcome, recorded.EndedAt)
+	}
+}
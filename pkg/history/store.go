@@ -0,0 +1,293 @@
+// Package history records oven states and the cooks derived from them in a
+// local SQLite database.
+package history
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	_ "modernc.org/sqlite"
+	"time"
+)
+
+// schemaVersion is stored in the database's user_version. Bump it and add a
+// migration to migrate when changing the schema.
+const schemaVersion = 1
+
+const schema = `
+CREATE TABLE cooks (
+	cook_id          TEXT PRIMARY KEY,
+	cooker_id        TEXT NOT NULL,
+	oven_name        TEXT NOT NULL,
+	started_at       INTEGER NOT NULL,
+	ended_at         INTEGER,
+	stages           TEXT NOT NULL,
+	last_stage_index INTEGER NOT NULL DEFAULT 0,
+	peak_dry_celsius REAL,
+	peak_wet_celsius REAL,
+	outcome          TEXT
+);
+CREATE INDEX cooks_started_at ON cooks (started_at);
+
+CREATE TABLE states (
+	id                   INTEGER PRIMARY KEY,
+	cooker_id            TEXT NOT NULL,
+	cook_id              TEXT,
+	recorded_at          INTEGER NOT NULL,
+	mode                 TEXT NOT NULL,
+	stage_index          INTEGER,
+	dry_celsius          REAL NOT NULL,
+	dry_setpoint_celsius REAL,
+	wet_celsius          REAL NOT NULL,
+	wet_setpoint_celsius REAL,
+	relative_humidity    INTEGER,
+	fan_speed            INTEGER NOT NULL,
+	door_closed          INTEGER NOT NULL,
+	vent_open            INTEGER NOT NULL,
+	probe_connected      INTEGER NOT NULL,
+	timer_mode           TEXT NOT NULL,
+	timer_initial        INTEGER NOT NULL,
+	timer_current        INTEGER NOT NULL
+);
+CREATE INDEX states_cook_id ON states (cook_id, recorded_at);
+CREATE INDEX states_cooker_id ON states (cooker_id, recorded_at);
+`
+
+type Outcome string
+
+const (
+	// The final stage's timer ran out
+	OutcomeCompleted Outcome = "completed"
+	// The cook ended any other way, e.g. it was stopped or a probe stage finished
+	OutcomeStopped Outcome = "stopped"
+	// The cook ended while the recorder wasn't running
+	OutcomeUnknown Outcome = "unknown"
+)
+
+// Cook is a cook session derived from recorded states.
+type Cook struct {
+	CookID   string
+	CookerID anova.CookerID
+	OvenName string
+
+	StartedAt time.Time
+	// Nil while the cook is in progress
+	EndedAt *time.Time
+	// Empty while the cook is in progress
+	Outcome Outcome
+
+	Stages []dto.Stage
+	// Index of the furthest stage reached
+	LastStageIndex int
+
+	PeakDryCelsius *float64
+	PeakWetCelsius *float64
+}
+
+// State is a recorded oven state, flattened to the fields useful for reviewing
+// a cook.
+type State struct {
+	CookerID   anova.CookerID
+	CookID     string
+	RecordedAt time.Time
+	Mode       dto.StateMode
+	StageIndex *int
+
+	DryCelsius         float64
+	DrySetpointCelsius *float64
+	WetCelsius         float64
+	WetSetpointCelsius *float64
+	RelativeHumidity   *int
+
+	FanSpeed       int
+	DoorClosed     bool
+	VentOpen       bool
+	ProbeConnected bool
+
+	TimerMode    string
+	TimerInitial int
+	TimerCurrent int
+}
+
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it if needed.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database \"%s\": %+v", path, err)
+	}
+	// SQLite only supports one writer at a time
+	db.SetMaxOpenConns(1)
+
+	store := &Store{db: db}
+	err = store.migrate()
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate history database \"%s\": %+v", path, err)
+	}
+	return store, nil
+}
+
+func (store *Store) Close() error {
+	return store.db.Close()
+}
+
+func (store *Store) migrate() error {
+	var version int
+	err := store.db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version == schemaVersion:
+		return nil
+	case version > schemaVersion:
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, schemaVersion)
+	}
+
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(schema)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type ErrCookNotFound struct {
+	CookID string
+}
+
+func (err ErrCookNotFound) Error() string {
+	return fmt.Sprintf("no cook with ID \"%s\"", err.CookID)
+}
+
+const cookColumns = `cook_id, cooker_id, oven_name, started_at, ended_at, outcome, stages, last_stage_index, peak_dry_celsius, peak_wet_celsius`
+
+// ListCooks returns the most recent cooks first. If oven is set, only cooks
+// from the oven with that cooker ID or name are returned.
+func (store *Store) ListCooks(oven string, limit int) ([]Cook, error) {
+	rows, err := store.db.Query(`SELECT `+cookColumns+` FROM cooks
+		WHERE ? = '' OR cooker_id = ? OR oven_name = ? COLLATE NOCASE
+		ORDER BY started_at DESC
+		LIMIT ?`, oven, oven, oven, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cooks []Cook
+	for rows.Next() {
+		cook, err := scanCook(rows)
+		if err != nil {
+			return nil, err
+		}
+		cooks = append(cooks, *cook)
+	}
+	return cooks, rows.Err()
+}
+
+func (store *Store) GetCook(cookID string) (*Cook, error) {
+	row := store.db.QueryRow(`SELECT `+cookColumns+` FROM cooks WHERE cook_id = ?`, cookID)
+	cook, err := scanCook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCookNotFound{CookID: cookID}
+	}
+	return cook, err
+}
+
+func scanCook(row interface{ Scan(...any) error }) (*Cook, error) {
+	var cook Cook
+	var startedAt int64
+	var endedAt sql.NullInt64
+	var outcome sql.NullString
+	var stages string
+	var peakDry, peakWet sql.NullFloat64
+	err := row.Scan(&cook.CookID, &cook.CookerID, &cook.OvenName, &startedAt, &endedAt, &outcome,
+		&stages, &cook.LastStageIndex, &peakDry, &peakWet)
+	if err != nil {
+		return nil, err
+	}
+
+	cook.StartedAt = time.UnixMilli(startedAt)
+	if endedAt.Valid {
+		ended := time.UnixMilli(endedAt.Int64)
+		cook.EndedAt = &ended
+	}
+	cook.Outcome = Outcome(outcome.String)
+	if peakDry.Valid {
+		cook.PeakDryCelsius = &peakDry.Float64
+	}
+	if peakWet.Valid {
+		cook.PeakWetCelsius = &peakWet.Float64
+	}
+	err = json.Unmarshal([]byte(stages), &cook.Stages)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stages for cook \"%s\": %+v", cook.CookID, err)
+	}
+	return &cook, nil
+}
+
+const stateColumns = `cooker_id, COALESCE(cook_id, ''), recorded_at, mode, stage_index,
+	dry_celsius, dry_setpoint_celsius, wet_celsius, wet_setpoint_celsius, relative_humidity,
+	fan_speed, door_closed, vent_open, probe_connected, timer_mode, timer_initial, timer_current`
+
+// ListStates returns recorded states in chronological order, either for a
+// single cook or, if cookID is empty, for every oven.
+func (store *Store) ListStates(cookID string) ([]State, error) {
+	rows, err := store.db.Query(`SELECT `+stateColumns+` FROM states
+		WHERE ? = '' OR cook_id = ?
+		ORDER BY recorded_at, id`, cookID, cookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []State
+	for rows.Next() {
+		var state State
+		var recordedAt int64
+		var stageIndex, relativeHumidity sql.NullInt64
+		var drySetpoint, wetSetpoint sql.NullFloat64
+		err := rows.Scan(&state.CookerID, &state.CookID, &recordedAt, &state.Mode, &stageIndex,
+			&state.DryCelsius, &drySetpoint, &state.WetCelsius, &wetSetpoint, &relativeHumidity,
+			&state.FanSpeed, &state.DoorClosed, &state.VentOpen, &state.ProbeConnected,
+			&state.TimerMode, &state.TimerInitial, &state.TimerCurrent)
+		if err != nil {
+			return nil, err
+		}
+
+		state.RecordedAt = time.UnixMilli(recordedAt)
+		if stageIndex.Valid {
+			index := int(stageIndex.Int64)
+			state.StageIndex = &index
+		}
+		if drySetpoint.Valid {
+			state.DrySetpointCelsius = &drySetpoint.Float64
+		}
+		if wetSetpoint.Valid {
+			state.WetSetpointCelsius = &wetSetpoint.Float64
+		}
+		if relativeHumidity.Valid {
+			humidity := int(relativeHumidity.Int64)
+			state.RelativeHumidity = &humidity
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
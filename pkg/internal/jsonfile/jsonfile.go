@@ -0,0 +1,54 @@
+// Package jsonfile keeps values in JSON files that several processes share,
+// e.g. `apocli cook start` and `apocli daemon`.
+package jsonfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Lock takes an exclusive lock on the file, shared with other processes, until
+// unlock is called. Hold it while reading, changing and writing the file, so
+// that no changes are lost. The lock is taken on a separate file, as Write
+// replaces the file itself.
+func Lock(path string) (unlock func(), err error) {
+	fh, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(fh.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		_ = fh.Close()
+		return nil, err
+	}
+	return func() {
+		_ = syscall.Flock(int(fh.Fd()), syscall.LOCK_UN)
+		_ = fh.Close()
+	}, nil
+}
+
+// Write replaces the file with the value as indented JSON in one go, so that
+// other processes never read a partially written file.
+func Write(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fh, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fh.Name())
+
+	_, err = fh.Write(data)
+	if closeErr := fh.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(fh.Name(), path)
+}
@@ -0,0 +1,50 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestLockAndWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.json")
+
+	// Each increment reads, changes and writes the file under the lock, so none
+	// are lost
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				unlock, err := Lock(path)
+				if err != nil {
+					t.Errorf("lock failed: %+v", err)
+					return
+				}
+				var counter int
+				if data, err := os.ReadFile(path); err == nil {
+					_ = json.Unmarshal(data, &counter)
+				}
+				if err := Write(path, counter+1); err != nil {
+					t.Errorf("write failed: %+v", err)
+				}
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "200" {
+		t.Errorf("expected 200 increments, got %s (%+v)", data, err)
+	}
+
+	// Temporary files are cleaned up
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 2 {
+		t.Errorf("expected only the file and its lock, got %d files", len(entries))
+	}
+}
@@ -0,0 +1,179 @@
+// Package longcook follows cooks started with anova.NewLongCook, whose long
+// timers the oven runs as chains of stages. The chains are kept in a JSON file,
+// so that a cook's progress can be followed as a single cook across restarts.
+package longcook
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"apocli/pkg/internal/jsonfile"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record maps the stages of a long cook to the stages the oven runs.
+type Record struct {
+	CookID   string         `json:"cookId"`
+	CookerID anova.CookerID `json:"cookerId"`
+	// Name of the oven when the cook was started, for display only
+	OvenName  string        `json:"ovenName,omitempty"`
+	StartedAt time.Time     `json:"startedAt"`
+	Stages    []RecordStage `json:"stages"`
+}
+
+type RecordStage struct {
+	// IDs of the oven's stages: the preheat stage, if any, the cook stage, and
+	// the stages continuing its timer
+	StageIDs []string `json:"stageIds"`
+}
+
+func NewRecord(cook *anova.Cook, oven *anova.Oven, startedAt time.Time) Record {
+	record := Record{
+		CookID:    cook.ID(),
+		CookerID:  oven.CookerID,
+		OvenName:  oven.Name,
+		StartedAt: startedAt,
+	}
+	for _, stage := range cook.Stages {
+		record.Stages = append(record.Stages, RecordStage{StageIDs: stage.StageIDs()})
+	}
+	return record
+}
+
+// Progress is how far a long cook has got, counting each chain of stages as a
+// single stage.
+type Progress struct {
+	// Index of the running stage of the cook, and the number of stages
+	Stage  int
+	Stages int
+	// Whether the stage is preheating. Its timer hasn't started yet.
+	Preheating bool
+	// Time the stage's timer has run for across the chain, and its total, if
+	// the stage has a timer
+	TimerElapsed  time.Duration
+	TimerDuration time.Duration
+}
+
+// Progress works out how far the cook has got from the oven's state. It
+// returns false if the oven isn't running the recorded cook.
+func (record Record) Progress(state *dto.OvenStateV1) (Progress, bool) {
+	if state == nil || state.Cook == nil || state.Cook.CookID != record.CookID ||
+		state.State.Mode != dto.StateModeCook {
+		return Progress{}, false
+	}
+
+	ovenStages := make(map[string]dto.Stage, len(state.Cook.Stages))
+	for _, stage := range state.Cook.Stages {
+		ovenStages[stage.ID] = stage
+	}
+
+	for i, recordStage := range record.Stages {
+		progress := Progress{Stage: i, Stages: len(record.Stages)}
+		active := false
+		for _, id := range recordStage.StageIDs {
+			ovenStage, exists := ovenStages[id]
+			if !exists || ovenStage.Type == dto.StageTypePreheat {
+				if id == state.Cook.ActiveStageID {
+					active = true
+					progress.Preheating = true
+				}
+				continue
+			}
+
+			seconds := 0
+			if ovenStage.Timer != nil {
+				seconds = ovenStage.Timer.Initial
+			}
+			progress.TimerDuration += time.Duration(seconds) * time.Second
+			switch {
+			case id == state.Cook.ActiveStageID:
+				active = true
+				progress.TimerElapsed += time.Duration(state.Nodes.Timer.Current) * time.Second
+			case !active && !progress.Preheating:
+				// Earlier in the chain, so already run
+				progress.TimerElapsed += time.Duration(seconds) * time.Second
+			}
+		}
+		if active {
+			return progress, true
+		}
+	}
+	return Progress{}, false
+}
+
+// Store keeps the record of the last long cook started on each oven in a JSON
+// file. Like scheduler.Store, the file is read again on every call, and changed
+// under a lock shared with other processes.
+type Store struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Save saves the record, replacing any earlier record for its oven.
+func (store *Store) Save(record Record) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	unlock, err := jsonfile.Lock(store.path)
+	if err != nil {
+		return fmt.Errorf("failed to lock long cooks \"%s\": %+v", store.path, err)
+	}
+	defer unlock()
+
+	records, err := store.read()
+	if err != nil {
+		return err
+	}
+	records[record.CookerID] = record
+	return store.write(records)
+}
+
+// Get returns the record of the last long cook started on the oven, or nil if
+// there is none.
+func (store *Store) Get(cookerID anova.CookerID) (*Record, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	records, err := store.read()
+	if err != nil {
+		return nil, err
+	}
+	record, exists := records[cookerID]
+	if !exists {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+func (store *Store) read() (map[anova.CookerID]Record, error) {
+	records := make(map[anova.CookerID]Record)
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read long cooks \"%s\": %+v", store.path, err)
+	}
+
+	err = json.Unmarshal(data, &records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode long cooks \"%s\": %+v", store.path, err)
+	}
+	return records, nil
+}
+
+func (store *Store) write(records map[anova.CookerID]Record) error {
+	err := jsonfile.Write(store.path, records)
+	if err != nil {
+		return fmt.Errorf("failed to write long cooks \"%s\": %+v", store.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package longcook
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newLongCook(t *testing.T) *anova.Cook {
+	t.Helper()
+	cook, err := anova.Recipe{Long: true, Stages: []anova.RecipeStage{
+		{Mode: anova.TemperatureModeDry, TemperatureCelsius: 60, HeatingElements: []string{"rear"}, Timer: &anova.RecipeTimer{Duration: "240h"}},
+		{Mode: anova.TemperatureModeDry, TemperatureCelsius: 30, HeatingElements: []string{"rear"}},
+	}}.NewCook()
+	if err != nil {
+		t.Fatalf("expected valid long cook: %+v", err)
+	}
+	return cook
+}
+
+// runningState returns the state of the oven running the cook's stages, with
+// the timer of the active stage at the given number of seconds.
+func runningState(cook *anova.Cook, activeIndex int, timerSeconds int) *dto.OvenStateV1 {
+	state := &dto.OvenStateV1{}
+	state.State.Mode = dto.StateModeCook
+	state.Cook = &dto.CookV1{CookID: cook.ID()}
+	for _, stage := range anova.CookStages(cook.Stages).ToDto() {
+		state.Cook.Stages = append(state.Cook.Stages, dto.Stage{ID: stage.ID, Type: stage.Type, Timer: stage.Timer})
+	}
+	state.Cook.ActiveStageIndex = activeIndex
+	state.Cook.ActiveStageID = state.Cook.Stages[activeIndex].ID
+	state.Nodes.Timer.Current = timerSeconds
+	return state
+}
+
+func TestRecord_Progress(t *testing.T) {
+	cook := newLongCook(t)
+	record := NewRecord(cook, &anova.Oven{CookerID: "oven"}, time.Now())
+
+	// Stages: preheat, three chained cook stages, then preheat and cook for the
+	// second stage
+	for _, test := range []struct {
+		activeIndex  int
+		timerSeconds int
+		expected     Progress
+	}{
+		{0, 0, Progress{Stage: 0, Stages: 2, Preheating: true, TimerDuration: 240 * time.Hour}},
+		{1, 3600, Progress{Stage: 0, Stages: 2, TimerElapsed: time.Hour, TimerDuration: 240 * time.Hour}},
+		{3, 3600, Progress{Stage: 0, Stages: 2, TimerElapsed: 2*359940*time.Second + time.Hour, TimerDuration: 240 * time.Hour}},
+		{5, 0, Progress{Stage: 1, Stages: 2}},
+	} {
+		progress, ok := record.Progress(runningState(cook, test.activeIndex, test.timerSeconds))
+		if !ok || progress != test.expected {
+			t.Errorf("expected %+v at stage %d, got %+v", test.expected, test.activeIndex, progress)
+		}
+	}
+
+	other := runningState(cook, 1, 0)
+	other.Cook.CookID = "other"
+	if _, ok := record.Progress(other); ok {
+		t.Errorf("expected no progress for another cook")
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "longcooks.json"))
+	if record, err := store.Get("oven"); err != nil || record != nil {
+		t.Fatalf("expected no record, got %+v, %+v", record, err)
+	}
+
+	first := NewRecord(newLongCook(t), &anova.Oven{CookerID: "oven"}, time.Now())
+	second := NewRecord(newLongCook(t), &anova.Oven{CookerID: "oven"}, time.Now())
+	for _, record := range []Record{first, second} {
+		if err := store.Save(record); err != nil {
+			t.Fatalf("save failed: %+v", err)
+		}
+	}
+
+	// Only the last cook on each oven is kept
+	record, err := store.Get("oven")
+	if err != nil || record == nil || record.CookID != second.CookID || len(record.Stages) != 2 ||
+		len(record.Stages[0].StageIDs) != 4 {
+		t.Errorf("unexpected record %+v, %+v", record, err)
+	}
+}
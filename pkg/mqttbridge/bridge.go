@@ -0,0 +1,414 @@
+// Package mqttbridge publishes oven state to an MQTT broker and accepts
+// commands from it, including Home Assistant discovery configs.
+package mqttbridge
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultTopicPrefix     = "apocli"
+	DefaultDiscoveryPrefix = "homeassistant"
+
+	// Temperature used when the climate entity is turned on without a target
+	defaultClimateTemperatureCelsius = 180
+)
+
+// Connection is the subset of an MQTT client used by the bridge.
+type Connection interface {
+	Publish(topic string, payload []byte, retained bool) error
+	// Subscribe passes live messages to the handler. Retained messages are
+	// ignored, as every topic subscribed to is a command topic.
+	Subscribe(topic string, handler func(topic string, payload []byte)) error
+}
+
+type Options struct {
+	// Prefix of all state and command topics
+	TopicPrefix string
+	// Prefix of Home Assistant discovery topics. Empty to disable discovery.
+	DiscoveryPrefix string
+}
+
+// Bridge publishes state for every oven added to a Service and carries out
+// commands received on each oven's command topics.
+type Bridge struct {
+	conn      Connection
+	options   Options
+	observers []func(recipe anova.Recipe, cook *anova.Cook, oven *anova.Oven)
+
+	mutex sync.Mutex
+	ovens map[anova.CookerID]*ovenEntry
+}
+
+type ovenEntry struct {
+	oven  *anova.Oven
+	state *dto.OvenStateV1
+	// Last temperature requested through the climate entity
+	climateTemperatureCelsius float64
+}
+
+// NewBridge creates a bridge publishing to conn. The observers are called with
+// every cook started through the cook/start topics.
+func NewBridge(conn Connection, options Options, observers ...func(recipe anova.Recipe, cook *anova.Cook, oven *anova.Oven)) *Bridge {
+	return &Bridge{
+		conn:      conn,
+		options:   options,
+		observers: observers,
+
+		ovens: make(map[anova.CookerID]*ovenEntry),
+	}
+}
+
+// StatusTopic is where the bridge's own availability is published. It should be
+// used as the MQTT client's last will, with the payload "offline".
+func (options Options) StatusTopic() string {
+	return options.TopicPrefix + "/status"
+}
+
+func (bridge *Bridge) StatusTopic() string {
+	return bridge.options.StatusTopic()
+}
+
+func (bridge *Bridge) ovenTopic(cookerID anova.CookerID, suffix string) string {
+	return fmt.Sprintf("%s/%s/%s", bridge.options.TopicPrefix, cookerID, suffix)
+}
+
+func (bridge *Bridge) HandleEvent(event anova.Event) {
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		bridge.mutex.Lock()
+		entry := &ovenEntry{
+			oven:  event.Oven,
+			state: event.State,
+
+			climateTemperatureCelsius: defaultClimateTemperatureCelsius,
+		}
+		bridge.ovens[event.Oven.CookerID] = entry
+		bridge.mutex.Unlock()
+
+		bridge.publish(bridge.StatusTopic(), []byte("online"))
+		bridge.subscribe(event.Oven.CookerID)
+		bridge.publishDiscovery(event.Oven.CookerID, event.Oven.Name, event.State)
+		bridge.publishState(event.Oven.CookerID, event.State)
+
+	case anova.OvenUpdated:
+		bridge.mutex.Lock()
+		entry, exists := bridge.ovens[event.Oven.CookerID]
+		unitChanged := false
+		if exists {
+			unitChanged = event.State != nil && temperatureUnit(entry.state) != temperatureUnit(event.State)
+			entry.state = event.State
+		}
+		bridge.mutex.Unlock()
+
+		// The climate entity's unit and range are part of its discovery config
+		if unitChanged {
+			bridge.publishDiscovery(event.Oven.CookerID, event.Oven.Name, event.State)
+		}
+		bridge.publishState(event.Oven.CookerID, event.State)
+
+	case anova.OvenRenamed:
+		bridge.mutex.Lock()
+		entry, exists := bridge.ovens[event.Oven.CookerID]
+		bridge.mutex.Unlock()
+		if exists {
+			bridge.publishDiscovery(event.Oven.CookerID, event.Oven.Name, entry.state)
+		}
+
+	case anova.ServiceStopped:
+		bridge.publish(bridge.StatusTopic(), []byte("offline"))
+	}
+}
+
+func (bridge *Bridge) publish(topic string, payload []byte) {
+	err := bridge.conn.Publish(topic, payload, true)
+	if err != nil {
+		slog.Error("failed to publish MQTT message",
+			slog.String("topic", topic),
+			slog.Any("err", err))
+	}
+}
+
+func (bridge *Bridge) publishJSON(topic string, value interface{}) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		slog.Error("failed to marshal MQTT payload",
+			slog.String("topic", topic),
+			slog.Any("err", err))
+		return
+	}
+	bridge.publish(topic, payload)
+}
+
+// statePayload is the retained JSON published on each oven's state topic.
+type statePayload struct {
+	Mode            dto.StateMode       `json:"mode"`
+	Online          bool                `json:"online"`
+	TemperatureUnit dto.TemperatureUnit `json:"temperatureUnit"`
+
+	DryTemperature         float64  `json:"dryTemperature"`
+	DryTemperatureSetpoint *float64 `json:"dryTemperatureSetpoint"`
+	WetTemperature         float64  `json:"wetTemperature"`
+	WetTemperatureSetpoint *float64 `json:"wetTemperatureSetpoint"`
+	// Setpoint of whichever bulb is active
+	TemperatureSetpoint *float64 `json:"temperatureSetpoint"`
+	RelativeHumidity    *int     `json:"relativeHumidity"`
+	// Dry bulb temperature and active setpoint in the oven's temperature unit,
+	// for the climate entity
+	ClimateTemperature         float64  `json:"climateTemperature"`
+	ClimateTemperatureSetpoint *float64 `json:"climateTemperatureSetpoint"`
+
+	FanSpeed       int    `json:"fanSpeed"`
+	Door           string `json:"door"`
+	Lamp           string `json:"lamp"`
+	VentOpen       bool   `json:"ventOpen"`
+	WaterTankEmpty bool   `json:"waterTankEmpty"`
+	ProbeConnected bool   `json:"probeConnected"`
+
+	TimerRemaining *int `json:"timerRemaining"`
+	StageIndex     *int `json:"stageIndex"`
+}
+
+func newStatePayload(state *dto.OvenStateV1) statePayload {
+	nodes := state.Nodes
+	payload := statePayload{
+		Mode:            state.State.Mode,
+		Online:          state.SystemInfo.Online,
+		TemperatureUnit: state.State.TemperatureUnit,
+
+		DryTemperature: nodes.TemperatureBulbs.Dry.Current.Celsius,
+		WetTemperature: nodes.TemperatureBulbs.Wet.Current.Celsius,
+
+		FanSpeed:       nodes.Fan.Speed,
+		Door:           "open",
+		Lamp:           "OFF",
+		VentOpen:       nodes.Vent.Open,
+		WaterTankEmpty: nodes.WaterTank.Empty,
+		ProbeConnected: nodes.TemperatureProbe.Connected,
+	}
+
+	if setpoint := nodes.TemperatureBulbs.Dry.Setpoint; setpoint != nil {
+		payload.DryTemperatureSetpoint = &setpoint.Celsius
+	}
+	if setpoint := nodes.TemperatureBulbs.Wet.Setpoint; setpoint != nil {
+		payload.WetTemperatureSetpoint = &setpoint.Celsius
+	}
+	if nodes.TemperatureBulbs.Mode == dto.TemperatureBulbsModeWet {
+		payload.TemperatureSetpoint = payload.WetTemperatureSetpoint
+	} else {
+		payload.TemperatureSetpoint = payload.DryTemperatureSetpoint
+	}
+	payload.ClimateTemperature = inUnit(payload.DryTemperature, payload.TemperatureUnit)
+	if payload.TemperatureSetpoint != nil {
+		setpoint := inUnit(*payload.TemperatureSetpoint, payload.TemperatureUnit)
+		payload.ClimateTemperatureSetpoint = &setpoint
+	}
+	if relativeHumidity := nodes.SteamGenerators.RelativeHumidity; relativeHumidity != nil {
+		payload.RelativeHumidity = &relativeHumidity.Current
+	}
+
+	if nodes.Door.Closed {
+		payload.Door = "closed"
+	}
+	if nodes.Lamp.On {
+		payload.Lamp = "ON"
+	}
+
+	if nodes.Timer.Mode != string(dto.TimerModeIdle) {
+		remaining := nodes.Timer.Initial - nodes.Timer.Current
+		payload.TimerRemaining = &remaining
+	}
+	if state.Cook != nil {
+		payload.StageIndex = &state.Cook.ActiveStageIndex
+	}
+
+	return payload
+}
+
+// inUnit converts a temperature to the unit, to a tenth of a degree.
+func inUnit(celsius float64, unit dto.TemperatureUnit) float64 {
+	return math.Round(anova.NewTemperatureCelsius(celsius).In(unit)*10) / 10
+}
+
+func (bridge *Bridge) publishState(cookerID anova.CookerID, state *dto.OvenStateV1) {
+	if state == nil {
+		return
+	}
+
+	availability := "offline"
+	if state.SystemInfo.Online {
+		availability = "online"
+	}
+	bridge.publish(bridge.ovenTopic(cookerID, "availability"), []byte(availability))
+	bridge.publishJSON(bridge.ovenTopic(cookerID, "state"), newStatePayload(state))
+}
+
+func (bridge *Bridge) subscribe(cookerID anova.CookerID) {
+	for _, suffix := range []string{"lamp/set", "cook/start", "cook/stop", "climate/mode/set", "climate/temperature/set"} {
+		topic := bridge.ovenTopic(cookerID, suffix)
+		err := bridge.conn.Subscribe(topic, bridge.handleCommand)
+		if err != nil {
+			slog.Error("failed to subscribe to MQTT topic",
+				slog.String("topic", topic),
+				slog.Any("err", err))
+		}
+	}
+}
+
+func (bridge *Bridge) handleCommand(topic string, payload []byte) {
+	err := bridge.executeCommand(topic, payload)
+	if err != nil {
+		slog.Error("failed to execute MQTT command",
+			slog.String("topic", topic),
+			slog.String("payload", string(payload)),
+			slog.Any("err", err))
+	}
+}
+
+func (bridge *Bridge) executeCommand(topic string, payload []byte) error {
+	rest, found := strings.CutPrefix(topic, bridge.options.TopicPrefix+"/")
+	if !found {
+		return fmt.Errorf("unexpected topic")
+	}
+	rawCookerID, command, found := strings.Cut(rest, "/")
+	if !found {
+		return fmt.Errorf("unexpected topic")
+	}
+
+	bridge.mutex.Lock()
+	entry, exists := bridge.ovens[anova.CookerID(rawCookerID)]
+	bridge.mutex.Unlock()
+	if !exists {
+		return fmt.Errorf("unknown oven \"%s\"", rawCookerID)
+	}
+	oven := entry.oven
+
+	switch command {
+	case "lamp/set":
+		switch strings.ToUpper(strings.TrimSpace(string(payload))) {
+		case "ON":
+			return oven.TurnOnLamp(true)
+		case "OFF":
+			return oven.TurnOnLamp(false)
+		default:
+			return fmt.Errorf("expected ON or OFF")
+		}
+
+	case "cook/stop":
+		return oven.StopCook()
+
+	case "cook/start":
+		recipe, err := parseRecipe(payload)
+		if err != nil {
+			return err
+		}
+		cook, err := recipe.NewCook()
+		if err != nil {
+			return err
+		}
+		err = cook.Start(oven)
+		if err != nil {
+			return err
+		}
+		for _, observer := range bridge.observers {
+			observer(recipe, cook, oven)
+		}
+		return nil
+
+	case "climate/mode/set":
+		switch strings.TrimSpace(string(payload)) {
+		case "off":
+			return oven.StopCook()
+		case "heat":
+			bridge.mutex.Lock()
+			temperature := entry.climateTemperatureCelsius
+			bridge.mutex.Unlock()
+			return startClimateCook(oven, temperature)
+		default:
+			return fmt.Errorf("expected heat or off")
+		}
+
+	case "climate/temperature/set":
+		value, err := strconv.ParseFloat(strings.TrimSpace(string(payload)), 64)
+		if err != nil {
+			return fmt.Errorf("invalid temperature: %+v", err)
+		}
+		bridge.mutex.Lock()
+		// The climate entity uses the oven's temperature unit
+		temperature := anova.NewTemperatureCelsius(value)
+		if temperatureUnit(entry.state) == dto.TemperatureUnitFahrenheit {
+			temperature = anova.NewTemperatureFahrenheit(value)
+		}
+		entry.climateTemperatureCelsius = temperature.Celsius()
+		cooking := entry.state != nil && entry.state.State.Mode == dto.StateModeCook
+		mode := anova.TemperatureMode(anova.TemperatureModeDry)
+		if cooking && entry.state.Nodes.TemperatureBulbs.Mode == dto.TemperatureBulbsModeWet {
+			mode = anova.TemperatureModeWet
+		}
+		bridge.mutex.Unlock()
+		// Only change the running stage's setpoint, leaving the rest of the cook
+		// as it is; otherwise the temperature is used the next time the entity is
+		// turned on
+		if !cooking {
+			return nil
+		}
+		return oven.SetTemperatureSetpoint(anova.NewSetpointTemperature(temperature, mode))
+	}
+
+	return fmt.Errorf("unknown command \"%s\"", command)
+}
+
+// temperatureUnit returns the oven's temperature unit, which is Celsius until
+// its state is known.
+func temperatureUnit(state *dto.OvenStateV1) dto.TemperatureUnit {
+	if state == nil || state.State.TemperatureUnit != dto.TemperatureUnitFahrenheit {
+		return dto.TemperatureUnitCelsius
+	}
+	return dto.TemperatureUnitFahrenheit
+}
+
+// parseRecipe accepts either a full recipe with "stages", or a single stage.
+func parseRecipe(payload []byte) (anova.Recipe, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(payload, &fields)
+	if err != nil {
+		return anova.Recipe{}, fmt.Errorf("invalid cook payload: %+v", err)
+	}
+
+	var recipe anova.Recipe
+	if _, isRecipe := fields["stages"]; isRecipe {
+		err = json.Unmarshal(payload, &recipe)
+	} else {
+		recipe.Stages = make([]anova.RecipeStage, 1)
+		err = json.Unmarshal(payload, &recipe.Stages[0])
+	}
+	if err != nil {
+		return anova.Recipe{}, fmt.Errorf("invalid cook payload: %+v", err)
+	}
+	return recipe, nil
+}
+
+// startClimateCook starts an open-ended, single-stage cook with the same
+// defaults as the URL handler.
+func startClimateCook(oven *anova.Oven, temperatureCelsius float64) error {
+	stage := anova.NewCookStage(
+		anova.RackPositionMiddle,
+		anova.FanSpeedHigh,
+		anova.NewNonSousVideSetpointCelsius(temperatureCelsius),
+		anova.RearElementOnly,
+		anova.NoSteam,
+		nil)
+	cook, err := anova.NewCook(stage)
+	if err != nil {
+		return err
+	}
+	return cook.Start(oven)
+}
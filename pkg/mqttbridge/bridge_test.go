@@ -0,0 +1,180 @@
+package mqttbridge
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// fakeBroker records retained messages and subscriptions in memory.
+type fakeBroker struct {
+	retained      map[string][]byte
+	subscriptions map[string]func(topic string, payload []byte)
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{
+		retained:      make(map[string][]byte),
+		subscriptions: make(map[string]func(topic string, payload []byte)),
+	}
+}
+
+func (broker *fakeBroker) Publish(topic string, payload []byte, retained bool) error {
+	if retained {
+		broker.retained[topic] = payload
+	}
+	return nil
+}
+
+func (broker *fakeBroker) Subscribe(topic string, handler func(topic string, payload []byte)) error {
+	broker.subscriptions[topic] = handler
+	return nil
+}
+
+func TestBridge_HandleEvent(t *testing.T) {
+	broker := newFakeBroker()
+	bridge := NewBridge(broker, Options{TopicPrefix: DefaultTopicPrefix, DiscoveryPrefix: DefaultDiscoveryPrefix})
+	oven := &anova.Oven{CookerID: "0123456789abcdef", Name: "Kitchen"}
+
+	state := &dto.OvenStateV1{}
+	state.SystemInfo.Online = true
+	state.State.Mode = dto.StateModeCook
+	state.Nodes.TemperatureBulbs.Mode = dto.TemperatureBulbsModeDry
+	state.Nodes.TemperatureBulbs.Dry.Current = dto.NewTemperatureFromCelsius(150)
+	setpoint := dto.NewTemperatureFromCelsius(200)
+	state.Nodes.TemperatureBulbs.Dry.Setpoint = &setpoint
+	state.Nodes.Door.Closed = true
+	state.Nodes.Lamp.On = true
+
+	bridge.HandleEvent(anova.OvenAdded{Oven: oven, State: state})
+
+	if status := string(broker.retained["apocli/status"]); status != "online" {
+		t.Errorf("expected bridge to be online, got \"%s\"", status)
+	}
+	if availability := string(broker.retained["apocli/0123456789abcdef/availability"]); availability != "online" {
+		t.Errorf("expected oven to be online, got \"%s\"", availability)
+	}
+
+	var payload statePayload
+	err := json.Unmarshal(broker.retained["apocli/0123456789abcdef/state"], &payload)
+	if err != nil {
+		t.Fatalf("invalid state payload: %+v", err)
+	}
+	if payload.DryTemperature != 150 || payload.TemperatureSetpoint == nil || *payload.TemperatureSetpoint != 200 ||
+		payload.Door != "closed" || payload.Lamp != "ON" || payload.Mode != dto.StateModeCook {
+		t.Errorf("unexpected state payload %+v", payload)
+	}
+
+	var climate map[string]interface{}
+	err = json.Unmarshal(broker.retained["homeassistant/climate/apocli_0123456789abcdef/oven/config"], &climate)
+	if err != nil {
+		t.Fatalf("invalid climate discovery config: %+v", err)
+	}
+	if climate["unique_id"] != "apocli_0123456789abcdef_oven" ||
+		climate["temperature_command_topic"] != "apocli/0123456789abcdef/climate/temperature/set" {
+		t.Errorf("unexpected climate discovery config %+v", climate)
+	}
+	if _, subscribed := broker.subscriptions["apocli/0123456789abcdef/lamp/set"]; !subscribed {
+		t.Errorf("expected subscription to lamp command topic")
+	}
+
+	// Renaming the oven should update the device name
+	oven.Name = "Garage"
+	bridge.HandleEvent(anova.OvenRenamed{Oven: oven, OldName: "Kitchen"})
+	if config := string(broker.retained["homeassistant/switch/apocli_0123456789abcdef/lamp/config"]); !strings.Contains(config, `"name":"Garage"`) {
+		t.Errorf("expected renamed device in discovery config, got %s", config)
+	}
+
+	bridge.HandleEvent(anova.ServiceStopped{})
+	if status := string(broker.retained["apocli/status"]); status != "offline" {
+		t.Errorf("expected bridge to be offline, got \"%s\"", status)
+	}
+}
+
+func TestBridge_DiscoveryDisabled(t *testing.T) {
+	broker := newFakeBroker()
+	bridge := NewBridge(broker, Options{TopicPrefix: "ovens"})
+	bridge.HandleEvent(anova.OvenAdded{Oven: &anova.Oven{CookerID: "0123456789abcdef"}, State: &dto.OvenStateV1{}})
+
+	for topic := range broker.retained {
+		if !strings.HasPrefix(topic, "ovens/") {
+			t.Errorf("unexpected message on \"%s\" with discovery disabled", topic)
+		}
+	}
+}
+
+func TestBridge_ExecuteCommand_Invalid(t *testing.T) {
+	broker := newFakeBroker()
+	bridge := NewBridge(broker, Options{TopicPrefix: DefaultTopicPrefix})
+	bridge.HandleEvent(anova.OvenAdded{Oven: &anova.Oven{CookerID: "0123456789abcdef"}, State: &dto.OvenStateV1{}})
+
+	for _, testCase := range []struct {
+		topic   string
+		payload string
+	}{
+		{"apocli/fedcba9876543210/cook/stop", ""},
+		{"apocli/0123456789abcdef/lamp/set", "maybe"},
+		{"apocli/0123456789abcdef/cook/start", "{"},
+		{"apocli/0123456789abcdef/cook/start", `{"stages":[]}`},
+		{"apocli/0123456789abcdef/climate/mode/set", "cool"},
+		{"apocli/0123456789abcdef/climate/temperature/set", "hot"},
+	} {
+		err := bridge.executeCommand(testCase.topic, []byte(testCase.payload))
+		if err == nil {
+			t.Errorf("expected error for \"%s\" on %s", testCase.payload, testCase.topic)
+		}
+	}
+
+	// Setting the temperature while idle only stores it for later
+	err := bridge.executeCommand("apocli/0123456789abcdef/climate/temperature/set", []byte("200"))
+	if err != nil {
+		t.Errorf("expected temperature to be stored: %+v", err)
+	}
+	if temperature := bridge.ovens["0123456789abcdef"].climateTemperatureCelsius; temperature != 200 {
+		t.Errorf("expected stored temperature 200, got %f", temperature)
+	}
+}
+
+func TestBridge_Fahrenheit(t *testing.T) {
+	broker := newFakeBroker()
+	bridge := NewBridge(broker, Options{TopicPrefix: DefaultTopicPrefix, DiscoveryPrefix: DefaultDiscoveryPrefix})
+	oven := &anova.Oven{CookerID: "0123456789abcdef", Name: "Kitchen"}
+	bridge.HandleEvent(anova.OvenAdded{Oven: oven, State: &dto.OvenStateV1{}})
+
+	state := &dto.OvenStateV1{}
+	state.State.TemperatureUnit = dto.TemperatureUnitFahrenheit
+	state.Nodes.TemperatureBulbs.Mode = dto.TemperatureBulbsModeDry
+	state.Nodes.TemperatureBulbs.Dry.Current = dto.NewTemperatureFromCelsius(100)
+	setpoint := dto.NewTemperatureFromCelsius(200)
+	state.Nodes.TemperatureBulbs.Dry.Setpoint = &setpoint
+	bridge.HandleEvent(anova.OvenUpdated{Oven: oven, State: state})
+
+	// Changing the oven's unit should update the climate entity's unit and range
+	var climate discoveryEntity
+	err := json.Unmarshal(broker.retained["homeassistant/climate/apocli_0123456789abcdef/oven/config"], &climate)
+	if err != nil {
+		t.Fatalf("invalid climate discovery config: %+v", err)
+	}
+	if climate.TemperatureUnit != "F" || climate.MinTemp != 77 || climate.MaxTemp != 482 {
+		t.Errorf("expected 77 to 482 °F, got %g to %g %s", climate.MinTemp, climate.MaxTemp, climate.TemperatureUnit)
+	}
+
+	var payload statePayload
+	err = json.Unmarshal(broker.retained["apocli/0123456789abcdef/state"], &payload)
+	if err != nil {
+		t.Fatalf("invalid state payload: %+v", err)
+	}
+	if payload.ClimateTemperature != 212 || payload.ClimateTemperatureSetpoint == nil || *payload.ClimateTemperatureSetpoint != 392 {
+		t.Errorf("expected climate temperatures in °F, got %+v", payload)
+	}
+
+	err = bridge.executeCommand("apocli/0123456789abcdef/climate/temperature/set", []byte("356"))
+	if err != nil {
+		t.Errorf("expected temperature to be stored: %+v", err)
+	}
+	if temperature := bridge.ovens["0123456789abcdef"].climateTemperatureCelsius; temperature != 180 {
+		t.Errorf("expected stored temperature 180 °C, got %f", temperature)
+	}
+}
@@ -0,0 +1,175 @@
+package mqttbridge
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"fmt"
+)
+
+// See https://www.home-assistant.io/integrations/mqtt/#mqtt-discovery
+
+type discoveryDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+	SwVersion    string   `json:"sw_version,omitempty"`
+	HwVersion    string   `json:"hw_version,omitempty"`
+}
+
+type discoveryAvailability struct {
+	Topic string `json:"topic"`
+}
+
+// discoveryEntity holds the union of the fields used by every entity type.
+type discoveryEntity struct {
+	Name              string                  `json:"name"`
+	UniqueID          string                  `json:"unique_id"`
+	Device            discoveryDevice         `json:"device"`
+	Availability      []discoveryAvailability `json:"availability"`
+	AvailabilityMode  string                  `json:"availability_mode"`
+	StateTopic        string                  `json:"state_topic,omitempty"`
+	ValueTemplate     string                  `json:"value_template,omitempty"`
+	DeviceClass       string                  `json:"device_class,omitempty"`
+	StateClass        string                  `json:"state_class,omitempty"`
+	UnitOfMeasurement string                  `json:"unit_of_measurement,omitempty"`
+	PayloadOn         string                  `json:"payload_on,omitempty"`
+	PayloadOff        string                  `json:"payload_off,omitempty"`
+	CommandTopic      string                  `json:"command_topic,omitempty"`
+	PayloadPress      string                  `json:"payload_press,omitempty"`
+
+	// Climate only
+	Modes                      []string `json:"modes,omitempty"`
+	ModeStateTopic             string   `json:"mode_state_topic,omitempty"`
+	ModeStateTemplate          string   `json:"mode_state_template,omitempty"`
+	ModeCommandTopic           string   `json:"mode_command_topic,omitempty"`
+	TemperatureStateTopic      string   `json:"temperature_state_topic,omitempty"`
+	TemperatureStateTemplate   string   `json:"temperature_state_template,omitempty"`
+	TemperatureCommandTopic    string   `json:"temperature_command_topic,omitempty"`
+	CurrentTemperatureTopic    string   `json:"current_temperature_topic,omitempty"`
+	CurrentTemperatureTemplate string   `json:"current_temperature_template,omitempty"`
+	MinTemp                    float64  `json:"min_temp,omitempty"`
+	MaxTemp                    float64  `json:"max_temp,omitempty"`
+	TempStep                   float64  `json:"temp_step,omitempty"`
+	TemperatureUnit            string   `json:"temperature_unit,omitempty"`
+}
+
+func (bridge *Bridge) publishDiscovery(cookerID anova.CookerID, name string, state *dto.OvenStateV1) {
+	if bridge.options.DiscoveryPrefix == "" {
+		return
+	}
+
+	device := discoveryDevice{
+		Identifiers:  []string{"apocli_" + string(cookerID)},
+		Name:         name,
+		Manufacturer: "Anova",
+		Model:        "Precision Oven",
+	}
+	if state != nil {
+		device.SwVersion = state.SystemInfo.FirmwareVersion
+		device.HwVersion = state.SystemInfo.HardwareVersion
+	}
+
+	stateTopic := bridge.ovenTopic(cookerID, "state")
+	newEntity := func(objectID string, entityName string) discoveryEntity {
+		return discoveryEntity{
+			Name:     entityName,
+			UniqueID: fmt.Sprintf("apocli_%s_%s", cookerID, objectID),
+			Device:   device,
+			Availability: []discoveryAvailability{
+				{Topic: bridge.StatusTopic()},
+				{Topic: bridge.ovenTopic(cookerID, "availability")},
+			},
+			AvailabilityMode: "all",
+			StateTopic:       stateTopic,
+		}
+	}
+	temperatureSensor := func(objectID string, entityName string, field string) discoveryEntity {
+		entity := newEntity(objectID, entityName)
+		entity.ValueTemplate = fmt.Sprintf("{{ value_json.%s }}", field)
+		entity.DeviceClass = "temperature"
+		entity.StateClass = "measurement"
+		entity.UnitOfMeasurement = "°C"
+		return entity
+	}
+
+	entities := map[string]map[string]discoveryEntity{
+		"sensor": {
+			"dry_temperature": temperatureSensor("dry_temperature", "Dry bulb temperature", "dryTemperature"),
+			"wet_temperature": temperatureSensor("wet_temperature", "Wet bulb temperature", "wetTemperature"),
+		},
+		"binary_sensor": {},
+		"switch":        {},
+		"button":        {},
+		"climate":       {},
+	}
+
+	humidity := newEntity("relative_humidity", "Relative humidity")
+	humidity.ValueTemplate = "{{ value_json.relativeHumidity }}"
+	humidity.DeviceClass = "humidity"
+	humidity.StateClass = "measurement"
+	humidity.UnitOfMeasurement = "%"
+	entities["sensor"]["relative_humidity"] = humidity
+
+	timer := newEntity("timer_remaining", "Timer remaining")
+	timer.ValueTemplate = "{{ value_json.timerRemaining }}"
+	timer.DeviceClass = "duration"
+	timer.UnitOfMeasurement = "s"
+	entities["sensor"]["timer_remaining"] = timer
+
+	mode := newEntity("mode", "Mode")
+	mode.ValueTemplate = "{{ value_json.mode }}"
+	entities["sensor"]["mode"] = mode
+
+	door := newEntity("door", "Door")
+	door.ValueTemplate = "{{ value_json.door }}"
+	door.DeviceClass = "door"
+	door.PayloadOn = "open"
+	door.PayloadOff = "closed"
+	entities["binary_sensor"]["door"] = door
+
+	waterTank := newEntity("water_tank_empty", "Water tank empty")
+	waterTank.ValueTemplate = "{{ 'ON' if value_json.waterTankEmpty else 'OFF' }}"
+	waterTank.DeviceClass = "problem"
+	entities["binary_sensor"]["water_tank_empty"] = waterTank
+
+	lamp := newEntity("lamp", "Lamp")
+	lamp.ValueTemplate = "{{ value_json.lamp }}"
+	lamp.CommandTopic = bridge.ovenTopic(cookerID, "lamp/set")
+	lamp.PayloadOn = "ON"
+	lamp.PayloadOff = "OFF"
+	entities["switch"]["lamp"] = lamp
+
+	stopCook := newEntity("stop_cook", "Stop cook")
+	stopCook.StateTopic = ""
+	stopCook.CommandTopic = bridge.ovenTopic(cookerID, "cook/stop")
+	stopCook.PayloadPress = "STOP"
+	entities["button"]["stop_cook"] = stopCook
+
+	climate := newEntity("oven", "Oven")
+	climate.StateTopic = ""
+	climate.Modes = []string{"off", "heat"}
+	climate.ModeStateTopic = stateTopic
+	climate.ModeStateTemplate = "{{ 'heat' if value_json.mode == 'cook' else 'off' }}"
+	climate.ModeCommandTopic = bridge.ovenTopic(cookerID, "climate/mode/set")
+	climate.TemperatureStateTopic = stateTopic
+	climate.TemperatureStateTemplate = "{{ value_json.climateTemperatureSetpoint }}"
+	climate.TemperatureCommandTopic = bridge.ovenTopic(cookerID, "climate/temperature/set")
+	climate.CurrentTemperatureTopic = stateTopic
+	climate.CurrentTemperatureTemplate = "{{ value_json.climateTemperature }}"
+	// The range of the dry, rear element cooks the climate entity starts
+	unit := temperatureUnit(state)
+	minCelsius, maxCelsius := anova.SetpointRangeCelsius(anova.TemperatureModeDry, anova.RearElementOnly)
+	climate.MinTemp = inUnit(minCelsius, unit)
+	climate.MaxTemp = inUnit(maxCelsius, unit)
+	climate.TempStep = 1
+	climate.TemperatureUnit = string(unit)
+	entities["climate"]["oven"] = climate
+
+	for component, componentEntities := range entities {
+		for objectID, entity := range componentEntities {
+			topic := fmt.Sprintf("%s/%s/apocli_%s/%s/config", bridge.options.DiscoveryPrefix, component, cookerID, objectID)
+			bridge.publishJSON(topic, entity)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package mqttbridge
+
+import (
+	"errors"
+	"fmt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	qos            = 1
+	publishTimeout = 10 * time.Second
+)
+
+type DialOptions struct {
+	// Broker URL, e.g. "tcp://localhost:1883"
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+
+	// Topic and payload published by the broker if the connection drops
+	WillTopic   string
+	WillPayload string
+}
+
+// pahoConnection adapts a Paho client to Connection, restoring subscriptions
+// whenever the client reconnects.
+type pahoConnection struct {
+	client paho.Client
+
+	mutex         sync.Mutex
+	subscriptions map[string]paho.MessageHandler
+}
+
+// Dial connects to an MQTT broker.
+func Dial(options DialOptions) (Connection, error) {
+	conn := &pahoConnection{
+		subscriptions: make(map[string]paho.MessageHandler),
+	}
+
+	clientOptions := paho.NewClientOptions().
+		AddBroker(options.Broker).
+		SetClientID(options.ClientID).
+		SetUsername(options.Username).
+		SetPassword(options.Password).
+		SetAutoReconnect(true).
+		// Commands block until the oven acknowledges them, so don't let one
+		// slow command hold up the others
+		SetOrderMatters(false).
+		SetOnConnectHandler(conn.resubscribe)
+	if options.WillTopic != "" {
+		clientOptions.SetWill(options.WillTopic, options.WillPayload, qos, true)
+	}
+
+	conn.client = paho.NewClient(clientOptions)
+	token := conn.client.Connect()
+	if !token.WaitTimeout(publishTimeout) {
+		return nil, fmt.Errorf("timed out connecting to MQTT broker \"%s\"", options.Broker)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker \"%s\": %+v", options.Broker, err)
+	}
+
+	return conn, nil
+}
+
+func (conn *pahoConnection) Publish(topic string, payload []byte, retained bool) error {
+	token := conn.client.Publish(topic, qos, retained, payload)
+	if !token.WaitTimeout(publishTimeout) {
+		return errors.New("timed out publishing message")
+	}
+	return token.Error()
+}
+
+func (conn *pahoConnection) Subscribe(topic string, handler func(topic string, payload []byte)) error {
+	messageHandler := func(_ paho.Client, message paho.Message) {
+		// The bridge only subscribes to command topics. A retained command would
+		// be carried out again on every (re)connect, e.g. stopping every cook.
+		if message.Retained() {
+			slog.Warn("ignoring retained MQTT command",
+				slog.String("topic", message.Topic()),
+				slog.String("payload", string(message.Payload())))
+			return
+		}
+		handler(message.Topic(), message.Payload())
+	}
+
+	conn.mutex.Lock()
+	conn.subscriptions[topic] = messageHandler
+	conn.mutex.Unlock()
+
+	token := conn.client.Subscribe(topic, qos, messageHandler)
+	if !token.WaitTimeout(publishTimeout) {
+		return errors.New("timed out subscribing to topic")
+	}
+	return token.Error()
+}
+
+func (conn *pahoConnection) resubscribe(client paho.Client) {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+
+	for topic, handler := range conn.subscriptions {
+		token := client.Subscribe(topic, qos, handler)
+		if token.WaitTimeout(publishTimeout) && token.Error() != nil {
+			slog.Error("failed to resubscribe to MQTT topic",
+				slog.String("topic", topic),
+				slog.Any("err", token.Error()))
+		}
+	}
+}
+
+// Close disconnects from the broker, waiting briefly for in-flight messages.
+func Close(conn Connection) {
+	if conn, ok := conn.(*pahoConnection); ok {
+		conn.client.Disconnect(250)
+	}
+}
@@ -0,0 +1,497 @@
+package mqttbridge
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MQTT control packet types
+const (
+	packetConnect     = 1
+	packetConnAck     = 2
+	packetPublish     = 3
+	packetPubAck      = 4
+	packetSubscribe   = 8
+	packetSubAck      = 9
+	packetPingReq     = 12
+	packetPingResp    = 13
+	packetDisconnect  = 14
+	testBrokerEnvName = "APOCLI_TEST_MQTT_BROKER"
+)
+
+// testBroker is a minimal MQTT 3.1.1 broker: enough of the protocol for Paho,
+// with retained messages and wills, and subscribers sent everything at QoS 0.
+type testBroker struct {
+	listener net.Listener
+
+	mutex    sync.Mutex
+	retained map[string][]byte
+	clients  map[*testBrokerClient]bool
+}
+
+type testBrokerClient struct {
+	id      string
+	conn    net.Conn
+	writeMu sync.Mutex
+	filters []string
+
+	willTopic    string
+	willPayload  []byte
+	willRetained bool
+}
+
+type testBrokerPacket struct {
+	packetType byte
+	flags      byte
+	body       []byte
+}
+
+func newTestBroker(t *testing.T) *testBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %+v", err)
+	}
+	broker := &testBroker{
+		listener: listener,
+		retained: make(map[string][]byte),
+		clients:  make(map[*testBrokerClient]bool),
+	}
+	go broker.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		broker.disconnect("")
+	})
+	return broker
+}
+
+func (broker *testBroker) url() string {
+	return "tcp://" + broker.listener.Addr().String()
+}
+
+func (broker *testBroker) serve() {
+	for {
+		conn, err := broker.listener.Accept()
+		if err != nil {
+			return
+		}
+		go broker.handle(&testBrokerClient{conn: conn})
+	}
+}
+
+// disconnect drops the connections of clients with the ID, or of every client
+// if the ID is empty, as if the network failed.
+func (broker *testBroker) disconnect(clientID string) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	for client := range broker.clients {
+		if clientID == "" || client.id == clientID {
+			client.conn.Close()
+		}
+	}
+}
+
+func (broker *testBroker) handle(client *testBrokerClient) {
+	defer client.conn.Close()
+	reader := bufio.NewReader(client.conn)
+	cleanDisconnect := false
+	defer func() {
+		broker.mutex.Lock()
+		delete(broker.clients, client)
+		broker.mutex.Unlock()
+		if !cleanDisconnect && client.willTopic != "" {
+			broker.publish(client.willTopic, client.willPayload, client.willRetained)
+		}
+	}()
+
+	for {
+		packet, err := readTestBrokerPacket(reader)
+		if err != nil {
+			return
+		}
+		switch packet.packetType {
+		case packetConnect:
+			client.parseConnect(packet.body)
+			broker.mutex.Lock()
+			// A new connection with the same client ID takes over the session
+			for other := range broker.clients {
+				if other.id == client.id {
+					other.conn.Close()
+				}
+			}
+			broker.clients[client] = true
+			broker.mutex.Unlock()
+			client.write(packetConnAck, 0, []byte{0, 0})
+
+		case packetSubscribe:
+			packetID, rest := packet.body[:2], packet.body[2:]
+			var granted []byte
+			var filters []string
+			for len(rest) > 0 {
+				var filter string
+				filter, rest = readTestBrokerString(rest)
+				rest = rest[1:]
+				filters = append(filters, filter)
+				granted = append(granted, 0)
+			}
+			broker.mutex.Lock()
+			client.filters = append(client.filters, filters...)
+			var retained [][2]string
+			for topic, payload := range broker.retained {
+				for _, filter := range filters {
+					if topicMatches(filter, topic) {
+						retained = append(retained, [2]string{topic, string(payload)})
+						break
+					}
+				}
+			}
+			broker.mutex.Unlock()
+			client.write(packetSubAck, 0, append(packetID, granted...))
+			for _, message := range retained {
+				client.publish(message[0], []byte(message[1]), true)
+			}
+
+		case packetPublish:
+			qos := (packet.flags >> 1) & 3
+			topic, rest := readTestBrokerString(packet.body)
+			if qos > 0 {
+				client.write(packetPubAck, 0, rest[:2])
+				rest = rest[2:]
+			}
+			broker.publish(topic, rest, packet.flags&1 == 1)
+
+		case packetPingReq:
+			client.write(packetPingResp, 0, nil)
+
+		case packetDisconnect:
+			cleanDisconnect = true
+			return
+		}
+	}
+}
+
+// publish retains the message if asked to, and sends it to every subscriber.
+// Messages sent to existing subscriptions are never flagged as retained.
+func (broker *testBroker) publish(topic string, payload []byte, retain bool) {
+	broker.mutex.Lock()
+	if retain {
+		if len(payload) == 0 {
+			delete(broker.retained, topic)
+		} else {
+			broker.retained[topic] = append([]byte(nil), payload...)
+		}
+	}
+	var subscribers []*testBrokerClient
+	for client := range broker.clients {
+		for _, filter := range client.filters {
+			if topicMatches(filter, topic) {
+				subscribers = append(subscribers, client)
+				break
+			}
+		}
+	}
+	broker.mutex.Unlock()
+
+	for _, client := range subscribers {
+		client.publish(topic, payload, false)
+	}
+}
+
+func (client *testBrokerClient) parseConnect(body []byte) {
+	_, rest := readTestBrokerString(body)
+	flags := rest[1]
+	client.id, rest = readTestBrokerString(rest[4:])
+	if flags&0x04 != 0 {
+		var willPayload string
+		client.willTopic, rest = readTestBrokerString(rest)
+		willPayload, _ = readTestBrokerString(rest)
+		client.willPayload = []byte(willPayload)
+		client.willRetained = flags&0x20 != 0
+	}
+}
+
+func (client *testBrokerClient) publish(topic string, payload []byte, retained bool) {
+	var flags byte
+	if retained {
+		flags = 1
+	}
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+	body = append(append(body, topic...), payload...)
+	client.write(packetPublish, flags, body)
+}
+
+func (client *testBrokerClient) write(packetType byte, flags byte, body []byte) {
+	packet := []byte{packetType<<4 | flags}
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+
+	client.writeMu.Lock()
+	defer client.writeMu.Unlock()
+	client.conn.Write(append(packet, body...))
+}
+
+func readTestBrokerPacket(reader *bufio.Reader) (testBrokerPacket, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return testBrokerPacket{}, err
+	}
+	length, multiplier := 0, 1
+	for {
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return testBrokerPacket{}, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return testBrokerPacket{}, err
+	}
+	return testBrokerPacket{packetType: header >> 4, flags: header & 0x0f, body: body}, nil
+}
+
+func readTestBrokerString(data []byte) (string, []byte) {
+	length := int(binary.BigEndian.Uint16(data))
+	return string(data[2 : 2+length]), data[2+length:]
+}
+
+// topicMatches matches a topic against a filter with + and # wildcards.
+func topicMatches(filter string, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		switch {
+		case level == "#":
+			return true
+		case i >= len(topicLevels):
+			return false
+		case level != "+" && level != topicLevels[i]:
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
+
+// brokerURLs returns the embedded broker, and the broker in
+// APOCLI_TEST_MQTT_BROKER, e.g. a local Mosquitto, if set.
+func brokerURLs(t *testing.T) map[string]string {
+	urls := map[string]string{"embedded": newTestBroker(t).url()}
+	if url := os.Getenv(testBrokerEnvName); url != "" {
+		urls["external"] = url
+	}
+	return urls
+}
+
+// observer is a plain Paho client recording the last message on every topic,
+// including retained ones.
+type observer struct {
+	client   paho.Client
+	mutex    sync.Mutex
+	messages map[string]paho.Message
+}
+
+func observe(t *testing.T, brokerURL string, filters ...string) *observer {
+	t.Helper()
+	observer := &observer{messages: make(map[string]paho.Message)}
+	observer.client = paho.NewClient(paho.NewClientOptions().
+		AddBroker(brokerURL).
+		SetClientID(fmt.Sprintf("apocli-test-observer-%d", time.Now().UnixNano())))
+	if token := observer.client.Connect(); !token.WaitTimeout(publishTimeout) || token.Error() != nil {
+		t.Fatalf("observer failed to connect: %+v", token.Error())
+	}
+	t.Cleanup(func() { observer.client.Disconnect(100) })
+
+	subscriptions := make(map[string]byte)
+	for _, filter := range filters {
+		subscriptions[filter] = qos
+	}
+	token := observer.client.SubscribeMultiple(subscriptions, func(_ paho.Client, message paho.Message) {
+		observer.mutex.Lock()
+		observer.messages[message.Topic()] = message
+		observer.mutex.Unlock()
+	})
+	if !token.WaitTimeout(publishTimeout) || token.Error() != nil {
+		t.Fatalf("observer failed to subscribe: %+v", token.Error())
+	}
+	return observer
+}
+
+// waitFor waits for a message on the topic with a matching payload.
+func (observer *observer) waitFor(t *testing.T, topic string, matches func(payload string) bool) paho.Message {
+	t.Helper()
+	var message paho.Message
+	if !eventually(func() bool {
+		observer.mutex.Lock()
+		defer observer.mutex.Unlock()
+		message = observer.messages[topic]
+		return message != nil && matches(string(message.Payload()))
+	}) {
+		t.Fatalf("no matching message on %s", topic)
+	}
+	return message
+}
+
+func (observer *observer) publish(t *testing.T, topic string, payload string, retained bool) {
+	t.Helper()
+	token := observer.client.Publish(topic, qos, retained, payload)
+	if !token.WaitTimeout(publishTimeout) || token.Error() != nil {
+		t.Fatalf("failed to publish: %+v", token.Error())
+	}
+}
+
+// clearRetained removes every message the observer has seen from the broker.
+func (observer *observer) clearRetained(t *testing.T) {
+	observer.mutex.Lock()
+	topics := make([]string, 0, len(observer.messages))
+	for topic := range observer.messages {
+		topics = append(topics, topic)
+	}
+	observer.mutex.Unlock()
+	for _, topic := range topics {
+		observer.publish(t, topic, "", true)
+	}
+}
+
+func eventually(condition func() bool) bool {
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if condition() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPahoConnection(t *testing.T) {
+	if testing.Short() {
+		t.Skip("connects to an MQTT broker")
+	}
+	if os.Getenv(testBrokerEnvName) == "" {
+		t.Logf("set %s, e.g. to tcp://localhost:1883, to also test against a real broker", testBrokerEnvName)
+	}
+	for name, brokerURL := range brokerURLs(t) {
+		t.Run(name, func(t *testing.T) {
+			testPahoConnection(t, brokerURL)
+		})
+	}
+}
+
+func testPahoConnection(t *testing.T, brokerURL string) {
+	// Unique topics, so that runs against a real broker don't interfere
+	prefix := fmt.Sprintf("apocli-test-%d", time.Now().UnixNano())
+	options := Options{TopicPrefix: prefix, DiscoveryPrefix: prefix + "-discovery"}
+	clientID := prefix + "-bridge"
+	commandTopic := prefix + "/0123456789abcdef/climate/temperature/set"
+
+	observer := observe(t, brokerURL, prefix+"/#", prefix+"-discovery/#")
+	t.Cleanup(func() { observer.clearRetained(t) })
+	// A retained command left behind, e.g. by a misconfigured automation
+	observer.publish(t, commandTopic, "150", true)
+	observer.waitFor(t, commandTopic, func(payload string) bool { return payload == "150" })
+
+	conn, err := Dial(DialOptions{Broker: brokerURL, ClientID: clientID, WillTopic: options.StatusTopic(), WillPayload: "offline"})
+	if err != nil {
+		t.Fatalf("failed to dial: %+v", err)
+	}
+	defer Close(conn)
+	client := conn.(*pahoConnection).client
+	bridge := NewBridge(conn, options)
+	bridge.HandleEvent(anova.OvenAdded{Oven: &anova.Oven{CookerID: "0123456789abcdef", Name: "Kitchen"}, State: &dto.OvenStateV1{}})
+
+	// Discovery is retained, so it reaches subscribers that come along later
+	discoveryTopic := prefix + "-discovery/climate/apocli_0123456789abcdef/oven/config"
+	discovery := observe(t, brokerURL, discoveryTopic).waitFor(t, discoveryTopic,
+		func(payload string) bool { return strings.Contains(payload, `"name":"Kitchen"`) })
+	if !discovery.Retained() {
+		t.Errorf("expected discovery config to be retained")
+	}
+
+	climateTemperature := func() float64 {
+		bridge.mutex.Lock()
+		defer bridge.mutex.Unlock()
+		return bridge.ovens["0123456789abcdef"].climateTemperatureCelsius
+	}
+	// Commands published while the bridge is reconnecting are lost, so keep
+	// publishing until the bridge has carried it out
+	setTemperature := func(payload string, expected float64) {
+		t.Helper()
+		if !eventually(func() bool {
+			observer.publish(t, commandTopic, payload, false)
+			time.Sleep(100 * time.Millisecond)
+			return climateTemperature() == expected
+		}) {
+			t.Fatalf("expected the bridge to set the temperature to %g, got %g", expected, climateTemperature())
+		}
+	}
+	// The retained command was sent on subscribing, and ignored
+	time.Sleep(200 * time.Millisecond)
+	if temperature := climateTemperature(); temperature != defaultClimateTemperatureCelsius {
+		t.Fatalf("expected retained command to be ignored, got %g", temperature)
+	}
+	setTemperature("200", 200)
+
+	// A client connecting with the same ID makes the broker drop the bridge's
+	// connection before accepting the new one. Paho restores it, resubscribing
+	// to the command topics.
+	intruder := paho.NewClient(paho.NewClientOptions().AddBroker(brokerURL).SetClientID(clientID).SetAutoReconnect(false))
+	if token := intruder.Connect(); !token.WaitTimeout(publishTimeout) || token.Error() != nil {
+		t.Fatalf("intruder failed to connect: %+v", token.Error())
+	}
+	intruder.Disconnect(100)
+	setTemperature("210", 210)
+
+	// The retained command was sent again on resubscribing, and ignored again
+	time.Sleep(200 * time.Millisecond)
+	if temperature := climateTemperature(); temperature != 210 {
+		t.Fatalf("expected retained command to be ignored after reconnecting, got %g", temperature)
+	}
+
+	// Closing disconnects cleanly, after the bridge has published its status
+	bridge.HandleEvent(anova.ServiceStopped{})
+	observer.waitFor(t, options.StatusTopic(), func(payload string) bool { return payload == "offline" })
+	Close(conn)
+	if client.IsConnected() {
+		t.Errorf("expected the connection to be closed")
+	}
+}
+
+func TestPahoConnection_Will(t *testing.T) {
+	broker := newTestBroker(t)
+	conn, err := Dial(DialOptions{Broker: broker.url(), ClientID: "apocli-test-will", WillTopic: "apocli/status", WillPayload: "offline"})
+	if err != nil {
+		t.Fatalf("failed to dial: %+v", err)
+	}
+	defer Close(conn)
+	if err := conn.Publish("apocli/status", []byte("online"), true); err != nil {
+		t.Fatalf("failed to publish: %+v", err)
+	}
+	observer := observe(t, broker.url(), "apocli/status")
+	observer.waitFor(t, "apocli/status", func(payload string) bool { return payload == "online" })
+
+	// The broker publishes the will when the connection drops unexpectedly
+	broker.disconnect("apocli-test-will")
+	observer.waitFor(t, "apocli/status", func(payload string) bool { return payload == "offline" })
+}
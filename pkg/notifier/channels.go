@@ -0,0 +1,274 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const sendTimeout = 10 * time.Second
+
+// urgent triggers need attention rather than just being informative, and are
+// sent with a higher priority where channels support it.
+func urgent(trigger Trigger) bool {
+	return trigger == TriggerWaterTankEmpty || trigger == TriggerDoorOpen || trigger == TriggerOffline ||
+		trigger == TriggerWatchdog
+}
+
+// WebhookChannel sends a request to any HTTP endpoint.
+type WebhookChannel struct {
+	url     string
+	method  string
+	headers map[string]string
+	body    *template.Template
+	client  *http.Client
+}
+
+// NewWebhookChannel sends requests to url. The body is rendered from a
+// text/template with the Notification as its data, and a "json" function to
+// encode values as JSON, e.g. `{"text": {{json .Message}}}`. Without a template,
+// the Notification itself is sent as JSON. method defaults to POST.
+func NewWebhookChannel(url string, method string, headers map[string]string, bodyTemplate string) (*WebhookChannel, error) {
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	var body *template.Template
+	if bodyTemplate != "" {
+		var err error
+		body, err = template.New("body").Funcs(template.FuncMap{
+			"json": func(value interface{}) (string, error) {
+				encoded, err := json.Marshal(value)
+				return string(encoded), err
+			},
+		}).Parse(bodyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook body template: %+v", err)
+		}
+	}
+
+	return &WebhookChannel{
+		url:     url,
+		method:  method,
+		headers: headers,
+		body:    body,
+		client:  &http.Client{Timeout: sendTimeout},
+	}, nil
+}
+
+func (channel *WebhookChannel) Send(notification Notification) error {
+	var body bytes.Buffer
+	contentType := "text/plain; charset=utf-8"
+	if channel.body == nil {
+		contentType = "application/json"
+		if err := json.NewEncoder(&body).Encode(notification); err != nil {
+			return err
+		}
+	} else if err := channel.body.Execute(&body, notification); err != nil {
+		return fmt.Errorf("failed to render webhook body: %+v", err)
+	}
+
+	request, err := http.NewRequest(channel.method, channel.url, &body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	for name, value := range channel.headers {
+		request.Header.Set(name, value)
+	}
+	return doRequest(channel.client, request, "webhook")
+}
+
+// NtfyChannel publishes to an ntfy topic.
+// See https://docs.ntfy.sh/publish/
+type NtfyChannel struct {
+	topicURL string
+	token    string
+	client   *http.Client
+}
+
+// NewNtfyChannel publishes to topicURL, e.g. "https://ntfy.sh/my-oven". The
+// token is optional, for protected topics.
+func NewNtfyChannel(topicURL string, token string) *NtfyChannel {
+	return &NtfyChannel{
+		topicURL: topicURL,
+		token:    token,
+		client:   &http.Client{Timeout: sendTimeout},
+	}
+}
+
+func (channel *NtfyChannel) Send(notification Notification) error {
+	request, err := http.NewRequest(http.MethodPost, channel.topicURL, strings.NewReader(notification.Message))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Title", notification.Title)
+	request.Header.Set("Tags", string(notification.Trigger))
+	if urgent(notification.Trigger) {
+		request.Header.Set("Priority", "high")
+	}
+	if channel.token != "" {
+		request.Header.Set("Authorization", "Bearer "+channel.token)
+	}
+	return doRequest(channel.client, request, "ntfy")
+}
+
+// GotifyChannel sends messages to a Gotify server.
+// See https://gotify.net/docs/pushmsg
+type GotifyChannel struct {
+	serverURL string
+	token     string
+	client    *http.Client
+}
+
+// NewGotifyChannel sends to the server at serverURL, e.g.
+// "https://gotify.example.com", with an application token.
+func NewGotifyChannel(serverURL string, token string) *GotifyChannel {
+	return &GotifyChannel{
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		token:     token,
+		client:    &http.Client{Timeout: sendTimeout},
+	}
+}
+
+func (channel *GotifyChannel) Send(notification Notification) error {
+	priority := 5
+	if urgent(notification.Trigger) {
+		priority = 8
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"title":    notification.Title,
+		"message":  notification.Message,
+		"priority": priority,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, channel.serverURL+"/message", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Gotify-Key", channel.token)
+	return doRequest(channel.client, request, "Gotify")
+}
+
+func doRequest(client *http.Client, request *http.Request, service string) error {
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send %s notification: %+v", service, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("%s notification failed with status %d: %s", service, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// EmailChannel sends plain text emails over SMTP.
+type EmailChannel struct {
+	address  string
+	auth     smtp.Auth
+	from     string
+	to       []string
+	sendMail func(address string, auth smtp.Auth, from string, to []string, message []byte) error
+}
+
+// NewEmailChannel sends through the SMTP server at address, e.g.
+// "smtp.example.com:587", using STARTTLS if the server supports it. Without a
+// username, mail is sent unauthenticated.
+func NewEmailChannel(address string, username string, password string, from string, to []string) *EmailChannel {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := strings.Cut(address, ":")
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailChannel{
+		address:  address,
+		auth:     auth,
+		from:     from,
+		to:       to,
+		sendMail: smtp.SendMail,
+	}
+}
+
+func (channel *EmailChannel) Send(notification Notification) error {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", channel.from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(channel.to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Title))
+	fmt.Fprintf(&message, "Date: %s\r\n", notification.Time.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(notification.Message, "\n", "\r\n"))
+	message.WriteString("\r\n")
+
+	err := channel.sendMail(channel.address, channel.auth, channel.from, channel.to, message.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to send email: %+v", err)
+	}
+	return nil
+}
+
+// DesktopChannel shows desktop notifications on Linux through the
+// org.freedesktop.Notifications D-Bus service, using gdbus from GLib.
+type DesktopChannel struct {
+	// Seconds to show notifications for, or 0 for the desktop's default
+	ExpireSeconds int
+}
+
+func (channel *DesktopChannel) Send(notification Notification) error {
+	output, err := exec.Command("gdbus", channel.notifyArgs(notification)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to show desktop notification: %+v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// notifyArgs calls Notify(app_name, replaces_id, app_icon, summary, body,
+// actions, hints, expire_timeout), with every argument in GVariant text format.
+// See https://specifications.freedesktop.org/notification-spec/latest/protocol.html
+func (channel *DesktopChannel) notifyArgs(notification Notification) []string {
+	// 0 = low, 1 = normal, 2 = critical
+	urgency := 1
+	if urgent(notification.Trigger) {
+		urgency = 2
+	}
+	expireTimeout := -1
+	if channel.ExpireSeconds > 0 {
+		expireTimeout = channel.ExpireSeconds * 1000
+	}
+
+	return []string{
+		"call", "--session",
+		"--dest", "org.freedesktop.Notifications",
+		"--object-path", "/org/freedesktop/Notifications",
+		"--method", "org.freedesktop.Notifications.Notify",
+		gvariantString("apocli"),
+		"uint32 0",
+		gvariantString(""),
+		gvariantString(notification.Title),
+		gvariantString(notification.Message),
+		"@as []",
+		fmt.Sprintf("{'urgency': <byte %d>}", urgency),
+		"int32 " + strconv.Itoa(expireTimeout),
+	}
+}
+
+// gvariantString quotes a string in GVariant text format.
+func gvariantString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
@@ -0,0 +1,144 @@
+package notifier
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"strings"
+	"time"
+)
+
+// Config describes the channels to send notifications through, as loaded from
+// a TOML file. Every channel can be given any number of times, and sends every
+// trigger unless it lists its own.
+type Config struct {
+	// Defaults to DefaultDoorOpenAfter
+	DoorOpenAfter time.Duration `toml:"door_open_after"`
+
+	Webhooks []WebhookConfig `toml:"webhook"`
+	Ntfy     []NtfyConfig    `toml:"ntfy"`
+	Gotify   []GotifyConfig  `toml:"gotify"`
+	Email    []EmailConfig   `toml:"email"`
+	Desktop  []DesktopConfig `toml:"desktop"`
+}
+
+type WebhookConfig struct {
+	URL     string            `toml:"url"`
+	Method  string            `toml:"method"`
+	Headers map[string]string `toml:"headers"`
+	// text/template for the body; see NewWebhookChannel
+	Body     string    `toml:"body"`
+	Triggers []Trigger `toml:"triggers"`
+}
+
+type NtfyConfig struct {
+	URL      string    `toml:"url"`
+	Token    string    `toml:"token"`
+	Triggers []Trigger `toml:"triggers"`
+}
+
+type GotifyConfig struct {
+	URL      string    `toml:"url"`
+	Token    string    `toml:"token"`
+	Triggers []Trigger `toml:"triggers"`
+}
+
+type EmailConfig struct {
+	// host:port of the SMTP server
+	Server   string    `toml:"server"`
+	Username string    `toml:"username"`
+	Password string    `toml:"password"`
+	From     string    `toml:"from"`
+	To       []string  `toml:"to"`
+	Triggers []Trigger `toml:"triggers"`
+}
+
+type DesktopConfig struct {
+	ExpireSeconds int       `toml:"expire_seconds"`
+	Triggers      []Trigger `toml:"triggers"`
+}
+
+// LoadConfig reads a config file, rejecting unknown keys so that typos don't
+// silently disable notifications.
+func LoadConfig(path string) (*Config, error) {
+	var config Config
+	metadata, err := toml.DecodeFile(path, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifications config \"%s\": %+v", path, err)
+	}
+	if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, key := range undecoded {
+			keys = append(keys, key.String())
+		}
+		return nil, fmt.Errorf("unknown keys in notifications config \"%s\": %s", path, strings.Join(keys, ", "))
+	}
+	return &config, nil
+}
+
+// Routes creates the configured channels.
+func (config *Config) Routes() ([]Route, error) {
+	var routes []Route
+	add := func(name string, channel Channel, triggers []Trigger) error {
+		for _, trigger := range triggers {
+			if err := trigger.Validate(); err != nil {
+				return fmt.Errorf("%s: %+v", name, err)
+			}
+		}
+		routes = append(routes, Route{Name: name, Channel: channel, Triggers: triggers})
+		return nil
+	}
+
+	for i, webhook := range config.Webhooks {
+		name := fmt.Sprintf("webhook %d", i+1)
+		if webhook.URL == "" {
+			return nil, fmt.Errorf("%s: url is required", name)
+		}
+		channel, err := NewWebhookChannel(webhook.URL, webhook.Method, webhook.Headers, webhook.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %+v", name, err)
+		}
+		if err = add(name, channel, webhook.Triggers); err != nil {
+			return nil, err
+		}
+	}
+	for i, ntfy := range config.Ntfy {
+		name := fmt.Sprintf("ntfy %d", i+1)
+		if ntfy.URL == "" {
+			return nil, fmt.Errorf("%s: url is required", name)
+		}
+		if err := add(name, NewNtfyChannel(ntfy.URL, ntfy.Token), ntfy.Triggers); err != nil {
+			return nil, err
+		}
+	}
+	for i, gotify := range config.Gotify {
+		name := fmt.Sprintf("gotify %d", i+1)
+		if gotify.URL == "" || gotify.Token == "" {
+			return nil, fmt.Errorf("%s: url and token are required", name)
+		}
+		if err := add(name, NewGotifyChannel(gotify.URL, gotify.Token), gotify.Triggers); err != nil {
+			return nil, err
+		}
+	}
+	for i, email := range config.Email {
+		name := fmt.Sprintf("email %d", i+1)
+		if email.Server == "" || email.From == "" || len(email.To) == 0 {
+			return nil, fmt.Errorf("%s: server, from and to are required", name)
+		}
+		channel := NewEmailChannel(email.Server, email.Username, email.Password, email.From, email.To)
+		if err := add(name, channel, email.Triggers); err != nil {
+			return nil, err
+		}
+	}
+	for i, desktop := range config.Desktop {
+		name := fmt.Sprintf("desktop %d", i+1)
+		channel := &DesktopChannel{ExpireSeconds: desktop.ExpireSeconds}
+		if err := add(name, channel, desktop.Triggers); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no notification channels are configured")
+	}
+	return routes, nil
+}
@@ -0,0 +1,386 @@
+// Package notifier sends notifications about oven events, e.g. the oven having
+// preheated or a timer having finished, through webhooks, push services, email
+// and desktop notifications.
+package notifier
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Trigger string
+
+const (
+	// The oven finished preheating and moved on to the cooking stage
+	TriggerPreheated Trigger = "preheated"
+	// The cook moved on to another stage
+	TriggerStageChanged Trigger = "stage_changed"
+	// The active stage's timer ran out
+	TriggerTimerFinished Trigger = "timer_finished"
+	// The probe reached its target temperature
+	TriggerProbeReached Trigger = "probe_reached"
+	// The water tank ran empty
+	TriggerWaterTankEmpty Trigger = "water_tank_empty"
+	// The door was left open during a cook for longer than DoorOpenAfter
+	TriggerDoorOpen Trigger = "door_open"
+	// A cook ended, whether it completed or was stopped
+	TriggerCookEnded Trigger = "cook_ended"
+	// The oven lost its connection to the backend
+	TriggerOffline Trigger = "offline"
+	// The watchdog stopped a cook, or failed to
+	TriggerWatchdog Trigger = "watchdog"
+
+	// Sent to every channel, whichever triggers it is limited to, to check that
+	// the channels work
+	TriggerTest Trigger = "test"
+)
+
+// AllTriggers lists every trigger, in the order they are documented.
+var AllTriggers = []Trigger{
+	TriggerPreheated,
+	TriggerStageChanged,
+	TriggerTimerFinished,
+	TriggerProbeReached,
+	TriggerWaterTankEmpty,
+	TriggerDoorOpen,
+	TriggerCookEnded,
+	TriggerOffline,
+	TriggerWatchdog,
+}
+
+func (trigger Trigger) Validate() error {
+	for _, known := range AllTriggers {
+		if trigger == known {
+			return nil
+		}
+	}
+	return ErrInvalidTrigger{Trigger: trigger}
+}
+
+// DefaultDoorOpenAfter is how long the door may stay open during a cook before
+// TriggerDoorOpen is sent.
+const DefaultDoorOpenAfter = 2 * time.Minute
+
+// timerFinishedSlackSeconds is how close to running out a timer must have been
+// for the end of its stage to count as the timer finishing. Ovens report their
+// state every few seconds while cooking.
+const timerFinishedSlackSeconds = 30
+
+// Notification is passed to every channel, and to webhook body templates.
+type Notification struct {
+	Trigger  Trigger        `json:"trigger"`
+	CookerID anova.CookerID `json:"cookerId"`
+	OvenName string         `json:"ovenName"`
+	Time     time.Time      `json:"time"`
+
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+// Channel delivers notifications, e.g. to a push service.
+type Channel interface {
+	Send(notification Notification) error
+}
+
+// Route sends the given triggers to a channel. All triggers are sent if none
+// are listed.
+type Route struct {
+	Name     string
+	Channel  Channel
+	Triggers []Trigger
+}
+
+func (route Route) accepts(trigger Trigger) bool {
+	if len(route.Triggers) == 0 || trigger == TriggerTest {
+		return true
+	}
+	for _, accepted := range route.Triggers {
+		if accepted == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier watches the states of every oven for triggers, and sends a
+// notification for each through the routes that accept it. Notifications are
+// sent from a separate goroutine, so slow channels don't hold up other event
+// handlers.
+type Notifier struct {
+	routes        []Route
+	doorOpenAfter time.Duration
+
+	// Only accessed from HandleEvent
+	ovens map[anova.CookerID]*ovenEntry
+	now   func() time.Time
+
+	queue     chan Notification
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type ovenEntry struct {
+	name      string
+	lastState *dto.OvenStateV1
+	// When the door was seen opening during a cook, if it is still open
+	doorOpenedAt *time.Time
+	doorNotified bool
+}
+
+// New starts a notifier. doorOpenAfter defaults to DefaultDoorOpenAfter if 0.
+func New(routes []Route, doorOpenAfter time.Duration) *Notifier {
+	if doorOpenAfter == 0 {
+		doorOpenAfter = DefaultDoorOpenAfter
+	}
+
+	notifier := &Notifier{
+		routes:        routes,
+		doorOpenAfter: doorOpenAfter,
+
+		ovens: make(map[anova.CookerID]*ovenEntry),
+		now:   time.Now,
+
+		queue: make(chan Notification, 100),
+		done:  make(chan struct{}),
+	}
+	go notifier.sendNotifications()
+	return notifier
+}
+
+// Close sends any queued notifications and stops the notifier.
+func (notifier *Notifier) Close() {
+	notifier.closeOnce.Do(func() {
+		close(notifier.queue)
+	})
+	<-notifier.done
+}
+
+// Notify queues a notification for every route that accepts its trigger.
+func (notifier *Notifier) Notify(notification Notification) {
+	if notification.Time.IsZero() {
+		notification.Time = notifier.now()
+	}
+	select {
+	case notifier.queue <- notification:
+	default:
+		slog.Warn("dropped notification; too many are queued",
+			slog.String("trigger", string(notification.Trigger)))
+	}
+}
+
+func (notifier *Notifier) sendNotifications() {
+	defer close(notifier.done)
+	for notification := range notifier.queue {
+		for _, route := range notifier.routes {
+			if !route.accepts(notification.Trigger) {
+				continue
+			}
+			err := route.Channel.Send(notification)
+			if err != nil {
+				slog.Error("failed to send notification",
+					slog.String("channel", route.Name),
+					slog.String("trigger", string(notification.Trigger)),
+					slog.Any("err", err))
+			}
+		}
+	}
+}
+
+func (notifier *Notifier) HandleEvent(event anova.Event) {
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		notifier.ovens[event.Oven.CookerID] = &ovenEntry{
+			name:      event.Oven.Name,
+			lastState: event.State,
+		}
+	case anova.OvenRenamed:
+		if entry, exists := notifier.ovens[event.Oven.CookerID]; exists {
+			entry.name = event.Oven.Name
+		}
+	case anova.OvenUpdated:
+		entry, exists := notifier.ovens[event.Oven.CookerID]
+		if !exists {
+			entry = &ovenEntry{name: event.Oven.Name}
+			notifier.ovens[event.Oven.CookerID] = entry
+		}
+		if event.State == nil {
+			return
+		}
+		previous := entry.lastState
+		if previous == nil {
+			previous = event.PreviousState
+		}
+		entry.lastState = event.State
+		if previous == nil {
+			return
+		}
+
+		for _, notification := range notifier.detect(entry, previous, event.State, event.Oven.Progress()) {
+			notification.CookerID = event.Oven.CookerID
+			notification.OvenName = entry.name
+			notifier.Notify(notification)
+		}
+	}
+}
+
+// detect compares consecutive states of an oven for triggers. The oven's
+// progress, if known, adds an estimate of when the cook will be done.
+func (notifier *Notifier) detect(entry *ovenEntry, previous *dto.OvenStateV1, state *dto.OvenStateV1, progress *anova.CookProgress) []Notification {
+	var notifications []Notification
+	notify := func(trigger Trigger, title string, message string) {
+		notifications = append(notifications, Notification{
+			Trigger: trigger,
+			Title:   fmt.Sprintf("%s: %s", entry.name, title),
+			Message: message,
+			Time:    state.UpdatedTimestamp,
+		})
+	}
+
+	if previous.SystemInfo.Online && !state.SystemInfo.Online {
+		notify(TriggerOffline, "Offline", "The oven lost its connection.")
+	}
+
+	wasCooking := previous.Cook != nil && previous.State.Mode == dto.StateModeCook
+	cooking := state.Cook != nil && state.State.Mode == dto.StateModeCook
+	sameCook := wasCooking && cooking && previous.Cook.CookID == state.Cook.CookID
+
+	if wasCooking && !cooking {
+		notify(TriggerCookEnded, "Cook ended", "The cook has ended.")
+	}
+
+	// A long cook continuing its timer in the next stage is still in the same
+	// stage
+	stageChanged := sameCook && previous.Cook.ActiveStageIndex != state.Cook.ActiveStageIndex &&
+		!continuesTimer(state.Cook, previous.Cook.ActiveStageIndex, state.Cook.ActiveStageIndex)
+	if stageChanged {
+		cook := state.Cook
+		if stage := activeStage(previous.Cook); stage != nil && stage.Type == dto.StageTypePreheat {
+			notify(TriggerPreheated, "Preheated", fmt.Sprintf("The oven has reached %s.", formatSetpoint(stage, state.State.TemperatureUnit)))
+		}
+		stageIndex, stages := logicalStage(cook)
+		message := fmt.Sprintf("Stage %d of %d has started.", stageIndex+1, stages)
+		if progress != nil && progress.CookID == cook.CookID && progress.ActiveStageIndex == stageIndex &&
+			progress.Remaining != nil {
+			message += fmt.Sprintf(" The cook should be done in about %s.", formatDuration(*progress.Remaining))
+		}
+		notify(TriggerStageChanged, "Stage changed", message)
+	}
+
+	stageEnded := (wasCooking && !cooking) || stageChanged
+	// Only the last timer of a chain finishes the stage
+	continued := cooking && continuesTimer(state.Cook, state.Cook.ActiveStageIndex, state.Cook.ActiveStageIndex+1)
+	if !timerFinished(previous.Nodes.Timer) &&
+		((sameCook && !stageEnded && !continued && timerFinished(state.Nodes.Timer)) || (stageEnded && timerAlmostFinished(previous.Nodes.Timer))) {
+		notify(TriggerTimerFinished, "Timer finished", "The timer has finished.")
+	}
+
+	if probeReached(state) && !probeReached(previous) {
+		notify(TriggerProbeReached, "Probe target reached",
+			fmt.Sprintf("The probe has reached %s.", formatTemperature(state.Nodes.TemperatureProbe.Current.Celsius, state.State.TemperatureUnit)))
+	}
+
+	if !previous.Nodes.WaterTank.Empty && state.Nodes.WaterTank.Empty {
+		notify(TriggerWaterTankEmpty, "Water tank empty", "The water tank needs refilling.")
+	}
+
+	if !cooking || state.Nodes.Door.Closed {
+		entry.doorOpenedAt = nil
+		entry.doorNotified = false
+	} else {
+		now := notifier.now()
+		if entry.doorOpenedAt == nil {
+			entry.doorOpenedAt = &now
+		}
+		if !entry.doorNotified && now.Sub(*entry.doorOpenedAt) >= notifier.doorOpenAfter {
+			entry.doorNotified = true
+			notify(TriggerDoorOpen, "Door open",
+				fmt.Sprintf("The door has been open for %s.", now.Sub(*entry.doorOpenedAt).Round(time.Second)))
+		}
+	}
+
+	return notifications
+}
+
+func activeStage(cook *dto.CookV1) *dto.Stage {
+	if cook.ActiveStageIndex < 0 || cook.ActiveStageIndex >= len(cook.Stages) {
+		return nil
+	}
+	return &cook.Stages[cook.ActiveStageIndex]
+}
+
+// continuesTimer checks whether the stage at index to is the next stage of the
+// cook, continuing the timer of the stage at index from.
+func continuesTimer(cook *dto.CookV1, from int, to int) bool {
+	return from >= 0 && to == from+1 && to < len(cook.Stages) && anova.ContinuesTimer(cook.Stages[from], cook.Stages[to])
+}
+
+// logicalStage returns the index of the active stage and the number of stages
+// of the cook, counting the stages continuing a long timer as part of the
+// stage they continue, as anova.CookProgress does.
+func logicalStage(cook *dto.CookV1) (int, int) {
+	index, stages := 0, 0
+	for i := range cook.Stages {
+		if !continuesTimer(cook, i-1, i) {
+			stages++
+		}
+		if i == cook.ActiveStageIndex {
+			index = stages - 1
+		}
+	}
+	return index, stages
+}
+
+// timerFinished checks whether the timer has counted up to its initial value.
+func timerFinished(timer dto.TimerNodeV1) bool {
+	return timer.Initial > 0 && timer.Current >= timer.Initial
+}
+
+// timerAlmostFinished checks whether a stage that ended did so because its
+// timer ran out. The oven moves on as soon as it does, so the last state seen
+// may still show a few seconds left.
+func timerAlmostFinished(timer dto.TimerNodeV1) bool {
+	return timer.Initial > 0 && timer.Mode == string(dto.TimerModeRunning) && timer.Initial-timer.Current <= timerFinishedSlackSeconds
+}
+
+func probeReached(state *dto.OvenStateV1) bool {
+	probe := state.Nodes.TemperatureProbe
+	return probe.Connected && probe.Current != nil && probe.Setpoint != nil &&
+		probe.Current.Celsius >= probe.Setpoint.Celsius
+}
+
+// formatSetpoint formats the stage's setpoint in the unit the oven is set to.
+func formatSetpoint(stage *dto.Stage, unit dto.TemperatureUnit) string {
+	bulbs := stage.TemperatureBulbs
+	switch {
+	case bulbs == nil:
+		return "its setpoint"
+	case bulbs.Dry != nil:
+		return formatTemperature(bulbs.Dry.Setpoint.Celsius, unit)
+	case bulbs.Wet != nil:
+		return formatTemperature(bulbs.Wet.Setpoint.Celsius, unit) + " (wet bulb)"
+	}
+	return "its setpoint"
+}
+
+// formatDuration formats a duration to the minute, e.g. "1h30m".
+func formatDuration(duration time.Duration) string {
+	duration = max(duration.Round(time.Minute), time.Minute)
+	return strings.TrimSuffix(duration.String(), "0s")
+}
+
+func formatTemperature(celsius float64, unit dto.TemperatureUnit) string {
+	return anova.NewTemperatureCelsius(celsius).Format(unit)
+}
+
+type ErrInvalidTrigger struct {
+	Trigger Trigger
+}
+
+func (err ErrInvalidTrigger) Error() string {
+	return fmt.Sprintf("invalid trigger \"%s\"", err.Trigger)
+}
@@ -0,0 +1,406 @@
+package notifier
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeChannel struct {
+	sent []Notification
+}
+
+func (channel *fakeChannel) Send(notification Notification) error {
+	channel.sent = append(channel.sent, notification)
+	return nil
+}
+
+func newCookingState(stageIndex int, stageTypes ...dto.StageType) *dto.OvenStateV1 {
+	state := &dto.OvenStateV1{}
+	state.SystemInfo.Online = true
+	state.State.Mode = dto.StateModeCook
+	state.Nodes.Door.Closed = true
+	state.Cook = &dto.CookV1{CookID: "cook", ActiveStageIndex: stageIndex}
+	for _, stageType := range stageTypes {
+		state.Cook.Stages = append(state.Cook.Stages, dto.Stage{
+			Type: stageType,
+			TemperatureBulbs: &dto.StageTemperatureBulbs{
+				Mode: dto.TemperatureBulbsModeDry,
+				Dry:  &dto.TemperatureSetting{Setpoint: dto.NewTemperatureFromCelsius(200)},
+			},
+		})
+	}
+	return state
+}
+
+// triggersFor feeds the states to a new notifier as updates of one oven, and
+// returns the triggers of every notification sent.
+func triggersFor(t *testing.T, states ...*dto.OvenStateV1) []Trigger {
+	channel := &fakeChannel{}
+	notifier := New([]Route{{Name: "fake", Channel: channel}}, time.Minute)
+	now := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	notifier.now = func() time.Time { return now }
+
+	oven := &anova.Oven{CookerID: "oven", Name: "Kitchen"}
+	notifier.HandleEvent(anova.OvenAdded{Oven: oven, State: states[0]})
+	for _, state := range states[1:] {
+		now = now.Add(30 * time.Second)
+		notifier.HandleEvent(anova.OvenUpdated{Oven: oven, State: state})
+	}
+	notifier.Close()
+
+	var triggers []Trigger
+	for _, notification := range channel.sent {
+		if notification.CookerID != "oven" || !strings.HasPrefix(notification.Title, "Kitchen: ") {
+			t.Errorf("unexpected notification %+v", notification)
+		}
+		triggers = append(triggers, notification.Trigger)
+	}
+	return triggers
+}
+
+func expectTriggers(t *testing.T, name string, actual []Trigger, expected ...Trigger) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Errorf("%s: expected %v, got %v", name, expected, actual)
+		return
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("%s: expected %v, got %v", name, expected, actual)
+			return
+		}
+	}
+}
+
+func TestNotifier_Triggers(t *testing.T) {
+	stages := []dto.StageType{dto.StageTypePreheat, dto.StageTypeCook, dto.StageTypeCook}
+
+	preheating := newCookingState(0, stages...)
+	cooking := newCookingState(1, stages...)
+	expectTriggers(t, "preheated", triggersFor(t, preheating, preheating, cooking),
+		TriggerPreheated, TriggerStageChanged)
+
+	timerRunning := newCookingState(1, stages...)
+	timerRunning.Nodes.Timer = dto.TimerNodeV1{Mode: string(dto.TimerModeRunning), Initial: 600, Current: 300}
+	timerDone := newCookingState(1, stages...)
+	timerDone.Nodes.Timer = dto.TimerNodeV1{Mode: string(dto.TimerModeRunning), Initial: 600, Current: 600}
+	expectTriggers(t, "timer finished", triggersFor(t, timerRunning, timerDone, timerDone),
+		TriggerTimerFinished)
+
+	// The oven moved on before reporting the timer at 0
+	timerAlmostDone := newCookingState(1, stages...)
+	timerAlmostDone.Nodes.Timer = dto.TimerNodeV1{Mode: string(dto.TimerModeRunning), Initial: 600, Current: 595}
+	expectTriggers(t, "timer finished with the stage", triggersFor(t, timerAlmostDone, newCookingState(2, stages...)),
+		TriggerStageChanged, TriggerTimerFinished)
+
+	idle := &dto.OvenStateV1{}
+	idle.SystemInfo.Online = true
+	idle.State.Mode = dto.StateModeIdle
+	idle.Nodes.Door.Closed = true
+	expectTriggers(t, "stopped", triggersFor(t, timerRunning, idle), TriggerCookEnded)
+
+	probeBelow := newCookingState(1, stages...)
+	probeBelow.Nodes.TemperatureProbe = dto.TemperatureProbeNodeV1{Connected: true}
+	probeBelow.Nodes.TemperatureProbe.Current = &dto.Temperature{Celsius: 50}
+	probeBelow.Nodes.TemperatureProbe.Setpoint = &dto.Temperature{Celsius: 57}
+	probeAbove := newCookingState(1, stages...)
+	probeAbove.Nodes.TemperatureProbe = probeBelow.Nodes.TemperatureProbe
+	probeAbove.Nodes.TemperatureProbe.Current = &dto.Temperature{Celsius: 57.5}
+	expectTriggers(t, "probe", triggersFor(t, probeBelow, probeAbove, probeAbove), TriggerProbeReached)
+
+	tankEmpty := newCookingState(1, stages...)
+	tankEmpty.Nodes.WaterTank.Empty = true
+	expectTriggers(t, "water tank", triggersFor(t, cooking, tankEmpty, tankEmpty), TriggerWaterTankEmpty)
+
+	// Sent once the door has been open for a minute, i.e. on the third update
+	doorOpen := newCookingState(1, stages...)
+	doorOpen.Nodes.Door.Closed = false
+	expectTriggers(t, "door", triggersFor(t, cooking, doorOpen, doorOpen, doorOpen, doorOpen, cooking, doorOpen),
+		TriggerDoorOpen)
+	expectTriggers(t, "door while idle", triggersFor(t, idle, func() *dto.OvenStateV1 {
+		state := *idle
+		state.Nodes.Door.Closed = false
+		return &state
+	}(), idle, idle, idle))
+
+	offline := newCookingState(1, stages...)
+	offline.SystemInfo.Online = false
+	expectTriggers(t, "offline", triggersFor(t, cooking, offline, offline), TriggerOffline)
+}
+
+func TestNotifier_LongCook(t *testing.T) {
+	// A long cook's first stage, run as a chain of three stages, then a second
+	// stage
+	longCookState := func(stageIndex int, timer dto.TimerNodeV1) *dto.OvenStateV1 {
+		state := newCookingState(stageIndex, dto.StageTypeCook, dto.StageTypeCook, dto.StageTypeCook, dto.StageTypeCook)
+		for i, seconds := range []int{359940, 359940, 1000, 600} {
+			state.Cook.Stages[i].Timer = &dto.StageTimer{Initial: seconds}
+		}
+		state.Nodes.Timer = timer
+		return state
+	}
+	running := func(initial int, current int) dto.TimerNodeV1 {
+		return dto.TimerNodeV1{Mode: string(dto.TimerModeRunning), Initial: initial, Current: current}
+	}
+
+	expectTriggers(t, "continued", triggersFor(t,
+		longCookState(0, running(359940, 359935)),
+		longCookState(1, running(359940, 0)),
+		longCookState(1, running(359940, 359940)),
+		longCookState(2, running(1000, 0))))
+	expectTriggers(t, "chain finished", triggersFor(t,
+		longCookState(2, running(1000, 995)),
+		longCookState(3, running(600, 0))),
+		TriggerStageChanged, TriggerTimerFinished)
+
+	if index, stages := logicalStage(longCookState(3, dto.TimerNodeV1{}).Cook); index != 1 || stages != 2 {
+		t.Errorf("expected stage 2 of 2, got %d of %d", index+1, stages)
+	}
+}
+
+func TestNotifier_StageChangedETA(t *testing.T) {
+	stages := []dto.StageType{dto.StageTypeCook, dto.StageTypeCook}
+	notifier := New(nil, time.Minute)
+	defer notifier.Close()
+	entry := &ovenEntry{name: "Kitchen"}
+	remaining := 90*time.Minute + 20*time.Second
+
+	for _, test := range []struct {
+		progress *anova.CookProgress
+		expected string
+	}{
+		{nil, "Stage 2 of 2 has started."},
+		{&anova.CookProgress{CookID: "cook", ActiveStageIndex: 1}, "Stage 2 of 2 has started."},
+		{&anova.CookProgress{CookID: "other", ActiveStageIndex: 1, Remaining: &remaining}, "Stage 2 of 2 has started."},
+		{&anova.CookProgress{CookID: "cook", ActiveStageIndex: 1, Remaining: &remaining},
+			"Stage 2 of 2 has started. The cook should be done in about 1h30m."},
+	} {
+		notifications := notifier.detect(entry, newCookingState(0, stages...), newCookingState(1, stages...), test.progress)
+		if len(notifications) != 1 || notifications[0].Message != test.expected {
+			t.Errorf("expected %q, got %+v", test.expected, notifications)
+		}
+	}
+}
+
+func TestNotifier_TemperatureUnit(t *testing.T) {
+	stages := []dto.StageType{dto.StageTypePreheat, dto.StageTypeCook}
+	notifier := New(nil, time.Minute)
+	defer notifier.Close()
+
+	for unit, expected := range map[dto.TemperatureUnit]string{
+		dto.TemperatureUnitCelsius:    "The oven has reached 200 °C.",
+		dto.TemperatureUnitFahrenheit: "The oven has reached 392 °F.",
+	} {
+		preheating := newCookingState(0, stages...)
+		preheating.State.TemperatureUnit = unit
+		cooking := newCookingState(1, stages...)
+		cooking.State.TemperatureUnit = unit
+
+		notifications := notifier.detect(&ovenEntry{name: "Kitchen"}, preheating, cooking, nil)
+		if len(notifications) != 2 || notifications[0].Message != expected {
+			t.Errorf("expected %q, got %+v", expected, notifications)
+		}
+	}
+}
+
+func TestNotifier_Routes(t *testing.T) {
+	all, doorOnly := &fakeChannel{}, &fakeChannel{}
+	notifier := New([]Route{
+		{Name: "all", Channel: all},
+		{Name: "door", Channel: doorOnly, Triggers: []Trigger{TriggerDoorOpen}},
+	}, 0)
+	notifier.Notify(Notification{Trigger: TriggerPreheated})
+	notifier.Notify(Notification{Trigger: TriggerDoorOpen})
+	notifier.Close()
+
+	if len(all.sent) != 2 || len(doorOnly.sent) != 1 || doorOnly.sent[0].Trigger != TriggerDoorOpen {
+		t.Errorf("unexpected routing: %+v, %+v", all.sent, doorOnly.sent)
+	}
+	if all.sent[0].Time.IsZero() {
+		t.Errorf("expected notification time to be set")
+	}
+}
+
+var testNotification = Notification{
+	Trigger:  TriggerDoorOpen,
+	CookerID: "oven",
+	OvenName: "Kitchen",
+	Time:     time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC),
+	Title:    "Kitchen: Door open",
+	Message:  `The "door" has been open for 2m0s.`,
+}
+
+func TestWebhookAndPushChannels(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		requests = append(requests, request)
+		bodies = append(bodies, string(body))
+		if request.URL.Path == "/fail" {
+			http.Error(writer, "nope", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	templated, err := NewWebhookChannel(server.URL+"/hook", "PUT", map[string]string{"X-Key": "secret"},
+		`{"text": {{json .Message}}, "oven": "{{.OvenName}}"}`)
+	if err != nil {
+		t.Fatalf("invalid webhook: %+v", err)
+	}
+	plain, _ := NewWebhookChannel(server.URL+"/plain", "", nil, "")
+	failing, _ := NewWebhookChannel(server.URL+"/fail", "", nil, "")
+	for _, channel := range []Channel{
+		templated,
+		plain,
+		NewNtfyChannel(server.URL+"/topic", "token"),
+		NewGotifyChannel(server.URL+"/", "app-token"),
+	} {
+		if err := channel.Send(testNotification); err != nil {
+			t.Fatalf("send failed: %+v", err)
+		}
+	}
+	if err := failing.Send(testNotification); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("expected failure with response body, got %+v", err)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal([]byte(bodies[0]), &body); err != nil || body["text"] != testNotification.Message || body["oven"] != "Kitchen" {
+		t.Errorf("unexpected templated body %s (%+v)", bodies[0], err)
+	}
+	if requests[0].Method != "PUT" || requests[0].Header.Get("X-Key") != "secret" {
+		t.Errorf("unexpected templated request %+v", requests[0])
+	}
+
+	var notification Notification
+	if err := json.Unmarshal([]byte(bodies[1]), &notification); err != nil || notification != testNotification {
+		t.Errorf("unexpected default body %s (%+v)", bodies[1], err)
+	}
+
+	ntfy := requests[2]
+	if bodies[2] != testNotification.Message || ntfy.Header.Get("Title") != testNotification.Title ||
+		ntfy.Header.Get("Priority") != "high" || ntfy.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("unexpected ntfy request %+v: %s", ntfy.Header, bodies[2])
+	}
+
+	gotify := requests[3]
+	if gotify.URL.Path != "/message" || gotify.Header.Get("X-Gotify-Key") != "app-token" ||
+		!strings.Contains(bodies[3], `"priority":8`) {
+		t.Errorf("unexpected Gotify request %s %+v: %s", gotify.URL, gotify.Header, bodies[3])
+	}
+}
+
+func TestEmailChannel(t *testing.T) {
+	channel := NewEmailChannel("smtp.example.com:587", "user", "password", "oven@example.com", []string{"a@example.com", "b@example.com"})
+	var sentTo []string
+	var message string
+	channel.sendMail = func(address string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		if address != "smtp.example.com:587" || auth == nil || from != "oven@example.com" {
+			t.Errorf("unexpected envelope %s %v %s", address, auth, from)
+		}
+		sentTo, message = to, string(msg)
+		return nil
+	}
+
+	if err := channel.Send(testNotification); err != nil {
+		t.Fatalf("send failed: %+v", err)
+	}
+	if len(sentTo) != 2 {
+		t.Errorf("unexpected recipients %v", sentTo)
+	}
+	for _, expected := range []string{
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: Kitchen: Door open\r\n",
+		"Date: Fri, 01 Mar 2024 18:00:00 +0000\r\n",
+		"\r\n\r\n" + testNotification.Message + "\r\n",
+	} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("expected message to contain %q:\n%s", expected, message)
+		}
+	}
+}
+
+func TestDesktopChannel_NotifyArgs(t *testing.T) {
+	args := (&DesktopChannel{ExpireSeconds: 5}).notifyArgs(Notification{
+		Trigger: TriggerPreheated,
+		Title:   "Kitchen: Preheated",
+		Message: `It's at 200 °C \o/`,
+	})
+	expected := []string{
+		"'apocli'", "uint32 0", "''", "'Kitchen: Preheated'", `'It\'s at 200 °C \\o/'`,
+		"@as []", "{'urgency': <byte 1>}", "int32 5000",
+	}
+	actual := args[len(args)-len(expected):]
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("expected arguments %q, got %q", expected, actual)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.toml")
+	err := os.WriteFile(path, []byte(`
+door_open_after = "5m"
+
+[[ntfy]]
+url = "https://ntfy.sh/oven"
+triggers = ["preheated", "timer_finished"]
+
+[[webhook]]
+url = "https://example.com/hook"
+body = '{"text": {{json .Title}}}'
+
+[[desktop]]
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("load failed: %+v", err)
+	}
+	if config.DoorOpenAfter != 5*time.Minute {
+		t.Errorf("unexpected door_open_after %s", config.DoorOpenAfter)
+	}
+	routes, err := config.Routes()
+	if err != nil {
+		t.Fatalf("invalid routes: %+v", err)
+	}
+	if len(routes) != 3 || len(routes[1].Triggers) != 2 || routes[0].Name != "webhook 1" {
+		t.Errorf("unexpected routes %+v", routes)
+	}
+
+	for _, invalid := range []string{
+		"[[ntfy]]\nurl = \"https://ntfy.sh/oven\"\ntriggers = [\"burnt\"]\n",
+		"[[gotify]]\nurl = \"https://gotify.example.com\"\n",
+		"[[email]]\nserver = \"smtp.example.com:587\"\n",
+		"[[webhook]]\nurl = \"https://example.com\"\nbody = \"{{.Nope\"\n",
+		"",
+	} {
+		os.WriteFile(path, []byte(invalid), 0600)
+		config, err := LoadConfig(path)
+		if err == nil {
+			_, err = config.Routes()
+		}
+		if err == nil {
+			t.Errorf("expected config to be invalid:\n%s", invalid)
+		}
+	}
+
+	os.WriteFile(path, []byte("[[ntfy]]\nurl = \"https://ntfy.sh/oven\"\ntrigger = [\"preheated\"]\n"), 0600)
+	if _, err = LoadConfig(path); err == nil || !strings.Contains(err.Error(), "ntfy.trigger") {
+		t.Errorf("expected unknown key to be rejected, got %+v", err)
+	}
+}
@@ -0,0 +1,181 @@
+// Package program runs closed-loop cooking programs. The oven only runs stages
+// with fixed setpoints; a program instead adjusts the running cook's setpoints
+// on every state update, e.g. to track the probe temperature or to ramp the
+// temperature over time.
+package program
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Input is what a program sees on every state update of the running cook.
+type Input struct {
+	State *dto.OvenStateV1
+	// Time since the program started
+	Elapsed time.Duration
+}
+
+// probeCelsius returns the probe's current temperature, if it is connected.
+func (input Input) probeCelsius() (float64, bool) {
+	probe := input.State.Nodes.TemperatureProbe
+	if !probe.Connected || probe.Current == nil {
+		return 0, false
+	}
+	return probe.Current.Celsius, true
+}
+
+// Output is what a program wants the oven to do. Nil fields are left as they
+// are.
+type Output struct {
+	Setpoint        *anova.TemperatureSetpoint
+	SteamPercentage *anova.SteamPercentage
+	// The program has finished once the output has been applied
+	Done bool
+}
+
+type Program interface {
+	anova.Validatable
+	fmt.Stringer
+
+	// Step returns the program's output for a state update. It is called for
+	// every state update of the running cook.
+	Step(input Input) Output
+}
+
+// DeltaT keeps the bulb a fixed offset above the probe temperature, so the
+// food heats up gently and evenly. End the cook with a probe target, as the
+// program runs until the cook ends.
+type DeltaT struct {
+	Mode          anova.TemperatureMode
+	OffsetCelsius float64
+}
+
+func (program DeltaT) Validate() error {
+	if err := program.Mode.Validate(); err != nil {
+		return err
+	}
+	if program.OffsetCelsius <= 0 {
+		return errors.New("Delta-T offset must be positive")
+	}
+	return nil
+}
+
+func (program DeltaT) String() string {
+	return fmt.Sprintf("Delta-T: %s bulb %.1f °C above the probe", program.Mode, program.OffsetCelsius)
+}
+
+func (program DeltaT) Step(input Input) Output {
+	probeCelsius, connected := input.probeCelsius()
+	if !connected {
+		return Output{}
+	}
+	setpoint := anova.NewSetpoint(probeCelsius+program.OffsetCelsius, program.Mode)
+	return Output{Setpoint: &setpoint}
+}
+
+// TemperatureRamp changes the bulb's setpoint linearly over time.
+type TemperatureRamp struct {
+	Mode        anova.TemperatureMode
+	FromCelsius float64
+	ToCelsius   float64
+	Over        time.Duration
+}
+
+func (program TemperatureRamp) Validate() error {
+	if err := program.Mode.Validate(); err != nil {
+		return err
+	}
+	if program.Over <= 0 {
+		return errors.New("ramp duration must be positive")
+	}
+	for _, celsius := range []float64{program.FromCelsius, program.ToCelsius} {
+		if err := anova.NewSetpoint(celsius, program.Mode).Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (program TemperatureRamp) String() string {
+	return fmt.Sprintf("ramp: %s bulb from %.1f °C to %.1f °C over %s",
+		program.Mode, program.FromCelsius, program.ToCelsius, program.Over)
+}
+
+func (program TemperatureRamp) Step(input Input) Output {
+	fraction, done := rampFraction(input.Elapsed, program.Over)
+	setpoint := anova.NewSetpoint(program.FromCelsius+fraction*(program.ToCelsius-program.FromCelsius), program.Mode)
+	return Output{Setpoint: &setpoint, Done: done}
+}
+
+// SteamRamp changes the relative humidity (in wet mode) or steam percentage (in
+// dry mode) linearly over time.
+type SteamRamp struct {
+	FromPercentage float64
+	ToPercentage   float64
+	Over           time.Duration
+}
+
+func (program SteamRamp) Validate() error {
+	if program.Over <= 0 {
+		return errors.New("ramp duration must be positive")
+	}
+	for _, percentage := range []float64{program.FromPercentage, program.ToPercentage} {
+		if err := anova.SteamPercentage(percentage).Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (program SteamRamp) String() string {
+	return fmt.Sprintf("ramp: steam from %.0f%% to %.0f%% over %s",
+		program.FromPercentage, program.ToPercentage, program.Over)
+}
+
+func (program SteamRamp) Step(input Input) Output {
+	fraction, done := rampFraction(input.Elapsed, program.Over)
+	return Output{
+		SteamPercentage: anova.NewSteamPercentage(program.FromPercentage + fraction*(program.ToPercentage-program.FromPercentage)),
+		Done:            done,
+	}
+}
+
+func rampFraction(elapsed time.Duration, over time.Duration) (fraction float64, done bool) {
+	if elapsed >= over {
+		return 1, true
+	}
+	return float64(elapsed) / float64(over), false
+}
+
+// ReverseSear switches to a searing setpoint once the probe reaches a
+// threshold, after cooking low and slow. Stop the cook once the outside is
+// seared; the program finishes as soon as it has switched.
+type ReverseSear struct {
+	ProbeCelsius float64
+	Sear         anova.TemperatureSetpoint
+}
+
+func (program ReverseSear) Validate() error {
+	if err := anova.NewProbeCelsius(program.ProbeCelsius).Validate(); err != nil {
+		return err
+	}
+	return program.Sear.Validate()
+}
+
+func (program ReverseSear) String() string {
+	return fmt.Sprintf("reverse sear: %s bulb to %.1f °C once the probe reaches %.1f °C",
+		program.Sear.Mode, program.Sear.TemperatureCelsius, program.ProbeCelsius)
+}
+
+func (program ReverseSear) Step(input Input) Output {
+	probeCelsius, connected := input.probeCelsius()
+	if !connected || probeCelsius < program.ProbeCelsius {
+		return Output{}
+	}
+	sear := program.Sear
+	return Output{Setpoint: &sear, Done: true}
+}
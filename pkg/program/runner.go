@@ -0,0 +1,243 @@
+package program
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// Setpoint changes smaller than these aren't sent, so a program that tracks
+	// a slowly changing value doesn't send a command on every update
+	minSetpointChangeCelsius = 0.5
+	minSteamChange           = 1
+
+	// MinCommandInterval is how often a runner sends commands at most, unless
+	// the program has finished. The oven reports its state every few seconds
+	// while cooking, and reports the new setpoint a little after a command.
+	MinCommandInterval = 30 * time.Second
+)
+
+// Runner runs a program against the cook running on one oven, from the first
+// state update until the program finishes or the cook ends. Setpoints are
+// clamped to the limits the oven accepts for the running stage.
+type Runner struct {
+	program  Program
+	cookerID anova.CookerID
+
+	mutex    sync.Mutex
+	cookID   string
+	started  time.Time
+	lastSent time.Time
+	sending  bool
+	finished bool
+	err      error
+	done     chan struct{}
+
+	now         func() time.Time
+	setSetpoint func(oven *anova.Oven, setpoint anova.TemperatureSetpoint) error
+	setSteam    func(oven *anova.Oven, mode anova.TemperatureMode, percentage anova.SteamPercentage) error
+	// Commands in progress
+	inFlight sync.WaitGroup
+}
+
+func NewRunner(program Program, cookerID anova.CookerID) *Runner {
+	return &Runner{
+		program:  program,
+		cookerID: cookerID,
+		done:     make(chan struct{}),
+
+		now: time.Now,
+		setSetpoint: func(oven *anova.Oven, setpoint anova.TemperatureSetpoint) error {
+			return oven.SetTemperatureSetpoint(setpoint)
+		},
+		setSteam: func(oven *anova.Oven, mode anova.TemperatureMode, percentage anova.SteamPercentage) error {
+			return oven.SetSteamPercentage(mode, percentage)
+		},
+	}
+}
+
+// Done is closed once the program has finished or the cook has ended.
+func (runner *Runner) Done() <-chan struct{} {
+	return runner.done
+}
+
+// Err returns why the runner stopped before the program finished, if it did.
+func (runner *Runner) Err() error {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	return runner.err
+}
+
+// Wait blocks until every command in progress has completed.
+func (runner *Runner) Wait() {
+	runner.inFlight.Wait()
+}
+
+func (runner *Runner) HandleEvent(event anova.Event) {
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		runner.step(event.Oven, event.State)
+	case anova.OvenUpdated:
+		runner.step(event.Oven, event.State)
+	}
+}
+
+// finish must be called with the mutex held.
+func (runner *Runner) finish(err error) {
+	if runner.finished {
+		return
+	}
+	runner.finished = true
+	runner.err = err
+	close(runner.done)
+}
+
+func (runner *Runner) step(oven *anova.Oven, state *dto.OvenStateV1) {
+	if oven.CookerID != runner.cookerID || state == nil {
+		return
+	}
+
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	if runner.finished {
+		return
+	}
+
+	now := runner.now()
+	cooking := state.State.Mode == dto.StateModeCook && state.Cook != nil
+	switch {
+	case runner.cookID == "" && !cooking:
+		runner.finish(ErrNotCooking{})
+		return
+	case runner.cookID == "":
+		runner.cookID = state.Cook.CookID
+		runner.started = now
+	case !cooking || state.Cook.CookID != runner.cookID:
+		runner.finish(ErrCookEnded{})
+		return
+	}
+
+	output := runner.program.Step(Input{State: state, Elapsed: now.Sub(runner.started)})
+	setpoint, steam := runner.changes(state, output)
+	if setpoint == nil && steam == nil {
+		if output.Done {
+			runner.finish(nil)
+		}
+		return
+	}
+	if runner.sending || (!output.Done && now.Sub(runner.lastSent) < MinCommandInterval) {
+		return
+	}
+	runner.sending = true
+	runner.lastSent = now
+
+	// Commands block until the oven responds, so don't hold up other handlers
+	runner.inFlight.Add(1)
+	go runner.send(oven, stageMode(state), setpoint, steam, output.Done)
+}
+
+// changes returns the parts of the output that differ enough from the oven's
+// current setpoints to be sent, clamped to the running stage's limits.
+func (runner *Runner) changes(state *dto.OvenStateV1, output Output) (*anova.TemperatureSetpoint, *anova.SteamPercentage) {
+	var setpoint *anova.TemperatureSetpoint
+	if output.Setpoint != nil {
+		clamped := output.Setpoint.Clamp(stageHeatingElements(state))
+		bulbs := state.Nodes.TemperatureBulbs
+		current := bulbs.Dry.Setpoint
+		if clamped.Mode == anova.TemperatureModeWet {
+			current = bulbs.Wet.Setpoint
+		}
+		if current == nil || string(bulbs.Mode) != string(clamped.Mode) ||
+			math.Abs(current.Celsius-clamped.TemperatureCelsius) >= minSetpointChangeCelsius {
+			setpoint = &clamped
+		}
+	}
+
+	var steam *anova.SteamPercentage
+	if output.SteamPercentage != nil {
+		clamped := anova.SteamPercentage(math.Max(0, math.Min(100, float64(*output.SteamPercentage))))
+		generators := state.Nodes.SteamGenerators
+		current := -1
+		if generators.RelativeHumidity != nil {
+			current = generators.RelativeHumidity.Setpoint
+		} else if generators.SteamPercentage != nil {
+			current = generators.SteamPercentage.Setpoint
+		}
+		if current < 0 || math.Abs(float64(current)-float64(clamped)) >= minSteamChange {
+			steam = &clamped
+		}
+	}
+	return setpoint, steam
+}
+
+func (runner *Runner) send(oven *anova.Oven, mode anova.TemperatureMode, setpoint *anova.TemperatureSetpoint, steam *anova.SteamPercentage, done bool) {
+	defer runner.inFlight.Done()
+
+	var errs []error
+	if setpoint != nil {
+		slog.Info("program is changing setpoint",
+			slog.String("cookerID", string(oven.CookerID)),
+			slog.String("mode", string(setpoint.Mode)),
+			slog.Float64("celsius", math.Round(setpoint.TemperatureCelsius*10)/10))
+		errs = append(errs, runner.setSetpoint(oven, *setpoint))
+	}
+	if steam != nil {
+		slog.Info("program is changing steam",
+			slog.String("cookerID", string(oven.CookerID)),
+			slog.Float64("percentage", math.Round(float64(*steam))))
+		errs = append(errs, runner.setSteam(oven, mode, *steam))
+	}
+	err := errors.Join(errs...)
+
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	runner.sending = false
+	if err != nil {
+		// Tried again on a later update
+		slog.Error("program failed to adjust cook",
+			slog.String("cookerID", string(oven.CookerID)),
+			slog.Any("err", err))
+		return
+	}
+	if done {
+		runner.finish(nil)
+	}
+}
+
+// stageMode returns the temperature mode of the running stage.
+func stageMode(state *dto.OvenStateV1) anova.TemperatureMode {
+	if state.Nodes.TemperatureBulbs.Mode == dto.TemperatureBulbsModeWet {
+		return anova.TemperatureModeWet
+	}
+	return anova.TemperatureModeDry
+}
+
+// stageHeatingElements returns the heating elements of the running stage. The
+// heating element nodes can't be used, as they turn on and off to hold the
+// setpoint.
+func stageHeatingElements(state *dto.OvenStateV1) anova.HeatingElements {
+	cook := state.Cook
+	if cook == nil || cook.ActiveStageIndex < 0 || cook.ActiveStageIndex >= len(cook.Stages) ||
+		cook.Stages[cook.ActiveStageIndex].HeatingElements == nil {
+		return anova.RearElementOnly
+	}
+	elements := cook.Stages[cook.ActiveStageIndex].HeatingElements
+	return anova.NewHeatingElements(elements.Top.On, elements.Rear.On, elements.Bottom.On)
+}
+
+type ErrNotCooking struct{}
+
+func (err ErrNotCooking) Error() string {
+	return "the oven is not cooking; start a cook before running a program"
+}
+
+type ErrCookEnded struct{}
+
+func (err ErrCookEnded) Error() string {
+	return "the cook ended before the program finished"
+}
@@ -0,0 +1,162 @@
+package program
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newCookingState(dryCelsius float64, setpointCelsius float64, probeCelsius *float64) *dto.OvenStateV1 {
+	state := &dto.OvenStateV1{}
+	state.State.Mode = dto.StateModeCook
+	state.Cook = &dto.CookV1{
+		CookID: "cook",
+		Stages: []dto.Stage{{
+			HeatingElements: &dto.StageHeatingElements{Rear: dto.HeatingElementSetting{On: true}},
+		}},
+	}
+	state.Nodes.TemperatureBulbs.Mode = dto.TemperatureBulbsModeDry
+	state.Nodes.TemperatureBulbs.Dry.Current = dto.NewTemperatureFromCelsius(dryCelsius)
+	setpoint := dto.NewTemperatureFromCelsius(setpointCelsius)
+	state.Nodes.TemperatureBulbs.Dry.Setpoint = &setpoint
+	if probeCelsius != nil {
+		probe := dto.NewTemperatureFromCelsius(*probeCelsius)
+		state.Nodes.TemperatureProbe.Connected = true
+		state.Nodes.TemperatureProbe.Current = &probe
+	}
+	return state
+}
+
+func celsius(value float64) *float64 {
+	return &value
+}
+
+type runnerTest struct {
+	runner    *Runner
+	oven      *anova.Oven
+	now       time.Time
+	setpoints []float64
+	steam     []float64
+	setErr    error
+}
+
+func newRunnerTest(program Program) *runnerTest {
+	test := &runnerTest{
+		oven: &anova.Oven{CookerID: "oven", Name: "Kitchen"},
+		now:  time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC),
+	}
+	test.runner = NewRunner(program, "oven")
+	test.runner.now = func() time.Time { return test.now }
+	test.runner.setSetpoint = func(oven *anova.Oven, setpoint anova.TemperatureSetpoint) error {
+		test.setpoints = append(test.setpoints, setpoint.TemperatureCelsius)
+		return test.setErr
+	}
+	test.runner.setSteam = func(oven *anova.Oven, mode anova.TemperatureMode, percentage anova.SteamPercentage) error {
+		test.steam = append(test.steam, float64(percentage))
+		return test.setErr
+	}
+	return test
+}
+
+func (test *runnerTest) update(after time.Duration, state *dto.OvenStateV1) {
+	test.now = test.now.Add(after)
+	test.runner.HandleEvent(anova.OvenUpdated{Oven: test.oven, State: state})
+	test.runner.Wait()
+}
+
+func (test *runnerTest) finished() bool {
+	select {
+	case <-test.runner.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestRunner_DeltaT(t *testing.T) {
+	test := newRunnerTest(DeltaT{Mode: anova.TemperatureModeDry, OffsetCelsius: 20})
+
+	// Nothing to do until the probe is connected
+	test.update(0, newCookingState(60, 60, nil))
+	test.update(MinCommandInterval, newCookingState(60, 60, celsius(20)))
+	test.update(time.Second, newCookingState(60, 40, celsius(21)))
+	// Small changes aren't sent
+	test.update(MinCommandInterval, newCookingState(41, 40, celsius(20.2)))
+	test.update(MinCommandInterval, newCookingState(41, 40, celsius(30)))
+
+	if len(test.setpoints) != 2 || test.setpoints[0] != 40 || test.setpoints[1] != 50 {
+		t.Errorf("unexpected setpoints %v", test.setpoints)
+	}
+	if test.finished() {
+		t.Errorf("expected Delta-T to run until the cook ends")
+	}
+
+	idle := &dto.OvenStateV1{}
+	idle.State.Mode = dto.StateModeIdle
+	test.update(time.Second, idle)
+	if !test.finished() || !errors.As(test.runner.Err(), &ErrCookEnded{}) {
+		t.Errorf("expected runner to stop when the cook ends, got %v", test.runner.Err())
+	}
+}
+
+func TestRunner_TemperatureRamp(t *testing.T) {
+	test := newRunnerTest(TemperatureRamp{Mode: anova.TemperatureModeDry, FromCelsius: 100, ToCelsius: 200, Over: 10 * time.Minute})
+
+	test.update(0, newCookingState(100, 150, nil))
+	test.update(5*time.Minute, newCookingState(100, 100, nil))
+	// Failures are retried on the next update, once the interval has passed
+	test.setErr = errors.New("timed out")
+	test.update(4*time.Minute, newCookingState(150, 150, nil))
+	test.setErr = nil
+	test.update(10*time.Second, newCookingState(150, 150, nil))
+	test.update(MinCommandInterval-10*time.Second, newCookingState(150, 150, nil))
+	if test.finished() {
+		t.Fatalf("expected ramp to still be running")
+	}
+	// The final setpoint is sent right away
+	test.update(time.Minute, newCookingState(150, 150, nil))
+
+	expected := []float64{100, 150, 190, 195, 200}
+	if len(test.setpoints) != len(expected) {
+		t.Fatalf("expected setpoints %v, got %v", expected, test.setpoints)
+	}
+	for i := range expected {
+		if test.setpoints[i] != expected[i] {
+			t.Fatalf("expected setpoints %v, got %v", expected, test.setpoints)
+		}
+	}
+	if !test.finished() || test.runner.Err() != nil {
+		t.Errorf("expected ramp to finish, got %v", test.runner.Err())
+	}
+}
+
+func TestRunner_ReverseSear(t *testing.T) {
+	test := newRunnerTest(ReverseSear{ProbeCelsius: 50, Sear: anova.NewNonSousVideSetpointCelsius(250)})
+
+	bottomOnly := newCookingState(120, 120, celsius(50))
+	bottomOnly.Cook.Stages[0].HeatingElements = &dto.StageHeatingElements{Bottom: dto.HeatingElementSetting{On: true}}
+
+	test.update(0, newCookingState(120, 120, celsius(40)))
+	test.update(time.Second, bottomOnly)
+
+	// Clamped to the limit for bottom heating
+	if len(test.setpoints) != 1 || test.setpoints[0] != 180 {
+		t.Errorf("unexpected setpoints %v", test.setpoints)
+	}
+	if !test.finished() || test.runner.Err() != nil {
+		t.Errorf("expected reverse sear to finish, got %v", test.runner.Err())
+	}
+}
+
+func TestRunner_NotCooking(t *testing.T) {
+	test := newRunnerTest(SteamRamp{FromPercentage: 0, ToPercentage: 100, Over: time.Hour})
+
+	idle := &dto.OvenStateV1{}
+	idle.State.Mode = dto.StateModeIdle
+	test.update(0, idle)
+	if !test.finished() || !errors.As(test.runner.Err(), &ErrNotCooking{}) {
+		t.Errorf("expected runner to refuse an idle oven, got %v", test.runner.Err())
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"apocli/pkg/anova"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rough heating rates of an APO starting from room temperature, for
+// estimating preheat times. Steam heats the wet bulb much more slowly.
+const (
+	ambientCelsius             = 20.0
+	dryPreheatCelsiusPerMinute = 9.0
+	wetPreheatCelsiusPerMinute = 4.0
+)
+
+// EstimatePreheat estimates how long the oven takes to heat from one
+// temperature to another. Cooling down is treated as instant.
+func EstimatePreheat(fromCelsius float64, setpoint anova.TemperatureSetpoint) time.Duration {
+	rise := setpoint.TemperatureCelsius - fromCelsius
+	if rise <= 0 {
+		return 0
+	}
+
+	rate := dryPreheatCelsiusPerMinute
+	if setpoint.Mode == anova.TemperatureModeWet {
+		rate = wetPreheatCelsiusPerMinute
+	}
+	return time.Duration(rise / rate * float64(time.Minute)).Round(time.Minute)
+}
+
+// EstimateDuration estimates how long a cook takes from being started to being
+// ready, from its timers and the estimated preheat time of each stage. Every
+// stage but the last needs a timer that starts on its own; the last stage may
+// instead run until stopped, e.g. to keep the food warm, in which case the
+// cook is ready once it has preheated.
+func EstimateDuration(cook *anova.Cook) (time.Duration, error) {
+	var duration time.Duration
+	temperature := ambientCelsius
+	for i, stage := range cook.Stages {
+		last := i == len(cook.Stages)-1
+
+		preheats := true
+		switch terminator := stage.Terminator.(type) {
+		case nil:
+			if !last {
+				return 0, ErrUnknownCookDuration{Stage: i + 1, Reason: "it runs until stopped"}
+			}
+		case *anova.Timer:
+			switch terminator.Trigger {
+			case anova.TimerTriggerImmediately:
+				preheats = false
+			case anova.TimerTriggerManually:
+				return 0, ErrUnknownCookDuration{Stage: i + 1, Reason: "its timer is started manually"}
+			}
+			duration += time.Duration(terminator.DurationSeconds) * time.Second
+		case *anova.Probe:
+			return 0, ErrUnknownCookDuration{Stage: i + 1, Reason: "it ends on the probe temperature"}
+		}
+
+		if preheats {
+			duration += EstimatePreheat(temperature, stage.TemperatureSetpoint)
+		}
+		temperature = stage.TemperatureSetpoint.TemperatureCelsius
+	}
+	return duration, nil
+}
+
+// StartTimeForReadyBy works back from when the cook should be ready to when it
+// needs to be started.
+func StartTimeForReadyBy(cook *anova.Cook, readyBy time.Time) (time.Time, error) {
+	duration, err := EstimateDuration(cook)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return readyBy.Add(-duration), nil
+}
+
+// ParseTime parses a wall-clock time in the local time zone, either as
+// "15:04", meaning the next time the clock shows it, or as a full
+// "2006-01-02 15:04" or RFC 3339 timestamp.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if clock, err := time.ParseInLocation("15:04", value, now.Location()); err == nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time \"%s\"; expected e.g. \"17:30\" or \"2006-01-02 17:30\"", value)
+}
+
+type ErrUnknownCookDuration struct {
+	// 1-based
+	Stage  int
+	Reason string
+}
+
+func (err ErrUnknownCookDuration) Error() string {
+	return fmt.Sprintf("cannot estimate when the cook will be ready: stage %d has no fixed duration, as %s", err.Stage, err.Reason)
+}
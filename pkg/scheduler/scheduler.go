@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// How often the store is checked for due jobs
+	CheckInterval = 5 * time.Second
+	// Jobs that couldn't be started within this long of their start time, e.g.
+	// because the daemon wasn't running, are dropped rather than started late
+	MissedJobGracePeriod = 15 * time.Minute
+)
+
+// Scheduler starts the jobs in a Store once they are due. It tracks the ovens
+// of a Service through HandleEvent, so it must be passed every event.
+type Scheduler struct {
+	store     *Store
+	observers []func(job Job, cook *anova.Cook, oven *anova.Oven)
+
+	mutex sync.Mutex
+	ovens map[anova.CookerID]*ovenEntry
+	// Jobs already logged as waiting for their oven. Only accessed from
+	// StartDueJobs.
+	waiting map[string]bool
+}
+
+type ovenEntry struct {
+	oven *anova.Oven
+	mode dto.StateMode
+}
+
+// New creates a scheduler for the jobs in the store. The observers are called
+// with every cook the scheduler starts.
+func New(store *Store, observers ...func(job Job, cook *anova.Cook, oven *anova.Oven)) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		observers: observers,
+
+		ovens:   make(map[anova.CookerID]*ovenEntry),
+		waiting: make(map[string]bool),
+	}
+}
+
+// Schedule validates the job's recipe and saves the job to the store.
+func (scheduler *Scheduler) Schedule(job Job) (Job, error) {
+	if _, err := job.Recipe.NewCook(); err != nil {
+		return Job{}, err
+	}
+	return scheduler.store.Add(job)
+}
+
+func (scheduler *Scheduler) HandleEvent(event anova.Event) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		scheduler.ovens[event.Oven.CookerID] = &ovenEntry{
+			oven: event.Oven,
+			mode: stateMode(event.State),
+		}
+	case anova.OvenUpdated:
+		if entry, exists := scheduler.ovens[event.Oven.CookerID]; exists {
+			entry.mode = stateMode(event.State)
+		}
+	}
+}
+
+func stateMode(state *dto.OvenStateV1) dto.StateMode {
+	if state == nil {
+		return ""
+	}
+	return state.State.Mode
+}
+
+// Run starts jobs as they become due, until done is closed.
+func (scheduler *Scheduler) Run(done <-chan struct{}) {
+	ticker := time.NewTicker(CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		err := scheduler.StartDueJobs(time.Now())
+		if err != nil {
+			slog.Error("failed to check scheduled cooks", slog.Any("err", err))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+// StartDueJobs starts every job due at or before now. A job is removed from the
+// store before its cook is started, so that it is never started twice; jobs
+// whose oven isn't available are left for the next check.
+func (scheduler *Scheduler) StartDueJobs(now time.Time) error {
+	jobs, err := scheduler.store.List()
+	if err != nil {
+		return err
+	}
+	scheduler.forgetWaiting(jobs)
+
+	for _, job := range jobs {
+		if job.StartAt.After(now) {
+			break
+		}
+
+		if now.Sub(job.StartAt) > MissedJobGracePeriod {
+			slog.Warn("dropping scheduled cook that was missed",
+				slog.String("job", job.ID),
+				slog.Time("startAt", job.StartAt))
+			delete(scheduler.waiting, job.ID)
+			_, err = scheduler.store.Remove(job.ID)
+			if err != nil && !errors.As(err, &ErrJobNotFound{}) {
+				return err
+			}
+			continue
+		}
+
+		oven, err := scheduler.availableOven(job)
+		if err != nil {
+			if !scheduler.waiting[job.ID] {
+				scheduler.waiting[job.ID] = true
+				slog.Warn("scheduled cook is waiting for its oven",
+					slog.String("job", job.ID),
+					slog.Any("err", err))
+			}
+			continue
+		}
+
+		delete(scheduler.waiting, job.ID)
+		_, err = scheduler.store.Remove(job.ID)
+		if errors.As(err, &ErrJobNotFound{}) {
+			// Cancelled since it was listed
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		cook, err := startJob(job, oven)
+		if err != nil {
+			slog.Error("failed to start scheduled cook",
+				slog.String("job", job.ID),
+				slog.Any("err", err))
+			continue
+		}
+		slog.Info("started scheduled cook",
+			slog.String("job", job.ID),
+			slog.String("cookerID", string(job.CookerID)))
+		for _, observer := range scheduler.observers {
+			observer(job, cook, oven)
+		}
+	}
+	return nil
+}
+
+// forgetWaiting stops tracking jobs that have left the store, e.g. because they
+// were cancelled.
+func (scheduler *Scheduler) forgetWaiting(jobs []Job) {
+	listed := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		listed[job.ID] = true
+	}
+	for id := range scheduler.waiting {
+		if !listed[id] {
+			delete(scheduler.waiting, id)
+		}
+	}
+}
+
+// availableOven returns the job's oven if it is online and idle.
+func (scheduler *Scheduler) availableOven(job Job) (*anova.Oven, error) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	entry, exists := scheduler.ovens[job.CookerID]
+	if !exists {
+		return nil, fmt.Errorf("oven \"%s\" has not been seen", job.CookerID)
+	}
+	if entry.mode != dto.StateModeIdle {
+		return nil, fmt.Errorf("oven \"%s\" is busy (%s)", job.CookerID, entry.mode)
+	}
+	return entry.oven, nil
+}
+
+func startJob(job Job, oven *anova.Oven) (*anova.Cook, error) {
+	cook, err := job.Recipe.NewCook()
+	if err != nil {
+		return nil, err
+	}
+	return cook, cook.Start(oven)
+}
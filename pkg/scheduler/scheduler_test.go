@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestRecipe(stages ...anova.RecipeStage) anova.Recipe {
+	return anova.Recipe{Stages: stages}
+}
+
+func timedStage(mode anova.TemperatureMode, temperatureCelsius float64, duration string, trigger anova.TimerTrigger) anova.RecipeStage {
+	return anova.RecipeStage{
+		Mode:               mode,
+		TemperatureCelsius: temperatureCelsius,
+		HeatingElements:    []string{"rear"},
+		Timer:              &anova.RecipeTimer{Duration: duration, Trigger: trigger},
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "schedule.json"))
+
+	jobs, err := store.List()
+	if err != nil || len(jobs) != 0 {
+		t.Fatalf("expected empty schedule, got %+v (%+v)", jobs, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	later, err := store.Add(Job{CookerID: "a", StartAt: now.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("add failed: %+v", err)
+	}
+	sooner, err := store.Add(Job{CookerID: "b", StartAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("add failed: %+v", err)
+	}
+	if later.ID == "" || later.ID == sooner.ID {
+		t.Fatalf("expected distinct IDs, got \"%s\" and \"%s\"", later.ID, sooner.ID)
+	}
+
+	// A second store sees the same jobs, as a daemon would
+	jobs, err = NewStore(store.path).List()
+	if err != nil {
+		t.Fatalf("list failed: %+v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != sooner.ID || jobs[1].ID != later.ID || !jobs[0].StartAt.Equal(sooner.StartAt) {
+		t.Fatalf("expected jobs in due order, got %+v", jobs)
+	}
+
+	removed, err := store.Remove(sooner.ID)
+	if err != nil || removed.CookerID != "b" {
+		t.Fatalf("unexpected removal %+v (%+v)", removed, err)
+	}
+	_, err = store.Remove(sooner.ID)
+	if !errors.Is(err, ErrJobNotFound{ID: sooner.ID}) {
+		t.Fatalf("expected ErrJobNotFound, got %+v", err)
+	}
+	jobs, _ = store.List()
+	if len(jobs) != 1 || jobs[0].ID != later.ID {
+		t.Fatalf("expected one job left, got %+v", jobs)
+	}
+}
+
+func TestStore_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+
+	// Separate stores, like separate processes, only share the file lock
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store := NewStore(path)
+			for j := 0; j < 25; j++ {
+				if _, err := store.Add(Job{CookerID: "a", StartAt: time.Now()}); err != nil {
+					t.Errorf("add failed: %+v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	jobs, err := NewStore(path).List()
+	if err != nil || len(jobs) != 200 {
+		t.Errorf("expected every job to be saved, got %d (%+v)", len(jobs), err)
+	}
+}
+
+func TestEstimateDuration(t *testing.T) {
+	recipe := newTestRecipe(
+		// 20 °C to 65 °C wet takes 11.25 minutes
+		timedStage(anova.TemperatureModeWet, 65, "2h", anova.TimerTriggerWhenPreheated),
+		// Hotter than the previous stage, 65 °C to 200 °C dry takes 15 minutes
+		timedStage(anova.TemperatureModeDry, 200, "10m", anova.TimerTriggerWhenPreheated),
+		// Cooler, so no preheat
+		timedStage(anova.TemperatureModeDry, 80, "30m", anova.TimerTriggerWhenPreheated),
+		// Starts right away
+		timedStage(anova.TemperatureModeDry, 250, "5m", anova.TimerTriggerImmediately),
+	)
+	cook, err := recipe.NewCook()
+	if err != nil {
+		t.Fatalf("invalid recipe: %+v", err)
+	}
+
+	duration, err := EstimateDuration(cook)
+	if err != nil {
+		t.Fatalf("estimate failed: %+v", err)
+	}
+	expected := 11*time.Minute + 2*time.Hour + 15*time.Minute + 10*time.Minute + 30*time.Minute + 5*time.Minute
+	if duration != expected {
+		t.Errorf("expected %s, got %s", expected, duration)
+	}
+
+	readyBy := time.Date(2024, 3, 1, 18, 45, 0, 0, time.UTC)
+	startAt, err := StartTimeForReadyBy(cook, readyBy)
+	if err != nil || !startAt.Equal(readyBy.Add(-expected)) {
+		t.Errorf("unexpected start time %s (%+v)", startAt, err)
+	}
+}
+
+func TestEstimateDuration_Unknown(t *testing.T) {
+	untimed := anova.RecipeStage{Mode: anova.TemperatureModeDry, TemperatureCelsius: 70, HeatingElements: []string{"rear"}}
+	probe := untimed
+	probe.Probe = &anova.RecipeProbe{TemperatureCelsius: 57}
+
+	// Keeping warm at the end is fine
+	cook, _ := newTestRecipe(timedStage(anova.TemperatureModeDry, 200, "1h", anova.TimerTriggerWhenPreheated), untimed).NewCook()
+	if _, err := EstimateDuration(cook); err != nil {
+		t.Errorf("expected untimed final stage to be allowed: %+v", err)
+	}
+
+	for _, recipe := range []anova.Recipe{
+		newTestRecipe(untimed, timedStage(anova.TemperatureModeDry, 200, "1h", anova.TimerTriggerWhenPreheated)),
+		newTestRecipe(probe),
+		newTestRecipe(timedStage(anova.TemperatureModeDry, 200, "1h", anova.TimerTriggerManually)),
+	} {
+		cook, err := recipe.NewCook()
+		if err != nil {
+			t.Fatalf("invalid recipe: %+v", err)
+		}
+		_, err = EstimateDuration(cook)
+		if !errors.As(err, &ErrUnknownCookDuration{}) {
+			t.Errorf("expected ErrUnknownCookDuration for %+v, got %+v", recipe, err)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 1, 17, 0, 0, 0, time.Local)
+	for value, expected := range map[string]time.Time{
+		"17:30":             time.Date(2024, 3, 1, 17, 30, 0, 0, time.Local),
+		"08:15":             time.Date(2024, 3, 2, 8, 15, 0, 0, time.Local),
+		"17:00":             time.Date(2024, 3, 2, 17, 0, 0, 0, time.Local),
+		"2024-03-05 06:00":  time.Date(2024, 3, 5, 6, 0, 0, 0, time.Local),
+		"2024-03-05T06:00Z": {},
+	} {
+		parsed, err := ParseTime(value, now)
+		if expected.IsZero() {
+			if err == nil {
+				t.Errorf("expected \"%s\" to be invalid", value)
+			}
+			continue
+		}
+		if err != nil || !parsed.Equal(expected) {
+			t.Errorf("expected \"%s\" to parse as %s, got %s (%+v)", value, expected, parsed, err)
+		}
+	}
+
+	parsed, err := ParseTime("2024-03-05T06:00:00Z", now)
+	if err != nil || !parsed.Equal(time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected RFC 3339 time %s (%+v)", parsed, err)
+	}
+}
+
+func TestScheduler_StartDueJobs(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "schedule.json"))
+	scheduler := New(store)
+	now := time.Now()
+
+	busy := &dto.OvenStateV1{}
+	busy.State.Mode = dto.StateModeCook
+	scheduler.HandleEvent(anova.OvenAdded{Oven: &anova.Oven{CookerID: "busy"}, State: busy})
+
+	recipe := newTestRecipe(timedStage(anova.TemperatureModeDry, 200, "1h", anova.TimerTriggerWhenPreheated))
+	missed, _ := scheduler.Schedule(Job{CookerID: "busy", Recipe: recipe, StartAt: now.Add(-MissedJobGracePeriod - time.Minute)})
+	waitingForBusy, _ := scheduler.Schedule(Job{CookerID: "busy", Recipe: recipe, StartAt: now.Add(-time.Minute)})
+	waitingForUnknown, _ := scheduler.Schedule(Job{CookerID: "unknown", Recipe: recipe, StartAt: now})
+	notDue, _ := scheduler.Schedule(Job{CookerID: "busy", Recipe: recipe, StartAt: now.Add(time.Hour)})
+
+	if _, err := scheduler.Schedule(Job{CookerID: "busy", StartAt: now}); err == nil {
+		t.Errorf("expected job without stages to be rejected")
+	}
+
+	err := scheduler.StartDueJobs(now)
+	if err != nil {
+		t.Fatalf("check failed: %+v", err)
+	}
+
+	jobs, _ := store.List()
+	remaining := make(map[string]bool)
+	for _, job := range jobs {
+		remaining[job.ID] = true
+	}
+	if remaining[missed.ID] {
+		t.Errorf("expected missed job to be dropped")
+	}
+	if !remaining[waitingForBusy.ID] || !remaining[waitingForUnknown.ID] || !remaining[notDue.ID] || len(remaining) != 3 {
+		t.Errorf("expected jobs waiting for their oven to be kept, got %+v", jobs)
+	}
+	if !scheduler.waiting[waitingForBusy.ID] || !scheduler.waiting[waitingForUnknown.ID] || len(scheduler.waiting) != 2 {
+		t.Errorf("expected the jobs waiting for their oven to be tracked, got %+v", scheduler.waiting)
+	}
+
+	// Cancelled jobs are no longer tracked
+	_, err = store.Remove(waitingForUnknown.ID)
+	if err != nil {
+		t.Fatalf("failed to cancel job: %+v", err)
+	}
+	err = scheduler.StartDueJobs(now)
+	if err != nil {
+		t.Fatalf("check failed: %+v", err)
+	}
+	if !scheduler.waiting[waitingForBusy.ID] || len(scheduler.waiting) != 1 {
+		t.Errorf("expected only the job still waiting to be tracked, got %+v", scheduler.waiting)
+	}
+}
@@ -0,0 +1,151 @@
+// Package scheduler starts cooks at a later time, either at a given wall-clock
+// time or early enough for them to be ready by one. Jobs are kept in a JSON
+// file so that they survive restarts.
+package scheduler
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/internal/jsonfile"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is a cook waiting to be started.
+type Job struct {
+	ID       string         `json:"id"`
+	CookerID anova.CookerID `json:"cookerId"`
+	// Name of the oven when the job was scheduled, for display only
+	OvenName string       `json:"ovenName,omitempty"`
+	Recipe   anova.Recipe `json:"recipe"`
+
+	StartAt time.Time `json:"startAt"`
+	// Set if StartAt was worked back from when the cook should be ready
+	ReadyBy   *time.Time `json:"readyBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Store keeps jobs in a JSON file. The file is read again on every call, and
+// changes are made under a lock shared with other processes, so several
+// processes can share it, e.g. `apocli schedule` and `apocli daemon`.
+type Store struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// List returns every job, in the order they are due.
+func (store *Store) List() ([]Job, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.read()
+}
+
+// Add saves a job, generating an ID for it if it doesn't have one.
+func (store *Store) Add(job Job) (Job, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	unlock, err := store.lock()
+	if err != nil {
+		return Job{}, err
+	}
+	defer unlock()
+
+	jobs, err := store.read()
+	if err != nil {
+		return Job{}, err
+	}
+	if job.ID == "" {
+		job.ID = uuid.New().String()[:8]
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	for _, existing := range jobs {
+		if existing.ID == job.ID {
+			return Job{}, fmt.Errorf("a job with ID \"%s\" already exists", job.ID)
+		}
+	}
+
+	return job, store.write(append(jobs, job))
+}
+
+// Remove deletes the job with the given ID, returning ErrJobNotFound if there
+// is none.
+func (store *Store) Remove(id string) (Job, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	unlock, err := store.lock()
+	if err != nil {
+		return Job{}, err
+	}
+	defer unlock()
+
+	jobs, err := store.read()
+	if err != nil {
+		return Job{}, err
+	}
+	for i, job := range jobs {
+		if job.ID == id {
+			return job, store.write(append(jobs[:i], jobs[i+1:]...))
+		}
+	}
+	return Job{}, ErrJobNotFound{ID: id}
+}
+
+func (store *Store) lock() (unlock func(), err error) {
+	unlock, err = jsonfile.Lock(store.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock schedule \"%s\": %+v", store.path, err)
+	}
+	return unlock, nil
+}
+
+func (store *Store) read() ([]Job, error) {
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule \"%s\": %+v", store.path, err)
+	}
+
+	var jobs []Job
+	err = json.Unmarshal(data, &jobs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode schedule \"%s\": %+v", store.path, err)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].StartAt.Before(jobs[j].StartAt)
+	})
+	return jobs, nil
+}
+
+func (store *Store) write(jobs []Job) error {
+	if jobs == nil {
+		jobs = []Job{}
+	}
+	err := jsonfile.Write(store.path, jobs)
+	if err != nil {
+		return fmt.Errorf("failed to write schedule \"%s\": %+v", store.path, err)
+	}
+	return nil
+}
+
+type ErrJobNotFound struct {
+	ID string
+}
+
+func (err ErrJobNotFound) Error() string {
+	return fmt.Sprintf("no scheduled cook with ID \"%s\"", err.ID)
+}
@@ -0,0 +1,121 @@
+package telemetry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type RotationOptions struct {
+	// Rotate once the file would grow past this many bytes. Zero to disable.
+	MaxBytes int64
+	// Rotate once the file has been open for this long. Zero to disable.
+	MaxAge time.Duration
+}
+
+// RotatingFile appends to a file, moving it aside to a timestamped name, e.g.
+// "oven-20240301T120000.csv", whenever it grows too large or old.
+type RotatingFile struct {
+	path    string
+	options RotationOptions
+	// Written at the start of every new file, e.g. a CSV header
+	header []byte
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func NewRotatingFile(path string, options RotationOptions, header []byte) (*RotatingFile, error) {
+	rotatingFile := &RotatingFile{
+		path:    path,
+		options: options,
+		header:  header,
+	}
+	err := rotatingFile.open()
+	if err != nil {
+		return nil, err
+	}
+	return rotatingFile, nil
+}
+
+func (rotatingFile *RotatingFile) open() error {
+	file, err := os.OpenFile(rotatingFile.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open \"%s\": %+v", rotatingFile.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat \"%s\": %+v", rotatingFile.path, err)
+	}
+
+	rotatingFile.file = file
+	rotatingFile.size = info.Size()
+	rotatingFile.openedAt = time.Now()
+
+	// Existing files already have a header
+	if rotatingFile.size == 0 && len(rotatingFile.header) > 0 {
+		n, err := file.Write(rotatingFile.header)
+		rotatingFile.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write header to \"%s\": %+v", rotatingFile.path, err)
+		}
+	}
+	return nil
+}
+
+func (rotatingFile *RotatingFile) needsRotation(length int) bool {
+	// Never rotate a file that only has a header, or it would rotate forever
+	if rotatingFile.size <= int64(len(rotatingFile.header)) {
+		return false
+	}
+	if rotatingFile.options.MaxBytes > 0 && rotatingFile.size+int64(length) > rotatingFile.options.MaxBytes {
+		return true
+	}
+	return rotatingFile.options.MaxAge > 0 && time.Since(rotatingFile.openedAt) >= rotatingFile.options.MaxAge
+}
+
+func (rotatingFile *RotatingFile) rotate() error {
+	err := rotatingFile.file.Close()
+	if err != nil {
+		return err
+	}
+
+	extension := filepath.Ext(rotatingFile.path)
+	base := strings.TrimSuffix(rotatingFile.path, extension)
+	timestamp := rotatingFile.openedAt.Format("20060102T150405")
+	rotatedPath := fmt.Sprintf("%s-%s%s", base, timestamp, extension)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotatedPath); os.IsNotExist(err) {
+			break
+		}
+		rotatedPath = fmt.Sprintf("%s-%s-%d%s", base, timestamp, i, extension)
+	}
+
+	err = os.Rename(rotatingFile.path, rotatedPath)
+	if err != nil {
+		return fmt.Errorf("failed to rotate \"%s\": %+v", rotatingFile.path, err)
+	}
+	return rotatingFile.open()
+}
+
+// Write writes p in full to one file; it is never split across a rotation.
+func (rotatingFile *RotatingFile) Write(p []byte) (int, error) {
+	if rotatingFile.needsRotation(len(p)) {
+		err := rotatingFile.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rotatingFile.file.Write(p)
+	rotatingFile.size += int64(n)
+	return n, err
+}
+
+func (rotatingFile *RotatingFile) Close() error {
+	return rotatingFile.file.Close()
+}
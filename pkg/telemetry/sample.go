@@ -0,0 +1,195 @@
+// Package telemetry flattens oven states into samples and writes them to
+// time-series sinks, e.g. InfluxDB, CSV or NDJSON files.
+package telemetry
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Field is a single flattened value. Value is nil when the oven doesn't report
+// it, e.g. setpoints while idle, and otherwise a float64, int, bool or string.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// Sample is the flattened state of one oven at one point in time.
+type Sample struct {
+	Time     time.Time
+	CookerID anova.CookerID
+	Name     string
+	// Always the same fields in the same order; see FieldNames
+	Fields []Field
+}
+
+// Sink receives every sample. Write is called from a single goroutine.
+type Sink interface {
+	Write(sample Sample) error
+	Close() error
+}
+
+// FieldNames lists the fields of every sample, in order.
+func FieldNames() []string {
+	fields := Flatten(&dto.OvenStateV1{})
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	return names
+}
+
+// Flatten converts the relevant parts of an oven state into fields. Names end
+// in their unit, and every temperature is included in both °C and °F.
+func Flatten(state *dto.OvenStateV1) []Field {
+	nodes := state.Nodes
+	bulbs := nodes.TemperatureBulbs
+
+	var fields []Field
+	add := func(name string, value interface{}) {
+		fields = append(fields, Field{Name: name, Value: value})
+	}
+	addTemperature := func(name string, temperature *dto.Temperature) {
+		if temperature == nil {
+			add(name+"_celsius", nil)
+			add(name+"_fahrenheit", nil)
+			return
+		}
+		add(name+"_celsius", temperature.Celsius)
+		add(name+"_fahrenheit", fahrenheit(*temperature))
+	}
+	addCelsius := func(name string, celsius float64) {
+		addTemperature(name, &dto.Temperature{Celsius: celsius})
+	}
+
+	add("mode", string(state.State.Mode))
+	add("online", state.SystemInfo.Online)
+
+	var cookID, stageIndex interface{}
+	if state.Cook != nil {
+		cookID = state.Cook.CookID
+		stageIndex = state.Cook.ActiveStageIndex
+	}
+	add("cook_id", cookID)
+	add("stage_index", stageIndex)
+
+	add("temperature_bulbs_mode", string(bulbs.Mode))
+	addTemperature("dry", &bulbs.Dry.Current)
+	addTemperature("dry_setpoint", bulbs.Dry.Setpoint)
+	addTemperature("wet", &bulbs.Wet.Current)
+	addTemperature("wet_setpoint", bulbs.Wet.Setpoint)
+	addTemperature("dry_top", &bulbs.DryTop.Current)
+	addTemperature("dry_bottom", &bulbs.DryBottom.Current)
+
+	var humidity, humiditySetpoint, steamSetpoint interface{}
+	if relativeHumidity := nodes.SteamGenerators.RelativeHumidity; relativeHumidity != nil {
+		humidity = relativeHumidity.Current
+		humiditySetpoint = relativeHumidity.Setpoint
+	}
+	if steamPercentage := nodes.SteamGenerators.SteamPercentage; steamPercentage != nil {
+		steamSetpoint = steamPercentage.Setpoint
+	}
+	add("steam_generators_mode", string(nodes.SteamGenerators.Mode))
+	add("relative_humidity_percent", humidity)
+	add("relative_humidity_setpoint_percent", humiditySetpoint)
+	add("steam_percentage_setpoint_percent", steamSetpoint)
+	addCelsius("evaporator", nodes.SteamGenerators.Evaporator.Celsius)
+	add("evaporator_watts", nodes.SteamGenerators.Evaporator.Watts)
+	addCelsius("boiler", nodes.SteamGenerators.Boiler.Celsius)
+	add("boiler_watts", nodes.SteamGenerators.Boiler.Watts)
+
+	for _, element := range []struct {
+		name string
+		node dto.HeatingElement
+	}{
+		{"top", nodes.HeatingElements.Top},
+		{"bottom", nodes.HeatingElements.Bottom},
+		{"rear", nodes.HeatingElements.Rear},
+	} {
+		add("heating_element_"+element.name+"_on", element.node.On)
+		add("heating_element_"+element.name+"_watts", element.node.Watts)
+	}
+
+	add("fan_speed_percent", nodes.Fan.Speed)
+	add("door_closed", nodes.Door.Closed)
+	add("lamp_on", nodes.Lamp.On)
+	add("vent_open", nodes.Vent.Open)
+	add("water_tank_empty", nodes.WaterTank.Empty)
+	add("probe_connected", nodes.TemperatureProbe.Connected)
+
+	add("timer_mode", nodes.Timer.Mode)
+	add("timer_initial_seconds", nodes.Timer.Initial)
+	add("timer_current_seconds", nodes.Timer.Current)
+
+	return fields
+}
+
+// fahrenheit prefers the oven's own value, which some payloads omit.
+func fahrenheit(temperature dto.Temperature) float64 {
+	if temperature.Fahrenheit != 0 {
+		return temperature.Fahrenheit
+	}
+	return temperature.Celsius*9/5 + 32
+}
+
+// Handler writes a sample to every sink for each oven state received.
+type Handler struct {
+	sinks []Sink
+	names map[anova.CookerID]string
+}
+
+func NewHandler(sinks ...Sink) *Handler {
+	return &Handler{
+		sinks: sinks,
+		names: make(map[anova.CookerID]string),
+	}
+}
+
+func (handler *Handler) HandleEvent(event anova.Event) {
+	var cookerID anova.CookerID
+	var state *dto.OvenStateV1
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		handler.names[event.Oven.CookerID] = event.Oven.Name
+		cookerID, state = event.Oven.CookerID, event.State
+	case anova.OvenUpdated:
+		cookerID, state = event.Oven.CookerID, event.State
+	case anova.OvenRenamed:
+		handler.names[event.Oven.CookerID] = event.Oven.Name
+		return
+	default:
+		return
+	}
+	if state == nil {
+		return
+	}
+
+	sample := Sample{
+		Time:     state.UpdatedTimestamp,
+		CookerID: cookerID,
+		Name:     handler.names[cookerID],
+		Fields:   Flatten(state),
+	}
+	if sample.Time.IsZero() {
+		sample.Time = time.Now()
+	}
+
+	for _, sink := range handler.sinks {
+		err := sink.Write(sample)
+		if err != nil {
+			slog.Error("failed to write telemetry sample", slog.Any("err", err))
+		}
+	}
+}
+
+// Close closes every sink.
+func (handler *Handler) Close() error {
+	var errs []error
+	for _, sink := range handler.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
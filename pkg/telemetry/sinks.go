@@ -0,0 +1,228 @@
+package telemetry
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfluxMeasurement is the measurement name used in line protocol.
+const InfluxMeasurement = "apocli_oven"
+
+const influxWriteTimeout = 10 * time.Second
+
+// FormatInfluxLine encodes a sample in InfluxDB line protocol, with the cooker
+// ID and oven name as tags and a nanosecond timestamp. Missing fields are
+// omitted.
+// See https://docs.influxdata.com/influxdb/v2/reference/syntax/line-protocol/
+func FormatInfluxLine(sample Sample) []byte {
+	var line bytes.Buffer
+	line.WriteString(InfluxMeasurement)
+	line.WriteString(",cooker_id=")
+	line.WriteString(escapeInfluxTag(string(sample.CookerID)))
+	if sample.Name != "" {
+		line.WriteString(",name=")
+		line.WriteString(escapeInfluxTag(sample.Name))
+	}
+
+	separator := byte(' ')
+	for _, field := range sample.Fields {
+		var value string
+		switch fieldValue := field.Value.(type) {
+		case float64:
+			value = strconv.FormatFloat(fieldValue, 'f', -1, 64)
+		case int:
+			value = strconv.Itoa(fieldValue) + "i"
+		case bool:
+			value = strconv.FormatBool(fieldValue)
+		case string:
+			value = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(fieldValue) + `"`
+		default:
+			continue
+		}
+		line.WriteByte(separator)
+		line.WriteString(field.Name)
+		line.WriteByte('=')
+		line.WriteString(value)
+		separator = ','
+	}
+
+	line.WriteByte(' ')
+	line.WriteString(strconv.FormatInt(sample.Time.UnixNano(), 10))
+	line.WriteByte('\n')
+	return line.Bytes()
+}
+
+func escapeInfluxTag(value string) string {
+	return strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `).Replace(value)
+}
+
+// InfluxWriterSink writes line protocol to a writer, e.g. a RotatingFile.
+type InfluxWriterSink struct {
+	writer io.WriteCloser
+}
+
+func NewInfluxWriterSink(writer io.WriteCloser) *InfluxWriterSink {
+	return &InfluxWriterSink{writer: writer}
+}
+
+func (sink *InfluxWriterSink) Write(sample Sample) error {
+	_, err := sink.writer.Write(FormatInfluxLine(sample))
+	return err
+}
+
+func (sink *InfluxWriterSink) Close() error {
+	return sink.writer.Close()
+}
+
+// InfluxHTTPSink posts each sample to an InfluxDB write endpoint.
+type InfluxHTTPSink struct {
+	writeURL string
+	token    string
+	client   *http.Client
+}
+
+// NewInfluxHTTPSink posts to writeURL, which must include the database or
+// bucket, e.g. "http://localhost:8086/api/v2/write?org=home&bucket=ovens". The
+// token is sent as "Authorization: Token <token>" if set, which InfluxDB 1.x
+// also accepts as "<username>:<password>".
+func NewInfluxHTTPSink(writeURL string, token string) *InfluxHTTPSink {
+	return &InfluxHTTPSink{
+		writeURL: writeURL,
+		token:    token,
+		client:   &http.Client{Timeout: influxWriteTimeout},
+	}
+}
+
+func (sink *InfluxHTTPSink) Write(sample Sample) error {
+	request, err := http.NewRequest(http.MethodPost, sink.writeURL, bytes.NewReader(FormatInfluxLine(sample)))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if sink.token != "" {
+		request.Header.Set("Authorization", "Token "+sink.token)
+	}
+
+	response, err := sink.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to write to InfluxDB: %+v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("InfluxDB write failed with status %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (sink *InfluxHTTPSink) Close() error {
+	return nil
+}
+
+// CSVHeader is the header row written by CSVSink, for use with RotatingFile.
+func CSVHeader() []byte {
+	var header bytes.Buffer
+	w := csv.NewWriter(&header)
+	_ = w.Write(append([]string{"time", "cooker_id", "name"}, FieldNames()...))
+	w.Flush()
+	return header.Bytes()
+}
+
+// CSVSink writes one row per sample. Missing fields are left empty. The writer
+// is expected to write CSVHeader itself, as RotatingFile does.
+type CSVSink struct {
+	writer io.WriteCloser
+}
+
+func NewCSVSink(writer io.WriteCloser) *CSVSink {
+	return &CSVSink{writer: writer}
+}
+
+func (sink *CSVSink) Write(sample Sample) error {
+	row := []string{sample.Time.UTC().Format(time.RFC3339Nano), string(sample.CookerID), sample.Name}
+	for _, field := range sample.Fields {
+		value := ""
+		if field.Value != nil {
+			value = fmt.Sprint(field.Value)
+		}
+		row = append(row, value)
+	}
+
+	// Buffer the row, so it's written to the file in one piece
+	var buffer bytes.Buffer
+	w := csv.NewWriter(&buffer)
+	err := w.Write(row)
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	_, err = sink.writer.Write(buffer.Bytes())
+	return err
+}
+
+func (sink *CSVSink) Close() error {
+	return sink.writer.Close()
+}
+
+// NDJSONSink writes one JSON object per line for each sample, with the same
+// keys as the CSV columns. Missing fields are null.
+type NDJSONSink struct {
+	writer io.WriteCloser
+}
+
+func NewNDJSONSink(writer io.WriteCloser) *NDJSONSink {
+	return &NDJSONSink{writer: writer}
+}
+
+func (sink *NDJSONSink) Write(sample Sample) error {
+	// Built by hand to keep the keys in field order
+	var line bytes.Buffer
+	writeKeyValue := func(key string, value interface{}) error {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if line.Len() > 0 {
+			line.WriteByte(',')
+		} else {
+			line.WriteByte('{')
+		}
+		line.WriteString(strconv.Quote(key))
+		line.WriteByte(':')
+		line.Write(encoded)
+		return nil
+	}
+
+	err := writeKeyValue("time", sample.Time.UTC().Format(time.RFC3339Nano))
+	if err == nil {
+		err = writeKeyValue("cooker_id", sample.CookerID)
+	}
+	if err == nil {
+		err = writeKeyValue("name", sample.Name)
+	}
+	for _, field := range sample.Fields {
+		if err != nil {
+			return err
+		}
+		err = writeKeyValue(field.Name, field.Value)
+	}
+	if err != nil {
+		return err
+	}
+	line.WriteString("}\n")
+
+	_, err = sink.writer.Write(line.Bytes())
+	return err
+}
+
+func (sink *NDJSONSink) Close() error {
+	return sink.writer.Close()
+}
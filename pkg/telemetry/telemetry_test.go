@@ -0,0 +1,174 @@
+package telemetry
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSample() Sample {
+	state := &dto.OvenStateV1{}
+	state.State.Mode = dto.StateModeCook
+	state.Nodes.TemperatureBulbs.Dry.Current = dto.NewTemperatureFromCelsius(200)
+	// Some payloads omit Fahrenheit
+	state.Nodes.TemperatureBulbs.Wet.Current = dto.Temperature{Celsius: 50}
+	state.Nodes.Timer.Mode = string(dto.TimerModeRunning)
+	state.Nodes.Timer.Initial = 600
+
+	return Sample{
+		Time:     time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		CookerID: "0123456789abcdef",
+		Name:     "Oven, Kitchen",
+		Fields:   Flatten(state),
+	}
+}
+
+func fieldValue(sample Sample, name string) interface{} {
+	for _, field := range sample.Fields {
+		if field.Name == name {
+			return field.Value
+		}
+	}
+	return "missing"
+}
+
+func TestFlatten(t *testing.T) {
+	sample := newTestSample()
+	for _, testCase := range []struct {
+		name     string
+		expected interface{}
+	}{
+		{"dry_celsius", 200.0},
+		{"dry_fahrenheit", 392.0},
+		{"wet_fahrenheit", 122.0},
+		{"dry_setpoint_celsius", nil},
+		{"timer_initial_seconds", 600},
+		{"cook_id", nil},
+	} {
+		if value := fieldValue(sample, testCase.name); value != testCase.expected {
+			t.Errorf("expected %s to be %v, got %v", testCase.name, testCase.expected, value)
+		}
+	}
+
+	if len(sample.Fields) != len(FieldNames()) {
+		t.Errorf("expected %d fields, got %d", len(FieldNames()), len(sample.Fields))
+	}
+}
+
+func TestFormatInfluxLine(t *testing.T) {
+	line := string(FormatInfluxLine(newTestSample()))
+
+	prefix := `apocli_oven,cooker_id=0123456789abcdef,name=Oven\,\ Kitchen mode="cook",online=false,`
+	if !strings.HasPrefix(line, prefix) {
+		t.Errorf("unexpected line prefix: %s", line)
+	}
+	if !strings.HasSuffix(line, " 1709294400000000000\n") {
+		t.Errorf("unexpected line timestamp: %s", line)
+	}
+	for _, expected := range []string{",dry_celsius=200,", ",timer_initial_seconds=600i,", ",door_closed=false,"} {
+		if !strings.Contains(line, expected) {
+			t.Errorf("expected line to contain %s: %s", expected, line)
+		}
+	}
+	if strings.Contains(line, "dry_setpoint") {
+		t.Errorf("expected missing setpoint to be omitted: %s", line)
+	}
+}
+
+func TestInfluxHTTPSink(t *testing.T) {
+	var body string
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		raw, _ := io.ReadAll(request.Body)
+		body = string(raw)
+		authorization = request.Header.Get("Authorization")
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewInfluxHTTPSink(server.URL+"/api/v2/write?bucket=ovens", "secret")
+	err := sink.Write(newTestSample())
+	if err != nil {
+		t.Fatalf("write failed: %+v", err)
+	}
+	if !strings.HasPrefix(body, InfluxMeasurement+",") || authorization != "Token secret" {
+		t.Errorf("unexpected request with authorization \"%s\": %s", authorization, body)
+	}
+}
+
+func TestFileSinks(t *testing.T) {
+	dir := t.TempDir()
+
+	csvFile, err := NewRotatingFile(filepath.Join(dir, "oven.csv"), RotationOptions{}, CSVHeader())
+	if err != nil {
+		t.Fatalf("failed to open CSV file: %+v", err)
+	}
+	ndjsonFile, err := NewRotatingFile(filepath.Join(dir, "oven.ndjson"), RotationOptions{}, nil)
+	if err != nil {
+		t.Fatalf("failed to open NDJSON file: %+v", err)
+	}
+
+	handler := NewHandler(NewCSVSink(csvFile), NewNDJSONSink(ndjsonFile))
+	state := &dto.OvenStateV1{UpdatedTimestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	handler.HandleEvent(anova.OvenAdded{Oven: &anova.Oven{CookerID: "0123456789abcdef", Name: "Kitchen"}, State: state})
+	err = handler.Close()
+	if err != nil {
+		t.Fatalf("failed to close sinks: %+v", err)
+	}
+
+	csvContents, _ := os.ReadFile(filepath.Join(dir, "oven.csv"))
+	rows, err := csv.NewReader(strings.NewReader(string(csvContents))).ReadAll()
+	if err != nil || len(rows) != 2 || rows[0][0] != "time" || rows[1][2] != "Kitchen" {
+		t.Errorf("unexpected CSV %v (err: %+v)", rows, err)
+	}
+
+	ndjsonContents, _ := os.ReadFile(filepath.Join(dir, "oven.ndjson"))
+	var object map[string]interface{}
+	err = json.Unmarshal(ndjsonContents, &object)
+	if err != nil || object["cooker_id"] != "0123456789abcdef" || object["dry_setpoint_celsius"] != nil {
+		t.Errorf("unexpected NDJSON %s (err: %+v)", ndjsonContents, err)
+	}
+	if _, exists := object["dry_fahrenheit"]; !exists {
+		t.Errorf("expected NDJSON to include every field: %s", ndjsonContents)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "oven.csv")
+	file, err := NewRotatingFile(path, RotationOptions{MaxBytes: 20}, []byte("header\n"))
+	if err != nil {
+		t.Fatalf("failed to open file: %+v", err)
+	}
+
+	for _, line := range []string{"first line\n", "second line\n", "third line\n"} {
+		_, err = file.Write([]byte(line))
+		if err != nil {
+			t.Fatalf("write failed: %+v", err)
+		}
+	}
+	_ = file.Close()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "oven-*.csv"))
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 rotated files, got %v", matches)
+	}
+	for _, match := range matches {
+		contents, _ := os.ReadFile(match)
+		if !strings.HasPrefix(string(contents), "header\n") {
+			t.Errorf("expected rotated file %s to start with the header: %s", match, contents)
+		}
+	}
+	if contents, _ := os.ReadFile(path); string(contents) != "header\nthird line\n" {
+		t.Errorf("unexpected current file: %s", contents)
+	}
+}
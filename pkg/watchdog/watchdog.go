@@ -0,0 +1,324 @@
+// Package watchdog stops cooks that look unsafe, e.g. because the oven is
+// overheating or a cook has run for far too long, as a guard rail for ovens
+// left running unattended.
+package watchdog
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rules configures when the watchdog stops a cook. Zero values disable a rule.
+type Rules struct {
+	// Stop cooks that have run for longer than this
+	MaxCookDuration time.Duration
+	// Stop cooks whose dry bulb is more than MaxOvershootCelsius above its
+	// setpoint for longer than OvershootFor, without cooling down towards it.
+	// The clock restarts whenever the setpoint or stage changes.
+	MaxOvershootCelsius float64
+	OvershootFor        time.Duration
+	// Stop cooks when the oven reports a failed or overheated component
+	StopOnFailure bool
+	// Stop cooks whose door has been open for longer than MaxDoorOpen while the
+	// dry bulb is at or above DoorOpenMinCelsius
+	MaxDoorOpen        time.Duration
+	DoorOpenMinCelsius float64
+	// Stop cooks that were running when the oven went offline, as soon as it
+	// comes back online. An offline oven can't be stopped.
+	StopWhenOffline bool
+}
+
+// An overshooting dry bulb that has fallen by at least this much since the
+// overshoot was first seen is cooling down towards its setpoint, rather than
+// overheating.
+const minCoolingCelsius = 2
+
+// DefaultRules are a reasonable starting point: every rule except the maximum
+// cook duration, which depends on the recipe, is enabled.
+func DefaultRules() Rules {
+	return Rules{
+		MaxOvershootCelsius: 25,
+		OvershootFor:        2 * time.Minute,
+		StopOnFailure:       true,
+		MaxDoorOpen:         10 * time.Minute,
+		DoorOpenMinCelsius:  100,
+		StopWhenOffline:     true,
+	}
+}
+
+func (rules Rules) Validate() error {
+	if rules.MaxCookDuration < 0 || rules.OvershootFor < 0 || rules.MaxDoorOpen < 0 {
+		return errors.New("watchdog durations cannot be negative")
+	}
+	if rules.MaxOvershootCelsius < 0 {
+		return errors.New("watchdog overshoot cannot be negative")
+	}
+	return nil
+}
+
+// Intervention describes a cook the watchdog stopped, or tried to stop.
+type Intervention struct {
+	CookerID anova.CookerID
+	OvenName string
+	CookID   string
+	Time     time.Time
+	// Why the cook was stopped, e.g. "the oven reported a failed fan"
+	Reason string
+	// Set if the cook could not be stopped. The watchdog tries again on the
+	// next state update.
+	Err error
+}
+
+func (intervention Intervention) String() string {
+	if intervention.Err != nil {
+		return fmt.Sprintf("failed to stop the cook, as %s: %s", intervention.Reason, intervention.Err)
+	}
+	return fmt.Sprintf("stopped the cook, as %s", intervention.Reason)
+}
+
+// Watchdog checks every state update of every oven against its rules, and stops
+// the cook when one is broken. Interventions are logged and passed to the
+// observers.
+type Watchdog struct {
+	rules     Rules
+	observers []func(Intervention)
+
+	// Guards the oven entries, which are also updated once a stop completes
+	mutex sync.Mutex
+	ovens map[anova.CookerID]*ovenEntry
+
+	now      func() time.Time
+	stopCook func(oven *anova.Oven) error
+	// Stops in progress
+	inFlight sync.WaitGroup
+}
+
+type ovenEntry struct {
+	name   string
+	cookID string
+
+	overshootSince *time.Time
+	// Dry bulb temperature when overshootSince was set
+	overshootCelsius float64
+	// Setpoint and stage the overshoot is measured against
+	setpointCelsius float64
+	stageIndex      int
+	doorOpenSince   *time.Time
+	// The oven went offline during the cook
+	wentOffline bool
+
+	// A stop is in progress or has succeeded
+	stopping bool
+	// The first intervention for the cook has been reported; retries are only
+	// logged
+	reported bool
+}
+
+func New(rules Rules, observers ...func(Intervention)) *Watchdog {
+	return &Watchdog{
+		rules:     rules,
+		observers: observers,
+
+		ovens: make(map[anova.CookerID]*ovenEntry),
+
+		now: time.Now,
+		stopCook: func(oven *anova.Oven) error {
+			return oven.StopCook()
+		},
+	}
+}
+
+func (watchdog *Watchdog) HandleEvent(event anova.Event) {
+	switch event := event.(type) {
+	case anova.OvenAdded:
+		watchdog.check(event.Oven, event.State)
+	case anova.OvenUpdated:
+		watchdog.check(event.Oven, event.State)
+	}
+}
+
+func (watchdog *Watchdog) check(oven *anova.Oven, state *dto.OvenStateV1) {
+	if state == nil {
+		return
+	}
+
+	watchdog.mutex.Lock()
+	defer watchdog.mutex.Unlock()
+
+	entry, exists := watchdog.ovens[oven.CookerID]
+	if !exists {
+		entry = &ovenEntry{}
+		watchdog.ovens[oven.CookerID] = entry
+	}
+	entry.name = oven.Name
+
+	if state.Cook == nil || state.State.Mode != dto.StateModeCook {
+		*entry = ovenEntry{name: oven.Name}
+		return
+	}
+	if entry.cookID != state.Cook.CookID {
+		*entry = ovenEntry{name: oven.Name, cookID: state.Cook.CookID}
+	}
+
+	reason := watchdog.brokenRule(entry, state)
+	if reason == "" || entry.stopping {
+		return
+	}
+	entry.stopping = true
+
+	intervention := Intervention{
+		CookerID: oven.CookerID,
+		OvenName: oven.Name,
+		CookID:   entry.cookID,
+		Time:     watchdog.now(),
+		Reason:   reason,
+	}
+	slog.Warn("watchdog is stopping cook",
+		slog.String("cookerID", string(oven.CookerID)),
+		slog.String("cookID", entry.cookID),
+		slog.String("reason", reason))
+
+	// Commands block until the oven responds, so don't hold up other handlers
+	watchdog.inFlight.Add(1)
+	go watchdog.stop(oven, entry, intervention)
+}
+
+// Wait blocks until every stop in progress has completed and been reported.
+func (watchdog *Watchdog) Wait() {
+	watchdog.inFlight.Wait()
+}
+
+func (watchdog *Watchdog) stop(oven *anova.Oven, entry *ovenEntry, intervention Intervention) {
+	defer watchdog.inFlight.Done()
+	intervention.Err = watchdog.stopCook(oven)
+
+	watchdog.mutex.Lock()
+	if intervention.Err != nil && entry.cookID == intervention.CookID {
+		// Try again on the next state update
+		entry.stopping = false
+	}
+	report := !entry.reported || intervention.Err == nil
+	entry.reported = true
+	watchdog.mutex.Unlock()
+
+	if intervention.Err != nil {
+		slog.Error("watchdog failed to stop cook",
+			slog.String("cookerID", string(oven.CookerID)),
+			slog.String("cookID", intervention.CookID),
+			slog.Any("err", intervention.Err))
+	} else {
+		slog.Warn("watchdog stopped cook",
+			slog.String("cookerID", string(oven.CookerID)),
+			slog.String("cookID", intervention.CookID))
+	}
+
+	if report {
+		for _, observer := range watchdog.observers {
+			observer(intervention)
+		}
+	}
+}
+
+// brokenRule returns why the cook should be stopped, if it should.
+func (watchdog *Watchdog) brokenRule(entry *ovenEntry, state *dto.OvenStateV1) string {
+	rules := watchdog.rules
+	now := watchdog.now()
+	nodes := state.Nodes
+
+	if !state.SystemInfo.Online {
+		if rules.StopWhenOffline {
+			entry.wentOffline = true
+		}
+		return ""
+	}
+	if entry.wentOffline {
+		return "the oven went offline during the cook"
+	}
+
+	if rules.MaxCookDuration > 0 {
+		elapsed := time.Duration(state.Cook.SecondsElapsed) * time.Second
+		if elapsed > rules.MaxCookDuration {
+			return fmt.Sprintf("the cook has run for %s, longer than the maximum of %s", elapsed, rules.MaxCookDuration)
+		}
+	}
+
+	if rules.StopOnFailure {
+		if failures := failedComponents(state); len(failures) > 0 {
+			return "the oven reported " + strings.Join(failures, ", ")
+		}
+	}
+
+	dry := nodes.TemperatureBulbs.Dry
+	setpointCelsius := 0.0
+	if dry.Setpoint != nil {
+		setpointCelsius = dry.Setpoint.Celsius
+	}
+	if setpointCelsius != entry.setpointCelsius || state.Cook.ActiveStageIndex != entry.stageIndex {
+		// The oven takes a while to cool down to a lowered setpoint, e.g. after
+		// a sear or when keeping warm, so start measuring again
+		entry.overshootSince = nil
+		entry.setpointCelsius = setpointCelsius
+		entry.stageIndex = state.Cook.ActiveStageIndex
+	}
+	if rules.MaxOvershootCelsius > 0 && dry.Setpoint != nil && dry.Current.Celsius-dry.Setpoint.Celsius > rules.MaxOvershootCelsius {
+		switch {
+		case entry.overshootSince == nil || entry.overshootCelsius-dry.Current.Celsius >= minCoolingCelsius:
+			// Only an oven that isn't cooling down towards its setpoint counts
+			entry.overshootSince = &now
+			entry.overshootCelsius = dry.Current.Celsius
+		case now.Sub(*entry.overshootSince) >= rules.OvershootFor:
+			return fmt.Sprintf("the dry bulb has been more than %.1f °C above its setpoint of %.1f °C for %s",
+				rules.MaxOvershootCelsius, dry.Setpoint.Celsius, now.Sub(*entry.overshootSince).Round(time.Second))
+		}
+	} else {
+		entry.overshootSince = nil
+	}
+
+	if rules.MaxDoorOpen > 0 && !nodes.Door.Closed && dry.Current.Celsius >= rules.DoorOpenMinCelsius {
+		if entry.doorOpenSince == nil {
+			entry.doorOpenSince = &now
+		}
+		if now.Sub(*entry.doorOpenSince) >= rules.MaxDoorOpen {
+			return fmt.Sprintf("the door has been open for %s at %.1f °C",
+				now.Sub(*entry.doorOpenSince).Round(time.Second), dry.Current.Celsius)
+		}
+	} else {
+		entry.doorOpenSince = nil
+	}
+
+	return ""
+}
+
+// failedComponents lists every component the oven reports as failed or
+// overheated.
+func failedComponents(state *dto.OvenStateV1) []string {
+	nodes := state.Nodes
+	var failures []string
+	for _, flag := range []struct {
+		set         bool
+		description string
+	}{
+		{nodes.HeatingElements.Top.Failed, "a failed top heating element"},
+		{nodes.HeatingElements.Bottom.Failed, "a failed bottom heating element"},
+		{nodes.HeatingElements.Rear.Failed, "a failed rear heating element"},
+		{nodes.Fan.Failed, "a failed fan"},
+		{nodes.SteamGenerators.Evaporator.Failed, "a failed evaporator"},
+		{nodes.SteamGenerators.Evaporator.Overheated, "an overheated evaporator"},
+		{nodes.SteamGenerators.Boiler.Failed, "a failed boiler"},
+		{nodes.SteamGenerators.Boiler.Overheated, "an overheated boiler"},
+		{nodes.TemperatureBulbs.DryTop.Overheated, "an overheated top dry bulb"},
+		{nodes.TemperatureBulbs.DryBottom.Overheated, "an overheated bottom dry bulb"},
+		{state.SystemInfo.TriacsFailed, "failed triacs"},
+	} {
+		if flag.set {
+			failures = append(failures, flag.description)
+		}
+	}
+	return failures
+}
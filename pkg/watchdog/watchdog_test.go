@@ -0,0 +1,210 @@
+package watchdog
+
+import (
+	"apocli/pkg/anova"
+	"apocli/pkg/anova/dto"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newCookingState(dryCelsius float64, setpointCelsius float64) *dto.OvenStateV1 {
+	state := &dto.OvenStateV1{}
+	state.SystemInfo.Online = true
+	state.State.Mode = dto.StateModeCook
+	state.Cook = &dto.CookV1{CookID: "cook", SecondsElapsed: 600}
+	state.Nodes.Door.Closed = true
+	state.Nodes.TemperatureBulbs.Dry.Current = dto.NewTemperatureFromCelsius(dryCelsius)
+	setpoint := dto.NewTemperatureFromCelsius(setpointCelsius)
+	state.Nodes.TemperatureBulbs.Dry.Setpoint = &setpoint
+	return state
+}
+
+type watchdogTest struct {
+	watchdog      *Watchdog
+	oven          *anova.Oven
+	now           time.Time
+	stops         int
+	stopErr       error
+	interventions chan Intervention
+}
+
+func newWatchdogTest(rules Rules) *watchdogTest {
+	test := &watchdogTest{
+		oven:          &anova.Oven{CookerID: "oven", Name: "Kitchen"},
+		now:           time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC),
+		interventions: make(chan Intervention, 10),
+	}
+	test.watchdog = New(rules, func(intervention Intervention) {
+		test.interventions <- intervention
+	})
+	test.watchdog.now = func() time.Time { return test.now }
+	test.watchdog.stopCook = func(oven *anova.Oven) error {
+		test.stops++
+		return test.stopErr
+	}
+	return test
+}
+
+// update passes a state update to the watchdog after the given delay, and
+// returns the resulting intervention, if any.
+func (test *watchdogTest) update(t *testing.T, after time.Duration, state *dto.OvenStateV1) *Intervention {
+	t.Helper()
+	test.now = test.now.Add(after)
+	test.watchdog.HandleEvent(anova.OvenUpdated{Oven: test.oven, State: state})
+	test.watchdog.Wait()
+	select {
+	case intervention := <-test.interventions:
+		return &intervention
+	default:
+		return nil
+	}
+}
+
+func TestWatchdog_Overshoot(t *testing.T) {
+	test := newWatchdogTest(Rules{MaxOvershootCelsius: 20, OvershootFor: time.Minute})
+
+	if test.update(t, 0, newCookingState(215, 200)) != nil {
+		t.Fatalf("expected small overshoot to be fine")
+	}
+	if test.update(t, time.Second, newCookingState(230, 200)) != nil {
+		t.Fatalf("expected overshoot to be tolerated briefly")
+	}
+	// Dropping back resets the clock
+	test.update(t, 50*time.Second, newCookingState(200, 200))
+	if test.update(t, 30*time.Second, newCookingState(230, 200)) != nil {
+		t.Fatalf("expected overshoot to be tolerated briefly")
+	}
+	intervention := test.update(t, time.Minute, newCookingState(230, 200))
+	if intervention == nil || intervention.CookID != "cook" || intervention.Err != nil ||
+		!strings.Contains(intervention.Reason, "above its setpoint") {
+		t.Fatalf("expected cook to be stopped, got %+v", intervention)
+	}
+
+	// Stopped only once while waiting for the oven to report the cook ended
+	if test.update(t, time.Second, newCookingState(230, 200)) != nil || test.stops != 1 {
+		t.Errorf("expected a single stop, got %d", test.stops)
+	}
+}
+
+func TestWatchdog_Overshoot_Cooling(t *testing.T) {
+	test := newWatchdogTest(DefaultRules())
+
+	// A sear followed by a cooler stage: the oven cools down for a while
+	sear := newCookingState(230, 230)
+	test.update(t, 0, sear)
+	for dry := 228.0; dry > 125; dry -= 3 {
+		state := newCookingState(dry, 100)
+		state.Cook.ActiveStageIndex = 1
+		if intervention := test.update(t, 30*time.Second, state); intervention != nil {
+			t.Fatalf("expected cooling oven to be left alone at %g °C, got %+v", dry, intervention)
+		}
+	}
+
+	// Lowering the setpoint within the stage restarts the clock too
+	steady := newCookingState(160, 160)
+	steady.Cook.ActiveStageIndex = 1
+	test.update(t, 30*time.Second, steady)
+	for _, after := range []time.Duration{time.Minute, time.Minute} {
+		state := newCookingState(160, 130)
+		state.Cook.ActiveStageIndex = 1
+		if intervention := test.update(t, after, state); intervention != nil {
+			t.Fatalf("expected lowered setpoint to be tolerated, got %+v", intervention)
+		}
+	}
+
+	// An oven that stops cooling is overheating
+	stuck := newCookingState(159, 130)
+	stuck.Cook.ActiveStageIndex = 1
+	if intervention := test.update(t, time.Minute, stuck); intervention == nil {
+		t.Fatalf("expected cook to be stopped")
+	}
+}
+
+func TestWatchdog_Failures(t *testing.T) {
+	test := newWatchdogTest(Rules{StopOnFailure: true})
+
+	state := newCookingState(200, 200)
+	state.Nodes.Fan.Failed = true
+	state.SystemInfo.TriacsFailed = true
+	intervention := test.update(t, 0, state)
+	if intervention == nil || intervention.Reason != "the oven reported a failed fan, failed triacs" {
+		t.Fatalf("unexpected intervention %+v", intervention)
+	}
+
+	// Failures outside of cooks are left alone
+	idle := &dto.OvenStateV1{}
+	idle.State.Mode = dto.StateModeIdle
+	idle.Nodes.Fan.Failed = true
+	if test.update(t, 0, idle) != nil {
+		t.Errorf("expected idle oven to be left alone")
+	}
+}
+
+func TestWatchdog_MaxCookDuration(t *testing.T) {
+	test := newWatchdogTest(Rules{MaxCookDuration: time.Hour})
+
+	state := newCookingState(200, 200)
+	if test.update(t, 0, state) != nil {
+		t.Fatalf("expected short cook to be fine")
+	}
+	state = newCookingState(200, 200)
+	state.Cook.SecondsElapsed = 3601
+	if intervention := test.update(t, 0, state); intervention == nil {
+		t.Fatalf("expected long cook to be stopped")
+	}
+}
+
+func TestWatchdog_DoorOpen(t *testing.T) {
+	test := newWatchdogTest(Rules{MaxDoorOpen: 5 * time.Minute, DoorOpenMinCelsius: 100})
+
+	coolOpen := newCookingState(80, 80)
+	coolOpen.Nodes.Door.Closed = false
+	hotOpen := newCookingState(200, 200)
+	hotOpen.Nodes.Door.Closed = false
+
+	for _, state := range []*dto.OvenStateV1{coolOpen, coolOpen, hotOpen, hotOpen} {
+		if test.update(t, 3*time.Minute, state) != nil {
+			t.Fatalf("expected door to be tolerated")
+		}
+	}
+	if intervention := test.update(t, 3*time.Minute, hotOpen); intervention == nil || !strings.Contains(intervention.Reason, "door") {
+		t.Fatalf("expected cook to be stopped, got %+v", intervention)
+	}
+}
+
+func TestWatchdog_Offline(t *testing.T) {
+	test := newWatchdogTest(Rules{StopWhenOffline: true})
+	test.stopErr = errors.New("timed out")
+
+	offline := newCookingState(200, 200)
+	offline.SystemInfo.Online = false
+	online := newCookingState(200, 200)
+
+	test.update(t, 0, online)
+	if test.update(t, 0, offline) != nil || test.stops != 0 {
+		t.Fatalf("expected no stop while offline")
+	}
+	intervention := test.update(t, 0, online)
+	if intervention == nil || intervention.Err == nil {
+		t.Fatalf("expected failed stop to be reported, got %+v", intervention)
+	}
+
+	// Retried on the next update, but only reported once it succeeds
+	if test.update(t, 0, online) != nil || test.stops != 2 {
+		t.Fatalf("expected retry without report, got %d stops", test.stops)
+	}
+	test.stopErr = nil
+	intervention = test.update(t, 0, online)
+	if intervention == nil || intervention.Err != nil || test.stops != 3 {
+		t.Fatalf("expected successful stop to be reported, got %+v", intervention)
+	}
+
+	// A new cook starts from a clean slate
+	next := newCookingState(200, 200)
+	next.Cook.CookID = "next"
+	if test.update(t, 0, next) != nil {
+		t.Errorf("expected new cook to be left alone")
+	}
+}